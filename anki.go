@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ankiHeader starts the file imported by Anki. It tells Anki the separator
+// of the fields, that they are plain text and that the third one holds
+// the tags.
+const ankiHeader = "#separator:tab\n#html:false\n#tags column:3\n"
+
+// WriteAnki writes the topic as a text file Anki imports, one note per
+// line: the question, the answer and the tags separated by tabs. The tags
+// of a question are its own and the name of its subsection, the spaces
+// being replaced by _ since Anki splits the tags on them.
+func (topic Topic) WriteAnki(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(ankiHeader)
+	for _, id := range topic.GetSubsectionsName() {
+		qa := topic.list[id]
+		for i := range qa.questions {
+			var tags []string
+			if len(id) != 0 {
+				tags = append(tags, ankiTag(id))
+			}
+			for _, tag := range qa.tags[i] {
+				tags = append(tags, ankiTag(tag))
+			}
+			bw.WriteString(ankiField(qa.questions[i]) + "\t" + ankiField(qa.answers[i]) + "\t" + strings.Join(tags, " ") + "\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// ankiField returns the text of a field on a single line without tab.
+func ankiField(text string) string {
+	return strings.NewReplacer("\t", " ", "\r", " ", "\n", " ").Replace(text)
+}
+
+// ankiTag returns the text of a tag without space.
+func ankiTag(text string) string {
+	return strings.Join(strings.Fields(text), "_")
+}
+
+// ankiSeparators are the names of the separators of the fields in the
+// headers of the Anki exports.
+var ankiSeparators = map[string]rune{
+	"tab": '\t', "comma": ',', "semicolon": ';', "space": ' ', "pipe": '|', "colon": ':',
+}
+
+// ankiMarkup matches the HTML tags of the fields of an Anki export.
+var ankiMarkup = regexp.MustCompile(`<[^>]*>`)
+
+// ParseTopicAnki reads the notes exported by Anki as plain text. The
+// headers starting the file tell the separator of the fields, if they hold
+// HTML and the columns of the deck, the note type, the guid and the tags:
+//
+//	#separator:tab
+//	#html:true
+//	#deck column:1
+//	#tags column:4
+//	French	manger	to eat	verb
+//
+// The first two other columns are the question and the answer. The notes of
+// a deck are in the subsection named after it. Without deck column, they
+// are all in a subsection with an empty id. The notes without question are
+// left out.
+func ParseTopicAnki(r io.Reader) (Topic, error) {
+	topic := NewTopic()
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return topic, err
+	}
+	body := string(content)
+	sep, isHTML := '\t', false
+	skipped := map[int]bool{} // the columns that are neither the question nor the answer
+	deckColumn, tagsColumn := 0, 0
+	headers := 0
+	for strings.HasPrefix(body, "#") {
+		line := body
+		if end := strings.Index(body, "\n"); end >= 0 {
+			line, body = body[:end], body[end+1:]
+		} else {
+			body = ""
+		}
+		headers++
+		key, value, _ := strings.Cut(strings.TrimSpace(strings.TrimPrefix(line, "#")), ":")
+		switch key {
+		case "separator":
+			if named, found := ankiSeparators[strings.ToLower(value)]; found {
+				sep = named
+			} else if runes := []rune(value); len(runes) == 1 {
+				sep = runes[0]
+			} else {
+				return topic, &ParseError{Line: headers, msg: fmt.Sprintf("The separator of the Anki export (%s) is unknown (line %d).", value, headers)}
+			}
+		case "html":
+			isHTML = value == "true"
+		case "deck column", "tags column", "notetype column", "guid column":
+			column, err := strconv.Atoi(value)
+			if err != nil || column <= 0 {
+				return topic, &ParseError{Line: headers, msg: fmt.Sprintf("The %s of the Anki export (%s) is not a column number (line %d).", key, value, headers)}
+			}
+			skipped[column] = true
+			switch key {
+			case "deck column":
+				deckColumn = column
+			case "tags column":
+				tagsColumn = column
+			}
+		}
+	}
+
+	records := csv.NewReader(strings.NewReader(body))
+	records.Comma = sep
+	records.FieldsPerRecord = -1
+	records.LazyQuotes = true
+	for {
+		fields, err := records.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return topic, fmt.Errorf("The Anki export is malformed: %v", err)
+		}
+		var id string
+		var tags, texts []string
+		for n, field := range fields {
+			column := n + 1
+			switch {
+			case column == deckColumn:
+				id = field
+			case column == tagsColumn:
+				tags = strings.Fields(field)
+			case !skipped[column]:
+				if isHTML {
+					field = ankiText(field)
+				}
+				texts = append(texts, field)
+			}
+		}
+		if len(texts) == 0 || len(texts[0]) == 0 {
+			continue
+		}
+		texts = append(texts, "")
+		qa := topic.GetSubsection(id)
+		qa.AddTaggedEntry(texts[0], texts[1], tags)
+		topic.SetSubsection(id, qa)
+	}
+	return topic, nil
+}
+
+// ankiText returns the text of a field holding HTML, on a single line.
+func ankiText(field string) string {
+	return strings.Join(strings.Fields(html.UnescapeString(ankiMarkup.ReplaceAllString(field, " "))), " ")
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestWriteAnki checks the notes written for Anki and their tags.
+func TestWriteAnki(t *testing.T) {
+	tpp := getTpp()
+	tpp.TopicAnnounce = "### "
+	tpp.TagPrefix = "#"
+	topic := ParseTopic(strings.NewReader("### Lesson 1\nmanger;to eat #verb\n### Lesson 2\nle\tpain;bread\n"), tpp)
+
+	var out strings.Builder
+	if err := topic.WriteAnki(&out); err != nil {
+		t.Fatal(err)
+	}
+	expected := ankiHeader + "manger\tto eat\tLesson_1 verb\nle pain\tbread\tLesson_2\n"
+	if out.String() != expected {
+		t.Errorf("The notes should be\n%s\nbut we got\n%s\n", expected, out.String())
+	}
+}
+
+// TestParseTopicAnki checks that the notes exported by Anki are read in
+// the subsection of their deck, with their tags.
+func TestParseTopicAnki(t *testing.T) {
+	export := "#separator:tab\n#html:true\n#guid column:1\n#deck column:2\n#tags column:5\n" +
+		"abc\tFrench\tmanger\tto <b>eat</b>&nbsp;\tverb food\n" +
+		"def\tFrench::Nouns\t\"le pain\"\tbread\t\n" +
+		"ghi\tFrench\t\tno question\t\n"
+	topic, err := ParseTopicAnki(strings.NewReader(export))
+	if err != nil {
+		t.Fatalf("A valid Anki export should not trigger an error: %v", err)
+	}
+	if names := topic.GetSubsectionsName(); !reflect.DeepEqual(names, []string{"French", "French::Nouns"}) {
+		t.Errorf("The decks should be the subsections but we got %v\n", names)
+	}
+	french := topic.GetSubsection("French")
+	if !reflect.DeepEqual(french.questions, []string{"manger"}) || !reflect.DeepEqual(french.answers, []string{"to eat"}) || !reflect.DeepEqual(french.tags[0], []string{"verb", "food"}) {
+		t.Errorf("The note of French is wrong: %v %v %v\n", french.questions, french.answers, french.tags)
+	}
+	if nouns := topic.GetSubsection("French::Nouns"); !reflect.DeepEqual(nouns.questions, []string{"le pain"}) {
+		t.Errorf("The quoted field should be read but we got %v\n", nouns.questions)
+	}
+
+	if _, err := ParseTopicAnki(strings.NewReader("#separator:dash\nmanger\tto eat\n")); err == nil {
+		t.Errorf("An unknown separator should be reported\n")
+	}
+}
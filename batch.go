@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DeckResult is the result of the session run on one deck of a batch.
+type DeckResult struct {
+	Path   string
+	Result SessionResult
+}
+
+// BatchResult is the result of a batch of sessions.
+type BatchResult struct {
+	Decks []DeckResult
+	Total SessionResult
+}
+
+// manifestEntry is a line of the batch manifest: the path to the deck and
+// optionally the subsections to be questioned on.
+type manifestEntry struct {
+	path        string
+	subsections []string
+}
+
+// parseManifest reads the list of decks of a batch. Each line contains the
+// path to a deck, optionally followed by a ';' and the comma separated list
+// of the subsections to pick. Empty lines and lines starting with '#' are
+// ignored.
+func parseManifest(r io.Reader) ([]manifestEntry, error) {
+	var entries []manifestEntry
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		split := strings.SplitN(line, ";", 2)
+		entry := manifestEntry{path: strings.TrimSpace(split[0])}
+		if len(split) == 2 && len(strings.TrimSpace(split[1])) != 0 {
+			entry.subsections = strings.Split(strings.TrimSpace(split[1]), ",")
+		}
+		entries = append(entries, entry)
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("Failed to read the batch manifest: %v", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("The batch manifest does not list any deck.")
+	}
+	return entries, nil
+}
+
+// RunBatch questions the user on each deck listed in the manifest, one
+// after the other. The open function gives access to the content of a deck
+// from its path.
+func RunBatch(manifest io.Reader, open func(path string) (io.ReadCloser, error), tpp TopicParsingParameters, p InterrogationParameters) (BatchResult, error) {
+	var result BatchResult
+	entries, err := parseManifest(manifest)
+	if err != nil {
+		return result, err
+	}
+
+	// The user input is read once for all the decks, otherwise each session
+	// would start its own reader on the same input.
+	commands := make(chan string)
+	if p.readsCommands() && p.in != nil {
+		go readCommands(context.Background(), p.in, commands)
+		p.in = nil
+	}
+
+	for _, entry := range entries {
+		deck, err := open(entry.path)
+		if err != nil {
+			return result, fmt.Errorf("Open of the deck %s failed: %v", entry.path, err)
+		}
+		topic, err := ParseDeck(entry.path, deck, tpp)
+		deck.Close()
+		if err != nil {
+			return result, fmt.Errorf("Parse of the deck %s failed: %v", entry.path, err)
+		}
+		if p.IsVerbose() {
+			WriteParseSummary(p.GetVerboseStream(), entry.path, tpp, topic)
+		}
+
+		qa := p.BuildQuestionsSet(topic, entry.subsections...)
+		if qa.GetCount() == 0 {
+			return result, fmt.Errorf("The deck %s has no question to ask.", entry.path)
+		}
+		fmt.Fprintf(p.GetOutputStream(), "Deck: %s\n", entry.path)
+		deckResult := DeckResult{
+			Path:   entry.path,
+			Result: askQuestions(context.Background(), qa, p.ApplyMetadata(topic.Metadata()), commands),
+		}
+		result.Decks = append(result.Decks, deckResult)
+		result.Total.Add(deckResult.Result)
+	}
+	return result, nil
+}
+
+// WriteReport writes the breakdown of the batch per deck and the grand
+// total.
+func (r BatchResult) WriteReport(out io.Writer) {
+	fmt.Fprintln(out, "Batch report:")
+	fmt.Fprintln(out, "=============")
+	for _, deck := range r.Decks {
+		fmt.Fprintf(out, "  * %s: %d questions asked%s\n", deck.Path, deck.Result.Asked, scoreSuffix(deck.Result))
+	}
+	fmt.Fprintf(out, "Total: %d questions asked%s\n", r.Total.Asked, scoreSuffix(r.Total))
+}
+
+// scoreSuffix returns the score to append to a line of the report when the
+// answers were graded.
+func scoreSuffix(r SessionResult) string {
+	if r.Graded == 0 {
+		return ""
+	}
+	return ", score " + r.Score()
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// getDeckOpener returns an opener giving access to in-memory decks.
+func getDeckOpener(decks map[string]string) func(string) (io.ReadCloser, error) {
+	return func(path string) (io.ReadCloser, error) {
+		content, found := decks[path]
+		if !found {
+			return nil, fmt.Errorf("no such file: %s", path)
+		}
+		return ioutil.NopCloser(strings.NewReader(content)), nil
+	}
+}
+
+// TestParseManifest checks the reading of the list of decks.
+func TestParseManifest(t *testing.T) {
+	entries, err := parseManifest(strings.NewReader(`
+# my decks
+deck1.csv
+deck2.csv;1,3
+`))
+	if err != nil {
+		t.Fatalf("A valid manifest should not trigger an error: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("We were expecting 2 decks but found %d\n", len(entries))
+	}
+	if entries[0].path != "deck1.csv" || entries[0].subsections != nil {
+		t.Errorf("The first deck should be deck1.csv without subsections but we got %+v\n", entries[0])
+	}
+	if entries[1].path != "deck2.csv" || strings.Join(entries[1].subsections, ",") != "1,3" {
+		t.Errorf("The second deck should be deck2.csv with subsections 1 and 3 but we got %+v\n", entries[1])
+	}
+
+	if _, err := parseManifest(strings.NewReader("\n# nothing\n")); err == nil {
+		t.Errorf("An empty manifest should trigger an error.")
+	}
+}
+
+// TestRunBatch checks that all the decks of the manifest are run and that
+// the results are aggregated.
+func TestRunBatch(t *testing.T) {
+	decks := map[string]string{
+		"deck1.csv": getSampleCsvAsStream(),
+		"deck2.csv": "### Lesson A\nq1;a1\nq2;a2\n",
+	}
+	manifest := strings.NewReader("deck1.csv;1,2\ndeck2.csv\n")
+
+	var out bytes.Buffer
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.out = &out
+	ip.limit = 2
+
+	result, err := RunBatch(manifest, getDeckOpener(decks), getTpp(), ip)
+	if err != nil {
+		t.Fatalf("Running the batch failed: %v", err)
+	}
+	if len(result.Decks) != 2 {
+		t.Fatalf("Both decks should have been run but we have %d results\n", len(result.Decks))
+	}
+	if result.Decks[0].Result.Asked != 6 {
+		t.Errorf("The first deck should have asked 3 questions twice but asked %d\n", result.Decks[0].Result.Asked)
+	}
+	if result.Decks[1].Result.Asked != 4 {
+		t.Errorf("The second deck should have asked 2 questions twice but asked %d\n", result.Decks[1].Result.Asked)
+	}
+	if result.Total.Asked != 10 {
+		t.Errorf("The total should be 10 questions asked but is %d\n", result.Total.Asked)
+	}
+	if !strings.Contains(out.String(), "q2     --> a2") {
+		t.Errorf("The questions of the second deck were not asked. Output was:\n%s\n", out.String())
+	}
+
+	var report bytes.Buffer
+	result.WriteReport(&report)
+	if !strings.Contains(report.String(), "Total: 10 questions asked") {
+		t.Errorf("The report does not show the grand total:\n%s\n", report.String())
+	}
+}
+
+// TestRunBatchMissingDeck checks that a deck that cannot be opened stops
+// the batch with an error.
+func TestRunBatchMissingDeck(t *testing.T) {
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.out = ioutil.Discard
+	_, err := RunBatch(strings.NewReader("missing.csv\n"), getDeckOpener(nil), getTpp(), ip)
+	if err == nil {
+		t.Errorf("A missing deck should trigger an error.")
+	}
+}
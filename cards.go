@@ -0,0 +1,23 @@
+package main
+
+// Card is a question of a topic with its answer and the subsection it
+// belongs to.
+type Card struct {
+	Question   string
+	Answer     string
+	Subsection string
+}
+
+// Cards returns all the questions of the topic as cards, subsection after
+// subsection in the order they were added, and in their order inside each
+// subsection.
+func (topic Topic) Cards() []Card {
+	var cards []Card
+	for _, id := range topic.GetSubsectionsName() {
+		qa := topic.list[id]
+		for i := range qa.questions {
+			cards = append(cards, Card{Question: qa.questions[i], Answer: qa.answers[i], Subsection: id})
+		}
+	}
+	return cards
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCards checks that every question of the sample deck is a card that
+// knows its subsection, in the order of the file.
+func TestCards(t *testing.T) {
+	topic := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp())
+	cards := topic.Cards()
+
+	if len(cards) != topic.BuildQuestionsSet().GetCount() {
+		t.Errorf("There should be a card per question but we got %d cards\n", len(cards))
+	}
+	expected := []Card{
+		{"1_Question 1", "1_Answer 1", "1"},
+		{"2_Question 1", "2_Answer 1", "2"},
+		{"2_Question 2", "2_Answer 2", "2"},
+		{"3_Question 1", "3_Answer 1", "3"},
+		{"3_Question 2", "3_Answer 2", "3"},
+		{"3_Question 3", "3_Answer 3", "3"},
+	}
+	for n, card := range expected {
+		if n >= len(cards) || cards[n] != card {
+			t.Errorf("The card %d should be %+v but we got %+v\n", n, card, cards)
+			break
+		}
+	}
+	if len(NewTopic().Cards()) != 0 {
+		t.Errorf("An empty topic should have no card.")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// defaultDistractors is the number of wrong answers listed with the right
+// one in the multiple choice mode.
+const defaultDistractors = 3
+
+// IsChoiceMode tells if the questions are asked with a numbered list of
+// answers to choose from.
+func (p InterrogationParameters) IsChoiceMode() bool {
+	return p.choice
+}
+
+// GetDistractors returns the number of wrong answers listed with the right
+// one in the multiple choice mode.
+func (p InterrogationParameters) GetDistractors() int {
+	return p.distractors
+}
+
+// Distractors draws up to n wrong answers to the entry i among the other
+// entries of its subsection. answer is the answer expected, and swapped
+// tells if it is taken from the questions. The distractors are distinct
+// and none of them matches the answer expected.
+func (qa QuestionsAnswers) Distractors(i int, answer string, swapped bool, n int, rng *rand.Rand) []string {
+	side := qa.answers
+	if swapped {
+		side = qa.questions
+	}
+	var distractors []string
+	for k := 0; k < qa.GetCount(); k++ {
+		if k == i || qa.origin[k] != qa.origin[i] || isCorrect(side[k], answer) || containsString(distractors, side[k]) {
+			continue
+		}
+		distractors = append(distractors, side[k])
+	}
+	rng.Shuffle(len(distractors), func(a, b int) {
+		distractors[a], distractors[b] = distractors[b], distractors[a]
+	})
+	if len(distractors) > n {
+		distractors = distractors[:n]
+	}
+	return distractors
+}
+
+// multipleChoice returns the answers listed for the entry i, the answer
+// expected being at the position given by key. See placeAnswer for the
+// position.
+func (qa QuestionsAnswers) multipleChoice(i int, answer string, swapped bool, n int, fixed bool, rng *rand.Rand) (options []string, key int) {
+	return placeAnswer(qa.Distractors(i, answer, swapped, n, rng), answer, fixed, rng)
+}
+
+// placeAnswer inserts the answer expected among the wrong answers listed
+// with it and returns the list with the position of the answer, from 0.
+// The position is drawn with rng, so the same seed gives the same list,
+// or is the first one if fixed is set.
+func placeAnswer(options []string, answer string, fixed bool, rng *rand.Rand) ([]string, int) {
+	var key int
+	if !fixed {
+		key = rng.Intn(len(options) + 1)
+	}
+	options = append(options, "")
+	copy(options[key+1:], options[key:])
+	options[key] = answer
+	return options, key
+}
+
+// choicePrompt returns the question followed by the numbered list of the
+// answers to choose from, one per line.
+func choicePrompt(question string, options []string) string {
+	var prompt strings.Builder
+	prompt.WriteString(question)
+	for n, option := range options {
+		fmt.Fprintf(&prompt, "\n  %d. %s", n+1, option)
+	}
+	return prompt.String()
+}
+
+// isChosen tells if the number typed by the user is the one of the answer
+// expected.
+func isChosen(given string, key int) bool {
+	return strings.TrimSpace(given) == fmt.Sprint(key+1)
+}
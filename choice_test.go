@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// TestDistractors checks that the wrong answers come from the subsection
+// of the question, once each, and never match the right answer.
+func TestDistractors(t *testing.T) {
+	deck := "### Lesson 1\nmanger;to eat\ndîner;To Eat\nboire;to drink\nboire;to drink\ndormir;to sleep\n### Lesson 2\ncourir;to run\n"
+	qa := ParseTopic(strings.NewReader(deck), getTpp()).BuildQuestionsSet()
+	rng := rand.New(rand.NewSource(1))
+
+	distractors := qa.Distractors(0, "to eat", false, 5, rng)
+	sort.Strings(distractors)
+	if expected := []string{"to drink", "to sleep"}; !reflect.DeepEqual(distractors, expected) {
+		t.Errorf("The distractors should be %v but we got %v\n", expected, distractors)
+	}
+	if distractors := qa.Distractors(0, "to eat", false, 1, rng); len(distractors) != 1 {
+		t.Errorf("Only 1 distractor should be drawn but we got %v\n", distractors)
+	}
+	if distractors := qa.Distractors(0, "manger", true, 5, rng); len(distractors) != 3 {
+		t.Errorf("The questions of the subsection should be drawn when the prompt is swapped but we got %v\n", distractors)
+	}
+
+	options, key := qa.multipleChoice(5, "to run", false, 3, false, rng)
+	if len(options) != 1 || key != 0 {
+		t.Errorf("A question alone in its subsection should have only its answer listed but we got %v\n", options)
+	}
+	if prompt := choicePrompt("courir", options); prompt != "courir\n  1. to run" {
+		t.Errorf("The answers should be numbered under the question but we got '%s'\n", prompt)
+	}
+}
+
+// TestChoiceMode checks that the number typed is checked against the one of
+// the right answer.
+func TestChoiceMode(t *testing.T) {
+	qa := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp()).BuildQuestionsSet()
+
+	userIn, userOut := io.Pipe()
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.in = userIn
+	ip.mode = linear
+	ip.graded = true
+	ip.choice = true
+	ip.distractors = 2
+	ip.limit = 1
+	ip.rng = rand.New(rand.NewSource(1))
+
+	// The same draws give the position of the right answers. Question 3 is
+	// missed: it has 3 answers listed.
+	rng := rand.New(rand.NewSource(1))
+	keys := make([]int, qa.GetCount())
+	for i := range qa.answers {
+		_, keys[i] = qa.multipleChoice(i, qa.answers[i], false, ip.distractors, false, rng)
+	}
+	go func() {
+		for i, key := range keys {
+			if i == 3 {
+				key = (key + 1) % 3
+			}
+			fmt.Fprintln(userOut, key+1)
+		}
+	}()
+
+	var result SessionResult
+	pr, pw := io.Pipe()
+	ip.out = pw
+	go func() {
+		defer pw.Close()
+		result = AskQuestions(qa, ip)
+	}()
+	io.Copy(ioutil.Discard, pr)
+
+	if result.Graded != qa.GetCount() || result.Correct != qa.GetCount()-1 || !reflect.DeepEqual(result.Wrong, []int{3}) {
+		t.Errorf("Only the question 3 should be wrong but we got %+v\n", result)
+	}
+	if len(result.Choices) != qa.GetCount() {
+		t.Fatalf("The answers chosen should be in the result but we got %+v\n", result.Choices)
+	}
+	for n, choice := range result.Choices {
+		if choice.Index != n || choice.Key != keys[n]+1 {
+			t.Errorf("The answer %d should be the number %d but we got %+v\n", n, keys[n]+1, choice)
+		}
+	}
+}
+
+// TestMultipleChoiceOrder checks that the same seed lists the answers in
+// the same order, the right one being at various positions, and that the
+// fixed order lists it first.
+func TestMultipleChoiceOrder(t *testing.T) {
+	qa := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp()).BuildQuestionsSet()
+	draw := func(seed int64, fixed bool) ([][]string, []int) {
+		rng := rand.New(rand.NewSource(seed))
+		var lists [][]string
+		var keys []int
+		for i := range qa.answers {
+			options, key := qa.multipleChoice(i, qa.answers[i], false, 2, fixed, rng)
+			lists, keys = append(lists, options), append(keys, key)
+		}
+		return lists, keys
+	}
+
+	lists, keys := draw(7, false)
+	if again, againKeys := draw(7, false); !reflect.DeepEqual(lists, again) || !reflect.DeepEqual(keys, againKeys) {
+		t.Errorf("The same seed should list the answers in the same order but we got %v and %v\n", lists, again)
+	}
+	positions := make(map[int]bool)
+	for i, key := range keys {
+		positions[key] = true
+		if lists[i][key] != qa.answers[i] {
+			t.Errorf("The key %d of the question %d is not its answer in %v\n", key, i, lists[i])
+		}
+	}
+	if len(positions) < 2 {
+		t.Errorf("The right answer should be listed at various positions but we got %v\n", keys)
+	}
+
+	lists, keys = draw(7, true)
+	for i, key := range keys {
+		if key != 0 || lists[i][0] != qa.answers[i] {
+			t.Errorf("The right answer should be listed first with the fixed order but we got %v\n", lists[i])
+		}
+	}
+}
+
+// TestFixChoiceOrder checks that -fix-choice-order lists the right answer
+// first in the session, where it is graded as such.
+func TestFixChoiceOrder(t *testing.T) {
+	p, err := parse(noEnvironment, "-m", "choice", "-fix-choice-order")
+	if err != nil {
+		t.Fatal(err)
+	}
+	qa := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp()).BuildQuestionsSet()
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.mode = linear
+	ip.graded = true
+	ip.choice = true
+	ip.distractors = 2
+	ip.limit = 1
+	ip.fixChoices = p.fixChoices
+	ip.rng = rand.New(rand.NewSource(1))
+	_, result := getCardsAsked(qa, ip, strings.Repeat("1\n", qa.GetCount()))
+	if result.Correct != qa.GetCount() {
+		t.Errorf("The first answer listed should always be the right one but we got %+v\n", result)
+	}
+}
+
+// TestPlaceAnswerOrder checks that the same seed lists the answers in the
+// same order, and that the answer is listed first when the order is fixed.
+func TestPlaceAnswerOrder(t *testing.T) {
+	wrong := func() []string {
+		return []string{"un", "deux", "trois"}
+	}
+	first, key := placeAnswer(wrong(), "quatre", false, rand.New(rand.NewSource(7)))
+	second, again := placeAnswer(wrong(), "quatre", false, rand.New(rand.NewSource(7)))
+	if !reflect.DeepEqual(first, second) || key != again {
+		t.Errorf("The same seed should give the same list: %v (%d) and %v (%d)\n", first, key, second, again)
+	}
+	if len(first) != 4 || first[key] != "quatre" {
+		t.Errorf("The answer expected should be listed at %d: %v\n", key, first)
+	}
+
+	seen := make(map[int]bool)
+	rng := rand.New(rand.NewSource(7))
+	for n := 0; n < 50; n++ {
+		_, key := placeAnswer(wrong(), "quatre", false, rng)
+		seen[key] = true
+	}
+	if len(seen) < 2 {
+		t.Errorf("The answer expected should not always be listed at the same position: %v\n", seen)
+	}
+
+	fixed, key := placeAnswer(wrong(), "quatre", true, rand.New(rand.NewSource(7)))
+	if key != 0 || !reflect.DeepEqual(fixed, []string{"quatre", "un", "deux", "trois"}) {
+		t.Errorf("With a fixed order, the answer expected should be listed first: %v (%d)\n", fixed, key)
+	}
+}
+
+// TestIsChosen checks that the number of the answer expected is accepted
+// wherever it is listed, and that the other numbers are not.
+func TestIsChosen(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	for n := 0; n < 20; n++ {
+		options, key := placeAnswer([]string{"un", "deux", "trois"}, "quatre", false, rng)
+		for k := range options {
+			given := string(rune('1' + k))
+			if isChosen(given, key) != (options[k] == "quatre") {
+				t.Errorf("The number %s should be graded %v in %v\n", given, options[k] == "quatre", options)
+			}
+		}
+		if !isChosen(" "+string(rune('1'+key))+" ", key) {
+			t.Errorf("The spaces around the number typed should be ignored.\n")
+		}
+	}
+}
+
+// TestFixChoiceOrderOption checks that -fix-choice-order is read from the
+// command line.
+func TestFixChoiceOrderOption(t *testing.T) {
+	p, err := parse(noEnvironment, "-fix-choice-order")
+	if err != nil {
+		t.Fatalf("The option should be accepted: %v\n", err)
+	}
+	if !p.fixChoices {
+		t.Errorf("-fix-choice-order should list the answer expected first.\n")
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import "strings"
+
+// clozeBlank replaces the deletion asked in the question of a cloze card.
+const clozeBlank = "[...]"
+
+// clozePart is a piece of a line with cloze deletions: some text, or a
+// deletion whose text is hidden in one of the cards.
+type clozePart struct {
+	text    string
+	deleted bool
+}
+
+// clozeCards returns the questions and the answers of a line with cloze
+// deletions, like 'The capital of France is {{Paris}}': one card per
+// deletion, whose question is the line with this deletion blanked and the
+// others shown, and whose answer is the text of the deletion. They are nil
+// if the line has no deletion.
+func clozeCards(line string) ([]string, []string) {
+	var parts []clozePart
+	deletions := 0
+	for rest := line; len(rest) != 0; {
+		start := strings.Index(rest, "{{")
+		end := -1
+		if start != -1 {
+			end = strings.Index(rest[start:], "}}")
+		}
+		if start == -1 || end == -1 {
+			parts = append(parts, clozePart{text: rest})
+			break
+		}
+		end += start
+		deleted := strings.TrimSpace(rest[start+2 : end])
+		parts = append(parts, clozePart{text: rest[:start]})
+		if len(deleted) == 0 {
+			// An empty deletion has nothing to ask: it is kept as text.
+			parts = append(parts, clozePart{text: rest[start : end+2]})
+		} else {
+			parts = append(parts, clozePart{text: deleted, deleted: true})
+			deletions++
+		}
+		rest = rest[end+2:]
+	}
+	if deletions == 0 {
+		return nil, nil
+	}
+	questions := make([]string, 0, deletions)
+	answers := make([]string, 0, deletions)
+	for k, asked := range parts {
+		if !asked.deleted {
+			continue
+		}
+		var question strings.Builder
+		for n, part := range parts {
+			if n == k {
+				question.WriteString(clozeBlank)
+			} else {
+				question.WriteString(part.text)
+			}
+		}
+		questions = append(questions, strings.TrimSpace(question.String()))
+		answers = append(answers, asked.text)
+	}
+	return questions, answers
+}
+
+// clozeEntries returns the cards of a line with cloze deletions, see
+// clozeCards. The tags end the line, like they end an answer. The cards
+// remember the line without its tags to write it back.
+func (p TopicParsingParameters) clozeEntries(line string) []entry {
+	var tags []string
+	if p.TagPrefix != "" {
+		line, tags = extractTags(line, p.TagPrefix)
+	}
+	questions, answers := clozeCards(line)
+	cards := make([]entry, len(questions))
+	for n := range questions {
+		if p.Normalize {
+			questions[n] = normalizeText(questions[n])
+			answers[n] = normalizeText(answers[n])
+		}
+		cards[n] = entry{question: questions[n], answer: answers[n], tags: tags, difficulty: p.DefaultDifficulty, cloze: line}
+	}
+	return cards
+}
+
+// IsCloze tells if the entry of index i is a card made from a line with
+// cloze deletions. Its prompt is never swapped with its answer.
+func (qa QuestionsAnswers) IsCloze(i int) bool {
+	return len(qa.cloze[i]) != 0
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestClozeCards checks that a card is made for each deletion of a line.
+func TestClozeCards(t *testing.T) {
+	cases := []struct {
+		line      string
+		questions []string
+		answers   []string
+	}{
+		{"The capital of France is {{Paris}}", []string{"The capital of France is [...]"}, []string{"Paris"}},
+		{"{{Paris}} is the capital of {{ France }}.", []string{"[...] is the capital of France.", "Paris is the capital of [...]."}, []string{"Paris", "France"}},
+		{"No deletion here", nil, nil},
+		{"An empty {{}} or an unclosed {{one", nil, nil},
+	}
+	for _, c := range cases {
+		questions, answers := clozeCards(c.line)
+		if !reflect.DeepEqual(questions, c.questions) || !reflect.DeepEqual(answers, c.answers) {
+			t.Errorf("The cards of '%s' should be %q and %q but we got %q and %q\n", c.line, c.questions, c.answers, questions, answers)
+		}
+	}
+}
+
+// TestParseCloze checks that the lines with cloze deletions of a deck give
+// their cards, written back as the same lines.
+func TestParseCloze(t *testing.T) {
+	deck := "### Lesson 1\n{{Paris}} is the capital of {{France}} #geography\nmanger;to eat\n"
+	tpp := getTpp()
+	tpp.TagPrefix = "#"
+	topic := ParseTopic(strings.NewReader(deck), tpp)
+
+	qa := topic.GetSubsection("1")
+	if !reflect.DeepEqual(qa.questions, []string{"[...] is the capital of France", "Paris is the capital of [...]", "manger"}) {
+		t.Errorf("The line with two deletions should give two cards but we got %q\n", qa.questions)
+	}
+	if !qa.IsCloze(1) || qa.IsCloze(2) || !reflect.DeepEqual(qa.tags[1], []string{"geography"}) {
+		t.Errorf("The cards of the cloze line should be cloze ones with its tags but we got %v and %v\n", qa.cloze, qa.tags)
+	}
+
+	var out bytes.Buffer
+	if err := WriteTopic(&out, topic, tpp); err != nil || out.String() != deck {
+		t.Errorf("The deck should be written back identically but we got %v and:\n%s\n", err, out.String())
+	}
+}
+
+// TestAskClozeReversed checks that the prompt of a cloze card stays the
+// line with the blank when the questions are reversed.
+func TestAskClozeReversed(t *testing.T) {
+	topic := ParseTopic(strings.NewReader("### Lesson 1\nThe capital of France is {{Paris}}\n"), getTpp())
+	qa := topic.BuildQuestionsSet()
+
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.limit = 1
+	ip.noColor = true
+	ip.reversed = true
+	ip.in = strings.NewReader("\n")
+	pr, pw := io.Pipe()
+	ip.out = pw
+	go func() {
+		defer pw.Close()
+		AskQuestions(qa, ip)
+	}()
+	output, _ := ioutil.ReadAll(pr)
+
+	if !strings.Contains(string(output), "The capital of France is [...]") || !strings.Contains(string(output), answerArrow+"Paris") {
+		t.Errorf("The blank should be asked and Paris revealed. Output was:\n%s\n", output)
+	}
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// learnCommand is the subcommand asking the questions of a deck, like in:
+// repeatit learn deck.csv -i. It is the one run without subcommand.
+const learnCommand = "learn"
+
+// defaultAddress is the address the serve subcommand listens on when -addr
+// is not set.
+const defaultAddress = "localhost:8080"
+
+// deckFlags are the options of learn telling how a deck is parsed. The
+// subcommands reading a deck take them too.
+var deckFlags = []string{"sep", "announce", "prefix", "connector", "alt-sep", "multi-answer", "normalize", "skip-header", "questions-only", "hint-note", "legacy-split"}
+
+// commands are the subcommands other than learn and review, by name. Each
+// one parses its own options in args, with the defaults of env, and writes
+// its result to out.
+var commands = map[string]func(env environment, args []string, out io.Writer) error{
+	"list":     listCommand,
+	"stats":    statsCommand,
+	"validate": validateCommand,
+	"convert":  convertCommand,
+	"serve":    serveCommand,
+	"fmt":      fmtCommand,
+}
+
+// commandFlagSet returns the options of the subcommand name: the options of
+// learn listed in names, and -config. They are set on p like in learn.
+func commandFlagSet(name string, p *InterrogationParameters, failed *error, names ...string) *flag.FlagSet {
+	all := newFlagSet(p, failed)
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+	for _, n := range append(names, "config") {
+		f := all.Lookup(n)
+		fs.Var(f.Value, f.Name, f.Usage)
+	}
+	return fs
+}
+
+// parseCommand reads the arguments of a subcommand: the path of a deck and
+// the options, the path being before or after them. It returns the path.
+func parseCommand(fs *flag.FlagSet, args []string, failed *error) (string, error) {
+	var deck string
+	if len(args) != 0 && !strings.HasPrefix(args[0], "-") {
+		deck, args = args[0], args[1:]
+	}
+	if err := fs.Parse(args); err != nil {
+		if *failed != nil {
+			return "", *failed
+		}
+		return "", flagError(err)
+	}
+	rest := fs.Args()
+	if deck == "" && len(rest) != 0 {
+		deck, rest = rest[0], rest[1:]
+	}
+	if len(rest) != 0 {
+		return "", optionErrorf(rest[0], ErrUnknownOption, "The argument %s is not an option.", rest[0])
+	}
+	if deck == "" {
+		return "", fmt.Errorf("The %s subcommand needs the path of a deck.", fs.Name())
+	}
+	return deck, nil
+}
+
+// listCommand writes the topics of a deck, like -s does.
+func listCommand(env environment, args []string, out io.Writer) error {
+	p, err := loadDefaults(env, args)
+	if err != nil {
+		return err
+	}
+	var failed error
+	deck, err := parseCommand(commandFlagSet("list", &p, &failed, deckFlags...), args, &failed)
+	if err != nil {
+		return err
+	}
+	topic, err := ParseTopicFromFile(deck, p.GetTopicParsingParameters())
+	if err != nil {
+		return err
+	}
+	return topic.WriteTopicsList(out)
+}
+
+// statsCommand writes the statistics kept with -stats on the questions of
+// a deck.
+func statsCommand(env environment, args []string, out io.Writer) error {
+	p, err := loadDefaults(env, args)
+	if err != nil {
+		return err
+	}
+	var failed error
+	deck, err := parseCommand(commandFlagSet("stats", &p, &failed, append(deckFlags, "stats", "data-dir")...), args, &failed)
+	if err != nil {
+		return err
+	}
+	p.resolveDataPaths()
+	if p.GetStatsPath() == "" {
+		return errors.New("The file of the statistics must be set with -stats.")
+	}
+	topic, err := ParseTopicFromFile(deck, p.GetTopicParsingParameters())
+	if err != nil {
+		return err
+	}
+	stats, err := LoadStats(p.GetStatsPath())
+	if err != nil {
+		return err
+	}
+	return stats.WriteReport(out, topic.BuildQuestionsSet())
+}
+
+// validateCommand writes the problems found in a deck. It fails if there
+// is any.
+func validateCommand(env environment, args []string, out io.Writer) error {
+	p, err := loadDefaults(env, args)
+	if err != nil {
+		return err
+	}
+	var failed error
+	deck, err := parseCommand(commandFlagSet("validate", &p, &failed, deckFlags...), args, &failed)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(deck)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	problems, err := ValidateDeck(deck, file, p.GetTopicParsingParameters())
+	if err != nil {
+		return err
+	}
+	for _, problem := range problems {
+		fmt.Fprintln(out, problem)
+	}
+	if len(problems) != 0 {
+		return fmt.Errorf("%d problems found in %s.", len(problems), deck)
+	}
+	fmt.Fprintf(out, "No problem found in %s.\n", deck)
+	return nil
+}
+
+// convertCommand writes a deck in another format, to the file of -o or to
+// out. The format is the one of -to, or else the one of the extension of
+// the file.
+func convertCommand(env environment, args []string, out io.Writer) error {
+	p, err := loadDefaults(env, args)
+	if err != nil {
+		return err
+	}
+	var failed error
+	fs := commandFlagSet("convert", &p, &failed, deckFlags...)
+	output := fs.String("o", "", "the `file` where the deck converted is written. Default is the output.")
+	from := fs.String("from", "", "the `format` of the deck read: anki for the notes exported by Anki as plain\n"+
+		"text, quizlet for a set exported by Quizlet. Default is the one of the extension\n"+
+		"of the deck.")
+	format := fs.String("to", "", "the `format` of the deck converted: csv, yaml, html or anki. Default is the\n"+
+		"one of the extension of -o, or csv.")
+	deck, err := parseCommand(fs, args, &failed)
+	if err != nil {
+		return err
+	}
+	if *format == "" {
+		*format = formatFromPath(*output)
+	}
+	if !deckFormats[*format] {
+		return optionErrorf("-to", ErrInvalidValue, "The format you set (%s) is unknown. Choose csv, yaml, html or anki.", *format)
+	}
+	tpp := p.GetTopicParsingParameters()
+	topic, err := importDeck(deck, *from, tpp)
+	if err != nil {
+		return err
+	}
+	write := func(w io.Writer) error {
+		return ConvertTopic(w, topic, *format, tpp)
+	}
+	if *output == "" {
+		return write(out)
+	}
+	return writeFile(*output, write)
+}
+
+// fmtCommand writes a deck in the canonical csv format, see FormatTopic,
+// to out or, with -w, to the file of the deck.
+func fmtCommand(env environment, args []string, out io.Writer) error {
+	p, err := loadDefaults(env, args)
+	if err != nil {
+		return err
+	}
+	var failed error
+	fs := commandFlagSet("fmt", &p, &failed, deckFlags...)
+	write := fs.Bool("w", false, "write the deck formatted to its file instead of the output.")
+	sorted := fs.Bool("sort", false, "sort the topics by name instead of keeping their order.")
+	deck, err := parseCommand(fs, args, &failed)
+	if err != nil {
+		return err
+	}
+	tpp := p.GetTopicParsingParameters()
+	topic, err := ParseTopicFromFile(deck, tpp)
+	if err != nil {
+		return err
+	}
+	topic = FormatTopic(topic, *sorted)
+	if !*write {
+		return WriteTopic(out, topic, tpp)
+	}
+	return writeFile(deck, func(w io.Writer) error {
+		return WriteTopic(w, topic, tpp)
+	})
+}
+
+// importDeck reads the deck at path in the format, anki or quizlet, or
+// like ParseTopicFromFile if the format is empty. The cards of a Quizlet
+// set are in a subsection named after the file.
+func importDeck(path string, format string, p TopicParsingParameters) (Topic, error) {
+	switch format {
+	case "":
+		return ParseTopicFromFile(path, p)
+	case "anki", "quizlet":
+	default:
+		return NewTopic(), optionErrorf("-from", ErrInvalidValue, "The format you set (%s) is unknown. Choose anki or quizlet.", format)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return NewTopic(), err
+	}
+	defer file.Close()
+	if format == "anki" {
+		return ParseTopicAnki(file)
+	}
+	return ParseTopicQuizlet(file, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)), "")
+}
+
+// serveCommand serves the flashcards of a deck over HTTP until the program
+// is stopped.
+func serveCommand(env environment, args []string, out io.Writer) error {
+	p, err := loadDefaults(env, args)
+	if err != nil {
+		return err
+	}
+	var failed error
+	fs := commandFlagSet("serve", &p, &failed, deckFlags...)
+	address := fs.String("addr", defaultAddress, "the `address` the flashcards are served on.")
+	deck, err := parseCommand(fs, args, &failed)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Serving the flashcards of %s on http://%s/\n", deck, *address)
+	return http.ListenAndServe(*address, DeckHandler(deck, p.GetTopicParsingParameters()))
+}
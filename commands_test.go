@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeDeck writes the deck to a file of the temporary directory of the
+// test and returns its path.
+func writeDeck(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestParseCommand checks that the path of the deck is read before or
+// after the options of a subcommand.
+func TestParseCommand(t *testing.T) {
+	for _, args := range [][]string{{"deck.csv", "-sep", ","}, {"-sep", ",", "deck.csv"}} {
+		p := NewInterrogationParameters()
+		var failed error
+		deck, err := parseCommand(commandFlagSet("list", &p, &failed, deckFlags...), args, &failed)
+		if err != nil || deck != "deck.csv" || p.separator != "," {
+			t.Errorf("Parsing %v should give the deck and the separator but we got '%s', '%s' and %v\n", args, deck, p.separator, err)
+		}
+	}
+	p := NewInterrogationParameters()
+	var failed error
+	if _, err := parseCommand(commandFlagSet("list", &p, &failed, deckFlags...), []string{"deck.csv", "-i"}, &failed); !errors.Is(err, ErrUnknownOption) {
+		t.Errorf("The options of learn should be refused by list but we got %v\n", err)
+	}
+	if _, err := parseCommand(commandFlagSet("list", &p, &failed, deckFlags...), nil, &failed); err == nil {
+		t.Errorf("A subcommand without deck should fail\n")
+	}
+}
+
+// TestListCommand checks that the topics of the deck are listed.
+func TestListCommand(t *testing.T) {
+	path := writeDeck(t, "deck.csv", "### Lesson 1\nmanger,to eat\n### Lesson 2\nboire,to drink\n")
+	var out bytes.Buffer
+	if err := listCommand(noEnvironment, []string{path, "-sep", ","}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "  * Lesson 1\n  * Lesson 2\n") {
+		t.Errorf("The topics should be listed. Output was:\n%s\n", out.String())
+	}
+}
+
+// TestConvertCommand checks that a csv deck converted to YAML is read
+// again with the same questions.
+func TestConvertCommand(t *testing.T) {
+	path := writeDeck(t, "deck.csv", "### Lesson 1\nmanger;to eat\n### Lesson 2\nboire;to drink\n")
+	output := filepath.Join(filepath.Dir(path), "deck.yaml")
+	if err := convertCommand(noEnvironment, []string{path, "-o", output}, nil); err != nil {
+		t.Fatal(err)
+	}
+	tpp := NewInterrogationParameters().GetTopicParsingParameters()
+	converted, err := ParseTopicFromFile(output, tpp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var csv strings.Builder
+	WriteTopic(&csv, converted, tpp)
+	if expected := "### Lesson 1\nmanger;to eat\n\n### Lesson 2\nboire;to drink\n"; csv.String() != expected {
+		t.Errorf("The deck converted should have the same questions but we got:\n%s\n", csv.String())
+	}
+	if err := convertCommand(noEnvironment, []string{path, "-to", "pdf"}, nil); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("An unknown format should be refused but we got %v\n", err)
+	}
+}
+
+// TestFmtCommand checks that the deck formatted is written to the output,
+// or to its file with -w, and that it is read again unchanged.
+func TestFmtCommand(t *testing.T) {
+	deck := "### Lesson 1\nmanger , to eat\n\n\n### Lesson 2\nboire,to drink, to sip"
+	expected := "### Lesson 1\nmanger,to eat\n\n### Lesson 2\nboire,to drink, to sip\n"
+	path := writeDeck(t, "deck.csv", deck)
+	var out bytes.Buffer
+	if err := fmtCommand(noEnvironment, []string{path, "-sep", ","}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != expected {
+		t.Errorf("The deck formatted should be:\n%s\nbut we got:\n%s\n", expected, out.String())
+	}
+	for i := 0; i < 2; i++ {
+		if err := fmtCommand(noEnvironment, []string{path, "-sep", ",", "-w"}, nil); err != nil {
+			t.Fatal(err)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != expected {
+			t.Errorf("The file formatted should be:\n%s\nbut we got:\n%s\n", expected, content)
+		}
+	}
+}
+
+// TestValidateCommand checks that the command fails on a deck with
+// problems.
+func TestValidateCommand(t *testing.T) {
+	var out bytes.Buffer
+	if err := validateCommand(noEnvironment, []string{writeDeck(t, "good.csv", "### Lesson 1\nmanger;to eat\n")}, &out); err != nil {
+		t.Errorf("A valid deck should pass but we got %v. Output was:\n%s\n", err, out.String())
+	}
+	out.Reset()
+	if err := validateCommand(noEnvironment, []string{writeDeck(t, "bad.csv", "### Lesson 1\nmanger to eat\n")}, &out); err == nil || !strings.Contains(out.String(), "Line 2") {
+		t.Errorf("The line without separator should be reported but we got %v. Output was:\n%s\n", err, out.String())
+	}
+}
+
+// TestStatsCommand checks that the statistics of the questions of the deck
+// are written.
+func TestStatsCommand(t *testing.T) {
+	path := writeDeck(t, "deck.csv", "### Lesson 1\nmanger;to eat\nboire;to drink\n")
+	statsPath := filepath.Join(filepath.Dir(path), "stats.json")
+	stats := Stats{questionHash("boire"): {Attempts: 2, Correct: 1, Incorrect: 1}}
+	if err := stats.Save(statsPath); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := statsCommand(noEnvironment, []string{path, "-stats", statsPath}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "50% missed, 1/2 correct, 2 attempts") || !strings.Contains(out.String(), "Questions never asked: 1/2") {
+		t.Errorf("The statistics of the deck should be written. Output was:\n%s\n", out.String())
+	}
+	if err := statsCommand(noEnvironment, []string{path}, &out); err == nil {
+		t.Errorf("The stats subcommand should fail without -stats\n")
+	}
+}
+
+// TestConvertCommandImport checks that a Quizlet set is converted, its
+// cards being in a subsection named after the file.
+func TestConvertCommandImport(t *testing.T) {
+	path := writeDeck(t, "verbs.txt", "manger\tto eat\n")
+	var out bytes.Buffer
+	if err := convertCommand(noEnvironment, []string{path, "-from", "quizlet"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "### verbs\nmanger;to eat\n"; out.String() != expected {
+		t.Errorf("The set should be converted to\n%s\nbut we got\n%s\n", expected, out.String())
+	}
+	if err := convertCommand(noEnvironment, []string{path, "-from", "memrise"}, &out); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("An unknown format should be refused but we got %v\n", err)
+	}
+}
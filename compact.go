@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// compactSeparator separates the question from the answer in the compact
+// transcript.
+const compactSeparator = " | "
+
+// IsCompactMode tells if the questions must be listed one per line with
+// their answer instead of being asked.
+func (p InterrogationParameters) IsCompactMode() bool {
+	return p.compact
+}
+
+// WriteCompact writes each card of the set once, in the order of the set,
+// on a single line like 'question | answer'. The prompt is the answer when
+// the card is reversed, so the transcript shows the cards as they would be
+// asked. There is no banner and no separator: the lines can be searched
+// with grep.
+func (p InterrogationParameters) WriteCompact(w io.Writer, qa QuestionsAnswers) error {
+	for i := 0; i < qa.GetCount(); i++ {
+		question, answer := qa.questions[i], qa.answers[i]
+		if p.isPromptSwapped() != qa.reversed[i] {
+			question, answer = answer, question
+		}
+		if _, err := fmt.Fprintln(w, question+compactSeparator+answer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
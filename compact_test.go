@@ -0,0 +1,36 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestWriteCompact checks that each card of the sample deck is written on a
+// single line, the prompt being the answer in reverse mode.
+func TestWriteCompact(t *testing.T) {
+	topic := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp())
+	qa := topic.BuildQuestionsSet(topic.GetSubsectionsName()...)
+
+	var out bytes.Buffer
+	p := NewInterrogationParameters()
+	if err := p.WriteCompact(&out, qa); err != nil {
+		t.Fatalf("Writing the transcript failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != qa.GetCount() {
+		t.Fatalf("There should be one line per card (%d) but we got:\n%s\n", qa.GetCount(), out.String())
+	}
+	for i, line := range lines {
+		if expected := qa.questions[i] + " | " + qa.answers[i]; line != expected {
+			t.Errorf("The line %d should be '%s' but is '%s'\n", i, expected, line)
+		}
+	}
+
+	out.Reset()
+	p.reversed = true
+	p.WriteCompact(&out, qa)
+	if first := strings.SplitN(out.String(), "\n", 2)[0]; first != qa.answers[0]+" | "+qa.questions[0] {
+		t.Errorf("In reverse mode the answer should come first but we got '%s'\n", first)
+	}
+}
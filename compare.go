@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// GetComparePath returns the path of the file where the last session of
+// each deck is kept to compare the next one to it. Empty means no
+// comparison.
+func (p InterrogationParameters) GetComparePath() string {
+	return p.compare
+}
+
+// Improvement is the progress of a session compared to a previous one.
+// Each delta is the value of the session minus the one of the previous
+// session.
+type Improvement struct {
+	First        bool          // there is no previous session to compare to
+	Correct      int           // delta of the number of correct answers
+	Percentage   float64       // delta of the percentage of the score, in points
+	ResponseTime time.Duration // delta of the average time to answer. 0 if a session has no time
+}
+
+// CompareTo compares the session to a previous one. A previous session
+// where no question was asked means that there is none.
+func (r SessionResult) CompareTo(prev SessionResult) Improvement {
+	if prev.Asked == 0 {
+		return Improvement{First: true}
+	}
+	improvement := Improvement{
+		Correct:    r.Correct - prev.Correct,
+		Percentage: r.percentage() - prev.percentage(),
+	}
+	if r.AverageResponseTime() != 0 && prev.AverageResponseTime() != 0 {
+		improvement.ResponseTime = r.AverageResponseTime() - prev.AverageResponseTime()
+	}
+	return improvement
+}
+
+// String sums up the improvement like "You improved by 12%".
+func (i Improvement) String() string {
+	if i.First {
+		return "This is your first session on this deck."
+	}
+	var summary string
+	switch points := math.Round(i.Percentage); {
+	case points > 0:
+		summary = fmt.Sprintf("You improved by %.0f%%", points)
+	case points < 0:
+		summary = fmt.Sprintf("You regressed by %.0f%%", -points)
+	default:
+		summary = "Your score did not change"
+	}
+	switch {
+	case i.ResponseTime < 0:
+		summary += fmt.Sprintf(", answering %.1fs faster", -i.ResponseTime.Seconds())
+	case i.ResponseTime > 0:
+		summary += fmt.Sprintf(", answering %.1fs slower", i.ResponseTime.Seconds())
+	}
+	return summary + "."
+}
+
+// SessionStats is what is kept of the last session of a deck to compare
+// the next one to it.
+type SessionStats struct {
+	Asked        int           `json:"asked"`
+	Graded       int           `json:"graded"`
+	Correct      int           `json:"correct"`
+	Credit       float64       `json:"credit"`
+	ResponseTime time.Duration `json:"response_time"`
+}
+
+// LastSessions are the last sessions, keyed by the fingerprint of their deck.
+type LastSessions map[string]SessionStats
+
+// ReadLastSessions reads the sessions saved in JSON by Write.
+func ReadLastSessions(r io.Reader) (LastSessions, error) {
+	stats := make(LastSessions)
+	if err := json.NewDecoder(r).Decode(&stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// Write saves the sessions in JSON.
+func (s LastSessions) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// Last returns the last session of the deck. Nothing was asked in it if
+// the deck has no session yet.
+func (s LastSessions) Last(deck string) SessionResult {
+	last := s[deck]
+	return SessionResult{Asked: last.Asked, Graded: last.Graded, Correct: last.Correct, Credit: last.Credit, ResponseTime: last.ResponseTime}
+}
+
+// Update keeps the session as the last one of the deck.
+func (s LastSessions) Update(deck string, r SessionResult) {
+	s[deck] = SessionStats{Asked: r.Asked, Graded: r.Graded, Correct: r.Correct, Credit: r.Credit, ResponseTime: r.ResponseTime}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// TestCompareTo checks the comparison to a previous session that did
+// better, worse, or that does not exist.
+func TestCompareTo(t *testing.T) {
+	prev := SessionResult{Asked: 10, Graded: 10, Correct: 6, Credit: 6, ResponseTime: 40 * time.Second}
+	cases := []struct {
+		name    string
+		result  SessionResult
+		prev    SessionResult
+		summary string
+	}{
+		{"improvement", SessionResult{Asked: 10, Graded: 10, Correct: 8, Credit: 8, ResponseTime: 30 * time.Second}, prev, "You improved by 20%, answering 1.0s faster."},
+		{"regression", SessionResult{Asked: 10, Graded: 10, Correct: 5, Credit: 5.5, ResponseTime: 50 * time.Second}, prev, "You regressed by 5%, answering 1.0s slower."},
+		{"no change", SessionResult{Asked: 5, Graded: 5, Correct: 3, Credit: 3}, prev, "Your score did not change."},
+		{"first", SessionResult{Asked: 10, Graded: 10, Correct: 8, Credit: 8}, SessionResult{}, "This is your first session on this deck."},
+	}
+	for _, c := range cases {
+		if summary := c.result.CompareTo(c.prev).String(); summary != c.summary {
+			t.Errorf("The %s should be summed up as '%s' but we got '%s'\n", c.name, c.summary, summary)
+		}
+	}
+
+	improvement := cases[0].result.CompareTo(prev)
+	if improvement.Correct != 2 || improvement.Percentage != 20 || improvement.ResponseTime != -time.Second {
+		t.Errorf("The deltas should be 2 answers, 20 points and -1s but we got %+v\n", improvement)
+	}
+}
+
+// TestLastSessions checks that the last session of a deck is read back.
+func TestLastSessions(t *testing.T) {
+	stats := make(LastSessions)
+	if !stats.Last("deck").CompareTo(SessionResult{}).First {
+		t.Errorf("A deck without session should have nothing to compare to.")
+	}
+	stats.Update("deck", SessionResult{Asked: 4, Graded: 4, Correct: 3, Credit: 3, ResponseTime: 8 * time.Second})
+
+	var out bytes.Buffer
+	if err := stats.Write(&out); err != nil {
+		t.Fatalf("Writing the sessions failed: %v", err)
+	}
+	read, err := ReadLastSessions(&out)
+	if err != nil {
+		t.Fatalf("Reading the sessions failed: %v", err)
+	}
+	last := read.Last("deck")
+	if last.Correct != 3 || last.Score() != "3/4 (75%)" || last.AverageResponseTime() != 2*time.Second {
+		t.Errorf("The last session should be read back but we got %+v\n", last)
+	}
+	if read.Last("other").Asked != 0 {
+		t.Errorf("The sessions of a deck should not be used for another one.")
+	}
+}
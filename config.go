@@ -0,0 +1,200 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+// configFileName is the name of the config file looked for in the home
+// directory when no -config option is given and there is no config file in
+// the config directory of the user.
+const configFileName = ".simplelearningrc"
+
+// configDirFile is the path of the config file in the config directory of
+// the user, ~/.config on Linux.
+const configDirFile = "simple-learning/config.yaml"
+
+// Config is the content of a config file giving the default values of some
+// options. It is written in YAML, or in JSON for a file whose extension is
+// not .yaml or .yml. For instance:
+//
+//	wait: 1500-3000
+//	mode: linear
+//	separator: "|"
+//	announce: "## "
+//	color: false
+//	theme: dark
+//	data_dir: ~/.simple-learning
+type Config struct {
+	Wait      string `json:"wait" yaml:"wait"`
+	Mode      string `json:"mode" yaml:"mode"`
+	Separator string `json:"separator" yaml:"separator"`
+	Announce  string `json:"announce" yaml:"announce"`
+	Color     *bool  `json:"color" yaml:"color"`
+	Theme     string `json:"theme" yaml:"theme"`
+	DataDir   string `json:"data_dir" yaml:"data_dir"`
+}
+
+// configPath returns the path of the config file: the one given with the
+// -config option, else the one of SIMPLE_LEARNING_CONFIG, else the one of the config directory of the user, else
+// the one of the home directory. required tells if the file was asked for
+// by the user. The option is written like the flag package reads it:
+// -config path, --config path or -config=path.
+func configPath(env environment, args []string) (path string, required bool) {
+	for i, opt := range args {
+		name, value, inline := strings.Cut(strings.TrimPrefix(opt, "-"), "=")
+		if name != "-config" && name != "config" {
+			continue
+		}
+		if inline {
+			return value, true
+		}
+		if i+1 < len(args) {
+			return args[i+1], true
+		}
+	}
+	if path, _ := env.lookup(envName("config")); len(path) != 0 {
+		return path, true
+	}
+	if dir, err := env.configDir(); err == nil {
+		path = filepath.Join(dir, configDirFile)
+		if _, err := os.Stat(path); err == nil {
+			return path, false
+		}
+	}
+	home, err := env.homeDir()
+	if err != nil {
+		return path, false
+	}
+	return filepath.Join(home, configFileName), false
+}
+
+// LoadConfig reads the config file at path. The options it sets are the
+// defaults: the environment variables and the command line take precedence
+// over them, see Parse.
+func LoadConfig(path string) (Config, error) {
+	var c Config
+	file, err := os.Open(path)
+	if err != nil {
+		return c, err
+	}
+	defer file.Close()
+	if isYAMLDeck(path) {
+		err = yaml.NewDecoder(file).Decode(&c)
+		if err == io.EOF {
+			// An empty file sets nothing.
+			err = nil
+		}
+	} else {
+		err = json.NewDecoder(file).Decode(&c)
+	}
+	if err != nil {
+		return c, fmt.Errorf("The config file %s is malformed: %v", path, err)
+	}
+	return c, nil
+}
+
+// Apply sets the options of the config on the parameters.
+func (c Config) Apply(p InterrogationParameters) (InterrogationParameters, error) {
+	var args []string
+	if len(c.Wait) != 0 {
+		args = append(args, "-t", c.Wait)
+	}
+	if len(c.Mode) != 0 {
+		args = append(args, "-m", c.Mode)
+	}
+	if len(c.Separator) != 0 {
+		args = append(args, "-sep", c.Separator)
+	}
+	if len(c.Announce) != 0 {
+		args = append(args, "-announce", c.Announce)
+	}
+	if c.Color != nil {
+		args = append(args, "-no-color="+strconv.FormatBool(!*c.Color))
+	}
+	if len(c.Theme) != 0 {
+		args = append(args, "-theme", c.Theme)
+	}
+	if len(c.DataDir) != 0 {
+		args = append(args, "-data-dir", c.DataDir)
+	}
+	return parseArgs(p, args...)
+}
+
+// colorThemes are the colors of the loop banners, by the name of their
+// theme.
+var colorThemes = map[string][]color.Attribute{
+	"default": {color.FgBlue, color.Bold},
+	"dark":    {color.FgHiCyan, color.Bold},
+	"light":   {color.FgBlue},
+	"mono":    {color.Bold},
+}
+
+// GetTheme returns the name of the colors of the output.
+func (p InterrogationParameters) GetTheme() string {
+	if len(p.theme) == 0 {
+		return "default"
+	}
+	return p.theme
+}
+
+// GetDataDir returns the directory of the files kept across sessions given
+// with a relative path. Empty means the current directory.
+func (p InterrogationParameters) GetDataDir() string {
+	return p.dataDir
+}
+
+// expandHome replaces the ~ starting the path by the home directory, the
+// config files not being expanded by a shell.
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// resolveDataPaths puts in the data directory the files kept across
+// sessions that are given with a relative path.
+func (p *InterrogationParameters) resolveDataPaths() {
+	if len(p.dataDir) == 0 {
+		return
+	}
+	for _, path := range []*string{&p.leeches, &p.fresh, &p.schedule, &p.mastery, &p.compare, &p.stats, &p.leitner, &p.resume} {
+		if len(*path) != 0 && !filepath.IsAbs(*path) {
+			*path = filepath.Join(p.dataDir, *path)
+		}
+	}
+}
+
+// loadConfig sets on the parameters the options of the config file at
+// path. A missing file is not an error unless it is required: the built-in
+// defaults are kept.
+func loadConfig(p InterrogationParameters, path string, required bool) (InterrogationParameters, error) {
+	if len(path) == 0 {
+		return p, nil
+	}
+	c, err := LoadConfig(path)
+	if os.IsNotExist(err) && !required {
+		return p, nil
+	}
+	if err != nil {
+		return p, err
+	}
+	p, err = c.Apply(p)
+	if err != nil {
+		return p, fmt.Errorf("The config file %s is invalid: %w", path, err)
+	}
+	return p, nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeConfig writes a config file in a temporary directory and returns its
+// path.
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("Writing the config file failed: %v", err)
+	}
+	return path
+}
+
+// TestParsingConfig checks that the options of the config file are applied
+// to both the interrogation and the parsing parameters.
+func TestParsingConfig(t *testing.T) {
+	path := writeConfig(t, `{"wait": "1500-3000", "mode": "linear", "separator": "|", "announce": "## ", "color": false}`)
+
+	p, err := parse(noEnvironment, "-config", path)
+	if err != nil {
+		t.Fatalf("A valid config file must not trigger a parsing error: %v", err)
+	}
+	if p.minWait != 1500*time.Millisecond || p.maxWait != 3000*time.Millisecond || p.mode != linear || !p.noColor {
+		t.Errorf("The options of the config file were not applied: %+v\n", p)
+	}
+	tpp := p.GetTopicParsingParameters()
+	if tpp.QaSep != "|" || tpp.TopicAnnounce != "## " {
+		t.Errorf("The parsing options of the config file were not applied: %+v\n", tpp)
+	}
+}
+
+// TestParsingFlagTurnsOffConfig checks that the command line changes the
+// mode and the colors set by the config file, in both directions.
+func TestParsingFlagTurnsOffConfig(t *testing.T) {
+	cases := []struct {
+		content string
+		args    []string
+		noColor bool
+		mode    interrogationMode
+	}{
+		{`{"mode": "linear", "color": false}`, []string{"-m", "random", "-no-color=false"}, false, random},
+		{`{"mode": "random", "color": true}`, []string{"-m", "linear", "-no-color"}, true, linear},
+		{`{"mode": "linear", "color": false}`, nil, true, linear},
+	}
+	for _, c := range cases {
+		p, err := parse(noEnvironment, append([]string{"-config", writeConfig(t, c.content)}, c.args...)...)
+		if err != nil {
+			t.Fatalf("A valid config file must not trigger a parsing error: %v", err)
+		}
+		if p.noColor != c.noColor || p.mode != c.mode {
+			t.Errorf("With %s and %v, no color should be %t and the mode %v but we got %+v\n", c.content, c.args, c.noColor, c.mode, p)
+		}
+	}
+
+	// The environment turns off the colors of the config file too.
+	path := writeConfig(t, `{"color": false}`)
+	p, err := parse(variables(map[string]string{"SIMPLE_LEARNING_NO_COLOR": "false"}), "-config", path)
+	if err != nil || p.noColor {
+		t.Errorf("SIMPLE_LEARNING_NO_COLOR set to false should turn on the colors of the config file: %v %+v\n", err, p)
+	}
+}
+
+// TestParsingFlagOverridesConfig checks that the command line takes
+// precedence over the config file.
+func TestParsingFlagOverridesConfig(t *testing.T) {
+	path := writeConfig(t, `{"wait": "1500", "separator": "|"}`)
+
+	p, err := parse(noEnvironment, "-sep", ",", "-config", path)
+	if err != nil {
+		t.Fatalf("A valid config file must not trigger a parsing error: %v", err)
+	}
+	if p.separator != "," {
+		t.Errorf("The separator of the command line should override the config file. Found '%s'\n", p.separator)
+	}
+	if p.minWait != 1500*time.Millisecond {
+		t.Errorf("The wait of the config file should be kept. Found %v\n", p.minWait)
+	}
+}
+
+// TestParsingMalformedConfig checks that a malformed config file is
+// reported, while a missing default one is ignored.
+func TestParsingMalformedConfig(t *testing.T) {
+	for _, content := range []string{`{"wait": `, `{"mode": "shuffled"}`, `["linear"]`} {
+		if _, err := parse(noEnvironment, "-config", writeConfig(t, content)); err == nil {
+			t.Errorf("The malformed config file '%s' is not detected.", content)
+		}
+	}
+	if _, err := parse(noEnvironment, "-config", filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("The missing config file given with -config is not detected.")
+	}
+
+	home := t.TempDir()
+	if _, err := parse(directories(nil, "", home)); err != nil {
+		t.Errorf("A missing default config file must not trigger a parsing error: %v", err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(home, configFileName), []byte(`{"mode": "shuffled"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parse(directories(nil, "", home)); err == nil {
+		t.Errorf("The malformed config file of the home directory is not detected.")
+	}
+}
+
+// TestConfigPrecedence checks that the YAML config file of the config
+// directory is read, the environment and the command line taking
+// precedence over it.
+func TestConfigPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "simple-learning"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := "wait: 1500\nmode: linear\nseparator: \"|\"\ntheme: dark\ndata_dir: /data\n"
+	if err := ioutil.WriteFile(filepath.Join(dir, configDirFile), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	env := directories(map[string]string{"SL_WAIT": "2500"}, dir, t.TempDir())
+	p, err := parse(env, "-sep", ",", "-stats", "stats.json", "-leitner", "/boxes.json")
+	if err != nil {
+		t.Fatalf("A valid config file must not trigger a parsing error: %v", err)
+	}
+	if p.mode != linear || p.GetTheme() != "dark" {
+		t.Errorf("The options of the config file were not applied: %+v\n", p)
+	}
+	if p.minWait != 2500*time.Millisecond {
+		t.Errorf("The wait of the environment should override the config file. Found %v\n", p.minWait)
+	}
+	if p.separator != "," {
+		t.Errorf("The separator of the command line should override the config file. Found '%s'\n", p.separator)
+	}
+	if p.GetStatsPath() != filepath.Join("/data", "stats.json") || p.GetLeitnerPath() != "/boxes.json" {
+		t.Errorf("Only the relative paths should be in the data directory but we got %s and %s\n", p.GetStatsPath(), p.GetLeitnerPath())
+	}
+
+	if _, err := parse(noEnvironment, "-theme", "pink"); err == nil {
+		t.Errorf("An unknown theme should be refused\n")
+	}
+}
+
+// TestLoadConfig checks that the format of the config file is chosen from
+// its extension.
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "config.yml")
+	if err := ioutil.WriteFile(yamlPath, []byte("mode: typed\ncolor: false\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := LoadConfig(yamlPath)
+	if err != nil || c.Mode != "typed" || c.Color == nil || *c.Color {
+		t.Errorf("The YAML config file should be read but we got %+v and %v\n", c, err)
+	}
+	if _, err := LoadConfig(writeConfig(t, "mode: typed\n")); err == nil {
+		t.Errorf("A file without the YAML extension should be read as JSON\n")
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import "math"
+
+// IsCoverageAsked tells if the user asked how many loops are needed to see
+// all the questions in random mode.
+func (p InterrogationParameters) IsCoverageAsked() bool {
+	return p.coverage
+}
+
+// EstimateCoverageDraws returns the number of random draws expected to see
+// at least once each of n cards, n*H(n) where H(n) = 1 + 1/2 + ... + 1/n
+// is the harmonic number. This is the problem of the coupon collector.
+func EstimateCoverageDraws(n int) float64 {
+	harmonic := 0.0
+	for k := 1; k <= n; k++ {
+		harmonic += 1 / float64(k)
+	}
+	return float64(n) * harmonic
+}
+
+// EstimateCoverageLoops returns the number of loops expected to see at
+// least once each of n cards in random mode, a loop asking n questions. See
+// EstimateCoverageDraws for the number of questions.
+func EstimateCoverageLoops(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	return int(math.Ceil(EstimateCoverageDraws(n) / float64(n)))
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// TestEstimateCoverage checks the estimates of the draws and the loops
+// against n*H(n) computed by hand for small decks.
+func TestEstimateCoverage(t *testing.T) {
+	cases := []struct {
+		n     int
+		draws float64
+		loops int
+	}{
+		{0, 0, 0},
+		{1, 1, 1},
+		{2, 3, 2},
+		{3, 5.5, 2},
+		{4, 25.0 / 3, 3},
+		{10, 7381.0 / 252, 3},
+	}
+	for _, c := range cases {
+		if draws := EstimateCoverageDraws(c.n); math.Abs(draws-c.draws) > 1e-9 {
+			t.Errorf("%d cards should need %f draws but the estimate is %f\n", c.n, c.draws, draws)
+		}
+		if loops := EstimateCoverageLoops(c.n); loops != c.loops {
+			t.Errorf("%d cards should need %d loops but the estimate is %d\n", c.n, c.loops, loops)
+		}
+	}
+}
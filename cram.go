@@ -0,0 +1,71 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// cramCurves give the weight of a subsection in the cram mode from its
+// rank in the file: 1 for the first one, 2 for the second one and so on.
+// The later a subsection comes in the file, the newer it is considered.
+var cramCurves = map[string]func(rank int) int{
+	"flat":   func(rank int) int { return 1 },
+	"linear": func(rank int) int { return rank },
+	"square": func(rank int) int { return rank * rank },
+}
+
+// IsCramMode tells if the newest subsections must be asked first and more
+// often.
+func (p InterrogationParameters) IsCramMode() bool {
+	return p.cram
+}
+
+// BuildCramSet builds the questions of the cram mode from the subsections
+// selected by the user, weighted with the curve chosen.
+func (p InterrogationParameters) BuildCramSet(topic Topic) QuestionsAnswers {
+	return topic.BuildCramSet(cramCurves[p.cramCurve], p.rng, p.GetListOfSubsections()...)
+}
+
+// BuildCramSet creates a set of questions where the questions of the newest
+// subsections, the last ones in the file, are asked first and more often.
+// Each question is added as many times as the weight of its subsection, then
+// the questions are shuffled so that the heaviest ones tend to come first.
+// If no subsection is supplied, the whole topic is used.
+func (topic Topic) BuildCramSet(weight func(rank int) int, rng *rand.Rand, ids ...string) QuestionsAnswers {
+	selected := make(map[string]bool)
+	for _, id := range ids {
+		selected[id] = true
+	}
+
+	type card struct {
+		id  string
+		i   int
+		key float64
+	}
+	var cards []card
+	rank := 0
+	for _, id := range topic.GetSubsectionsName() {
+		if len(ids) != 0 && !selected[id] {
+			continue
+		}
+		rank++
+		w := weight(rank)
+		for i := 0; i < topic.list[id].GetCount(); i++ {
+			for n := 0; n < w; n++ {
+				// Weighted random order: the heavier the card, the smaller
+				// its key is likely to be.
+				cards = append(cards, card{id: id, i: i, key: -math.Log(1-rng.Float64()) / float64(w)})
+			}
+		}
+	}
+	sort.SliceStable(cards, func(a, b int) bool {
+		return cards[a].key < cards[b].key
+	})
+
+	qa := NewQA()
+	for _, c := range cards {
+		qa.appendEntryFromSubsection(c.id, topic.list[c.id], c.i)
+	}
+	return qa
+}
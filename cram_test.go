@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// TestParsingCram checks the options of the cram mode.
+func TestParsingCram(t *testing.T) {
+	p, err := parse(noEnvironment, "-cram", "-m", "random")
+	if err != nil {
+		t.Errorf("Parsing detects the cram option as an error")
+	}
+	if !p.IsCramMode() || p.cramCurve != "linear" || p.mode != linear {
+		t.Errorf("The cram mode should be set with a linear curve and keep the order it builds.")
+	}
+	p, err = parse(noEnvironment, "-cram", "-cram-curve", "square")
+	if err != nil || p.cramCurve != "square" {
+		t.Errorf("Parsing failed to set the square curve.")
+	}
+	if _, err := parse(noEnvironment, "-cram", "-cram-curve", "steep"); err == nil {
+		t.Errorf("The unknown curve 'steep' is not detected.")
+	}
+}
+
+// TestBuildCramSet checks that the questions of the later subsections are
+// asked earlier and more often than the ones of the first subsection.
+func TestBuildCramSet(t *testing.T) {
+	topic := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp())
+	ids := topic.GetSubsectionsName()
+	oldest, newest := ids[0], ids[len(ids)-1]
+
+	qa := topic.BuildCramSet(cramCurves["linear"], rand.New(rand.NewSource(3)))
+
+	count := make(map[string]int)
+	positions := make(map[string]int)
+	for i, origin := range qa.origin {
+		count[origin]++
+		positions[origin] += i
+	}
+	if count[newest] <= count[oldest] {
+		t.Errorf("The newest subsection should be asked more often than the oldest one: %d against %d\n", count[newest], count[oldest])
+	}
+	if positions[newest]/count[newest] >= positions[oldest]/count[oldest] {
+		t.Errorf("The newest subsection should be asked earlier than the oldest one. Order was: %v\n", qa.origin)
+	}
+
+	flat := topic.BuildCramSet(cramCurves["flat"], rand.New(rand.NewSource(3)), oldest)
+	if flat.GetCount() != topic.list[oldest].GetCount() {
+		t.Errorf("With a flat curve each question of the subsection should be asked once. Got %d questions\n", flat.GetCount())
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// mergePolicies are the ways Topic.Merge handles a subsection found in
+// both topics.
+var mergePolicies = map[string]bool{
+	// The subsection added is named after its deck, like 'verbs/Lesson 1'.
+	"prefix": true,
+	// The questions of the subsection added follow the ones of the topic.
+	"merge": true,
+	// The merge fails.
+	"error": true,
+}
+
+// defaultMergePolicy is the policy of Merge when none is given.
+const defaultMergePolicy = "prefix"
+
+// deckExtensions are the extensions of the files read as decks in a
+// directory.
+var deckExtensions = map[string]bool{".csv": true, ".txt": true, ".yaml": true, ".yml": true, ".md": true, ".markdown": true}
+
+// GetMergePolicy returns how the subsections found in several decks are
+// merged: prefix, merge or error.
+func (p InterrogationParameters) GetMergePolicy() string {
+	if p.mergePolicy == "" {
+		return defaultMergePolicy
+	}
+	return p.mergePolicy
+}
+
+// Merge adds the subsections of other to the topic, in their order. The
+// policy tells what becomes of a subsection whose name is already in the
+// topic: with prefix, it is added under the name prefix/name, with merge,
+// its questions are added after the ones of the topic, and with error, the
+// merge fails. An empty policy is prefix. The topic is unchanged if the
+// merge fails.
+func (topic *Topic) Merge(other Topic, prefix string, policy string) error {
+	if policy == "" {
+		policy = defaultMergePolicy
+	}
+	if !mergePolicies[policy] {
+		return fmt.Errorf("The merge policy %s is unknown. Choose prefix, merge or error.", policy)
+	}
+	added := NewTopic()
+	added.metadata = other.metadata
+	for _, id := range other.order {
+		name := id
+		if _, found := topic.list[id]; found {
+			switch policy {
+			case "error":
+				return fmt.Errorf("The topic %s is in both decks.", id)
+			case "prefix":
+				name = prefix + "/" + id
+				if _, found := topic.list[name]; found {
+					return fmt.Errorf("The topic %s is in both decks, even named %s.", id, name)
+				}
+			}
+		}
+		added.SetSubsection(name, other.list[id])
+	}
+	topic.merge(added)
+	return nil
+}
+
+// expandDeckPaths returns the decks given on the command line: a path is a
+// deck, a directory stands for its decks and a glob pattern for the files
+// matching it, sorted by name.
+func expandDeckPaths(patterns []string) ([]string, error) {
+	var paths []string
+	for _, pattern := range patterns {
+		if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+			entries, err := os.ReadDir(pattern)
+			if err != nil {
+				return nil, err
+			}
+			found := false
+			for _, entry := range entries {
+				if !entry.IsDir() && deckExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+					paths = append(paths, filepath.Join(pattern, entry.Name()))
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("The directory %s has no deck.", pattern)
+			}
+			continue
+		}
+		if !strings.ContainsAny(pattern, "*?[") {
+			paths = append(paths, pattern)
+			continue
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("The pattern %s is malformed: %v", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("No deck matches %s.", pattern)
+		}
+		sort.Strings(matches)
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+// ParseTopicFromFiles reads the decks at paths like ParseTopicFromFile and
+// merges them in one topic with the policy, the prefix of a deck being its
+// file name without extension.
+func ParseTopicFromFiles(paths []string, p TopicParsingParameters, policy string) (Topic, error) {
+	topic := NewTopic()
+	for _, path := range paths {
+		deck, err := ParseTopicFromFile(path, p)
+		if err != nil {
+			return NewTopic(), err
+		}
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if err := topic.Merge(deck, name, policy); err != nil {
+			return NewTopic(), fmt.Errorf("Merge of %s failed: %v", path, err)
+		}
+	}
+	return topic, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestMerge checks what becomes of a topic found in both topics with each
+// policy.
+func TestMerge(t *testing.T) {
+	parse := func(deck string) Topic {
+		return ParseTopic(strings.NewReader(deck), getTpp())
+	}
+	first := "### Lesson 1\nmanger;to eat\n### Lesson 2\nboire;to drink\n"
+	second := "### Lesson 2\ncourir;to run\n### Lesson 3\nchat;cat\n"
+
+	topic := parse(first)
+	if err := topic.Merge(parse(second), "verbs", "prefix"); err != nil {
+		t.Fatalf("The merge should succeed but we got %v\n", err)
+	}
+	if expected := []string{"1", "2", "verbs/2", "3"}; !reflect.DeepEqual(topic.GetSubsectionsName(), expected) {
+		t.Errorf("The topics should be %v but we got %v\n", expected, topic.GetSubsectionsName())
+	}
+
+	topic = parse(first)
+	if err := topic.Merge(parse(second), "verbs", "merge"); err != nil {
+		t.Fatalf("The merge should succeed but we got %v\n", err)
+	}
+	if qa := topic.GetSubsection("2"); !reflect.DeepEqual(qa.questions, []string{"boire", "courir"}) {
+		t.Errorf("The questions of both decks should be in the topic but we got %v\n", qa.questions)
+	}
+
+	topic = parse(first)
+	if err := topic.Merge(parse(second), "verbs", "error"); err == nil || topic.GetSubsectionsCount() != 2 {
+		t.Errorf("The merge should fail and leave the topic unchanged but we got %v and %v\n", err, topic.GetSubsectionsName())
+	}
+	if err := topic.Merge(parse(second), "verbs", "ignore"); err == nil {
+		t.Errorf("An unknown policy should be refused\n")
+	}
+}
+
+// TestParseTopicFromFiles checks that the decks of a directory or of a
+// pattern are merged in one topic.
+func TestParseTopicFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"verbs.csv": "### Lesson 1\nmanger;to eat\n",
+		"nouns.csv": "### Lesson 1\nchat;cat\n",
+		"notes.doc": "not a deck",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, pattern := range []string{dir, filepath.Join(dir, "*.csv")} {
+		paths, err := expandDeckPaths([]string{pattern})
+		if err != nil || len(paths) != 2 || filepath.Base(paths[0]) != "nouns.csv" {
+			t.Fatalf("The decks of %s should be nouns.csv and verbs.csv but we got %v and %v\n", pattern, paths, err)
+		}
+		topic, err := ParseTopicFromFiles(paths, getTpp(), "")
+		if err != nil || !reflect.DeepEqual(topic.GetSubsectionsName(), []string{"1", "verbs/1"}) {
+			t.Errorf("The topics should be named after their deck but we got %v and %v\n", topic.GetSubsectionsName(), err)
+		}
+	}
+	if _, err := expandDeckPaths([]string{filepath.Join(dir, "*.yaml")}); err == nil {
+		t.Errorf("A pattern matching no deck should be reported\n")
+	}
+}
+
+// TestParseTopicFromFilesMetadata checks that the merged topic keeps the
+// front matter of the first deck that has one.
+func TestParseTopicFromFilesMetadata(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for _, deck := range []struct{ name, content string }{
+		{"nouns.csv", "### Lesson 1\nchat;cat\n"},
+		{"verbs.csv", "---\ntitle: French verbs\nlanguage: fr\n---\n### Lesson 1\nmanger;to eat\n"},
+	} {
+		path := filepath.Join(dir, deck.name)
+		if err := os.WriteFile(path, []byte(deck.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	topic, err := ParseTopicFromFiles(paths, getTpp(), "")
+	if err != nil {
+		t.Fatalf("The decks should be merged but we got %v\n", err)
+	}
+	if m := topic.Metadata(); m.Title != "French verbs" || m.Language != "fr" {
+		t.Errorf("The metadata of the verbs deck should be kept but we got %+v\n", m)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import "strings"
+
+// dedupStrategies tell which entry is kept among the ones sharing a
+// question.
+var dedupStrategies = map[string]func(kept entry, duplicate entry) entry{
+	// The first entry is kept, the others are dropped.
+	"keep-first": func(kept entry, duplicate entry) entry { return kept },
+	// The last entry is kept, at the place of the first one.
+	"keep-last": func(kept entry, duplicate entry) entry { return duplicate },
+	// The answers and the tags of the entries are combined in a single
+	// entry with several answers.
+	"merge": mergeEntries,
+}
+
+// defaultDedupStrategy is the strategy of Dedup when none is given.
+const defaultDedupStrategy = "keep-first"
+
+// GetDedupStrategy returns the strategy used to remove the questions asked
+// twice. Empty means that they are all asked.
+func (p InterrogationParameters) GetDedupStrategy() string {
+	return p.dedup
+}
+
+// Dedup returns a new set where each question appears once. The questions
+// are compared like the answers are graded: case and surrounding spaces are
+// not significant. The strategy tells what becomes of the entries sharing a
+// question: keep-first, keep-last or merge. An empty strategy is
+// keep-first. The entries stay in the order their question first appears.
+func (qa QuestionsAnswers) Dedup(strategy string) QuestionsAnswers {
+	if strategy == "" {
+		strategy = defaultDedupStrategy
+	}
+	combine := dedupStrategies[strategy]
+
+	var entries []entry
+	position := make(map[string]int)
+	for i := 0; i < qa.GetCount(); i++ {
+		key := strings.ToLower(strings.TrimSpace(qa.questions[i]))
+		if n, found := position[key]; found {
+			entries[n] = combine(entries[n], qa.entry(i))
+			continue
+		}
+		position[key] = len(entries)
+		entries = append(entries, qa.entry(i))
+	}
+
+	deduped := NewQA()
+	for _, e := range entries {
+		deduped.addEntry(e)
+	}
+	return deduped
+}
+
+// mergeEntries combines 2 entries sharing a question. The answers of both
+// become the acceptable answers of the entry, each one once, and the tags
+// are gathered. The rest comes from the first entry.
+func mergeEntries(kept entry, duplicate entry) entry {
+	answers := entryAnswers(kept)
+	for _, answer := range entryAnswers(duplicate) {
+		if !isCorrectAlternative(answer, answers) {
+			answers = append(answers, answer)
+		}
+	}
+	if len(answers) > 1 {
+		kept.alternatives = answers
+		kept.answer = strings.Join(answers, defaultAnswerConnector)
+	}
+
+	var tags []string
+	for _, tag := range append(append([]string{}, kept.tags...), duplicate.tags...) {
+		if !containsString(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	kept.tags = tags
+	if kept.media == "" {
+		kept.media = duplicate.media
+	}
+	return kept
+}
+
+// entryAnswers returns the acceptable answers of the entry.
+func entryAnswers(e entry) []string {
+	if len(e.alternatives) != 0 {
+		return append([]string{}, e.alternatives...)
+	}
+	return []string{e.answer}
+}
+
+// containsString tells if the string is one of the list.
+func containsString(list []string, s string) bool {
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
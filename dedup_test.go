@@ -0,0 +1,74 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// getDuplicatesSet returns a set where 'manger' is found twice with
+// different answers.
+func getDuplicatesSet() QuestionsAnswers {
+	qa := NewQA()
+	qa.AddTaggedEntry("manger", "to eat", []string{"verb"})
+	qa.AddEntry("boire", "to drink")
+	qa.AddTaggedEntry(" Manger", "to dine", []string{"verb", "food"})
+	qa.AddEntry("dormir", "to sleep")
+	return qa
+}
+
+// TestDedup checks what each strategy keeps of the questions found twice.
+func TestDedup(t *testing.T) {
+	cases := []struct {
+		strategy string
+		answers  []string
+	}{
+		{"", []string{"to eat", "to drink", "to sleep"}},
+		{"keep-first", []string{"to eat", "to drink", "to sleep"}},
+		{"keep-last", []string{"to dine", "to drink", "to sleep"}},
+		{"merge", []string{"to eat / to dine", "to drink", "to sleep"}},
+	}
+	for _, c := range cases {
+		deduped := getDuplicatesSet().Dedup(c.strategy)
+		if !reflect.DeepEqual(deduped.answers, c.answers) {
+			t.Errorf("With the strategy '%s' the answers should be %v but we got %v\n", c.strategy, c.answers, deduped.answers)
+		}
+		if deduped.GetCount() != 3 || deduped.Validate() != nil {
+			t.Errorf("With the strategy '%s' the set should hold 3 consistent entries but we got %v\n", c.strategy, deduped.questions)
+		}
+	}
+}
+
+// TestDedupMerge checks that the merged entry accepts all the answers and
+// carries all the tags.
+func TestDedupMerge(t *testing.T) {
+	deduped := getDuplicatesSet().Dedup("merge")
+	if !reflect.DeepEqual(deduped.alternatives[0], []string{"to eat", "to dine"}) {
+		t.Errorf("Both answers should be accepted but we got %v\n", deduped.alternatives[0])
+	}
+	if !reflect.DeepEqual(deduped.tags[0], []string{"verb", "food"}) {
+		t.Errorf("The tags should be gathered once each but we got %v\n", deduped.tags[0])
+	}
+	if deduped.alternatives[1] != nil {
+		t.Errorf("A question found once should keep a single answer but we got %v\n", deduped.alternatives[1])
+	}
+
+	// The same answer is not accepted twice.
+	qa := NewQA()
+	qa.AddEntry("manger", "to eat")
+	qa.AddEntry("manger", "To eat ")
+	if merged := qa.Dedup("merge"); merged.answers[0] != "to eat" || merged.alternatives[0] != nil {
+		t.Errorf("The same answer should be kept once but we got '%s' and %v\n", merged.answers[0], merged.alternatives[0])
+	}
+}
+
+// TestParsingDedup checks that the strategy is checked by the parsing.
+func TestParsingDedup(t *testing.T) {
+	p, err := parse(noEnvironment, "-dedup", "merge")
+	if err != nil || p.GetDedupStrategy() != "merge" {
+		t.Errorf("The strategy merge should be accepted but we got '%s' and %v\n", p.GetDedupStrategy(), err)
+	}
+	if _, err := parse(noEnvironment, "-dedup", "keep-all"); err == nil || !strings.Contains(err.Error(), "keep-all") {
+		t.Errorf("An unknown strategy should be refused but we got %v\n", err)
+	}
+}
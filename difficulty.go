@@ -0,0 +1,67 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	minDifficulty     = 1
+	maxDifficulty     = 5
+	defaultDifficulty = 3 // difficulty of the questions that do not set it
+)
+
+// extractDifficulty reads the difficulty in the last of the fields that
+// follow the question. The fields are returned without it. If the last field
+// is not a difficulty, or if it is the only field (the answer), the fields
+// are left untouched and the default difficulty is returned.
+func extractDifficulty(fields []string, defaultValue int) ([]string, int) {
+	if len(fields) < 2 {
+		return fields, defaultValue
+	}
+	value, err := strconv.Atoi(strings.TrimSpace(fields[len(fields)-1]))
+	if err != nil || value < minDifficulty || value > maxDifficulty {
+		return fields, defaultValue
+	}
+	return fields[:len(fields)-1], value
+}
+
+// GetDifficulty returns the difficulty of the entry of index i. The entries
+// read from a deck that tells no difficulty, like the YAML, Markdown, Anki
+// and Quizlet ones, have the default difficulty.
+func (qa QuestionsAnswers) GetDifficulty(i int) int {
+	if qa.difficulty[i] == 0 {
+		return defaultDifficulty
+	}
+	return qa.difficulty[i]
+}
+
+// FilterByDifficulty returns a new set made of the entries whose difficulty
+// is in the range [min, max].
+func (qa QuestionsAnswers) FilterByDifficulty(min int, max int) QuestionsAnswers {
+	filtered := NewQA()
+	for i := 0; i < qa.GetCount(); i++ {
+		if difficulty := qa.GetDifficulty(i); difficulty >= min && difficulty <= max {
+			filtered.appendEntryFrom(qa, i)
+		}
+	}
+	return filtered
+}
+
+// SortByDifficulty returns a new set with the hardest entries first. The
+// entries of the same difficulty keep their order.
+func (qa QuestionsAnswers) SortByDifficulty() QuestionsAnswers {
+	indexes := make([]int, qa.GetCount())
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		return qa.GetDifficulty(indexes[a]) > qa.GetDifficulty(indexes[b])
+	})
+	sorted := NewQA()
+	for _, i := range indexes {
+		sorted.appendEntryFrom(qa, i)
+	}
+	return sorted
+}
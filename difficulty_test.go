@@ -0,0 +1,118 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func getSampleDifficultyCsvAsStream() string {
+	return `
+### Lesson 1
+easy;answer;1
+hard;answer;5
+medium;answer;3
+unknown;answer
+two;fields;more than a difficulty
+`
+}
+
+func getDifficultyTpp() TopicParsingParameters {
+	tpp := getTpp()
+	tpp.DifficultyField = true
+	tpp.DefaultDifficulty = 2
+	return tpp
+}
+
+// TestParseDifficulty checks that the difficulty is read from the last field
+// and removed from the answer.
+func TestParseDifficulty(t *testing.T) {
+	qa := ParseTopic(strings.NewReader(getSampleDifficultyCsvAsStream()), getDifficultyTpp()).BuildQuestionsSet("1")
+
+	expected := []int{1, 5, 3, 2, 2}
+	if !reflect.DeepEqual(qa.difficulty, expected) {
+		t.Errorf("The difficulties should be %v but we got %v\n", expected, qa.difficulty)
+	}
+	for i := 0; i < 4; i++ {
+		if qa.answers[i] != "answer" {
+			t.Errorf("The difficulty should have been removed from the answer. Found '%s'\n", qa.answers[i])
+		}
+	}
+	if qa.answers[4] != "fields;more than a difficulty" {
+		t.Errorf("A last field that is not a difficulty should be kept in the answer. Found '%s'\n", qa.answers[4])
+	}
+}
+
+// TestParseWithoutDifficultyField checks that the last field is part of the
+// answer when the difficulty field is not enabled.
+func TestParseWithoutDifficultyField(t *testing.T) {
+	qa := ParseTopic(strings.NewReader(getSampleDifficultyCsvAsStream()), getTpp()).BuildQuestionsSet("1")
+	if qa.answers[0] != "answer;1" {
+		t.Errorf("The last field should be part of the answer. Found '%s'\n", qa.answers[0])
+	}
+}
+
+// TestFilterByDifficulty checks that only the entries in the range are kept.
+func TestFilterByDifficulty(t *testing.T) {
+	qa := ParseTopic(strings.NewReader(getSampleDifficultyCsvAsStream()), getDifficultyTpp()).BuildQuestionsSet("1")
+
+	filtered := qa.FilterByDifficulty(3, 5)
+	if !reflect.DeepEqual(filtered.questions, []string{"hard", "medium"}) {
+		t.Errorf("Filtering on 3-5 should give [hard medium] but we got %v\n", filtered.questions)
+	}
+	filtered = qa.FilterByDifficulty(1, 2)
+	if !reflect.DeepEqual(filtered.questions, []string{"easy", "unknown", "two"}) {
+		t.Errorf("Filtering on 1-2 should give [easy unknown two] but we got %v\n", filtered.questions)
+	}
+}
+
+// TestSortByDifficulty checks that the hardest entries come first.
+func TestSortByDifficulty(t *testing.T) {
+	qa := ParseTopic(strings.NewReader(getSampleDifficultyCsvAsStream()), getDifficultyTpp()).BuildQuestionsSet("1")
+
+	sorted := qa.SortByDifficulty()
+	expected := []string{"hard", "medium", "unknown", "two", "easy"}
+	if !reflect.DeepEqual(sorted.questions, expected) {
+		t.Errorf("Sorting by difficulty should give %v but we got %v\n", expected, sorted.questions)
+	}
+}
+
+// TestParsingDifficultyOptions checks the options filtering and ordering by
+// difficulty.
+func TestParsingDifficultyOptions(t *testing.T) {
+	p, err := parse(noEnvironment, "-min-difficulty", "2", "-max-difficulty", "4", "-hardest-first")
+	if err != nil {
+		t.Fatalf("Parsing detects the difficulty options as an error: %v", err)
+	}
+	if p.minDiff != 2 || p.maxDiff != 4 || !p.hardFirst || !p.UsesDifficulty() {
+		t.Errorf("The difficulty options were not detected: %+v\n", p)
+	}
+	for _, value := range []string{"0", "6", "hard"} {
+		if _, err := parse(noEnvironment, "-min-difficulty", value); err == nil {
+			t.Errorf("The invalid difficulty '%s' is not detected.", value)
+		}
+	}
+
+	qa := ParseTopic(strings.NewReader(getSampleDifficultyCsvAsStream()), getDifficultyTpp()).BuildQuestionsSet("1")
+	applied := p.ApplyDifficulty(qa)
+	if !reflect.DeepEqual(applied.questions, []string{"medium", "unknown", "two"}) {
+		t.Errorf("The difficulty options should give [medium unknown two] but we got %v\n", applied.questions)
+	}
+}
+
+// TestApplyDifficultyWithoutField checks that the questions of a deck that
+// tells no difficulty have the default one, and are not filtered out.
+func TestApplyDifficultyWithoutField(t *testing.T) {
+	topic, err := ParseTopicYAML(strings.NewReader("Lesson 1:\n  - q: manger\n    a: to eat\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := parse(noEnvironment, "-hardest-first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied := p.ApplyDifficulty(topic.BuildQuestionsSet())
+	if applied.GetCount() != 1 || applied.GetDifficulty(0) != defaultDifficulty {
+		t.Errorf("The question of the YAML deck should be kept with the default difficulty but we got %v\n", applied.questions)
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// envPrefix starts the name of the environment variable giving the default
+// value of each option, like SIMPLE_LEARNING_LOOPS for -loops.
+const envPrefix = "SIMPLE_LEARNING_"
+
+// environment gives what the parameters read outside of the command line:
+// the variables and the directories where the config file is looked for.
+// The tests give their own instead of the one of the process, so that they
+// do not depend on the environment of the developer.
+type environment struct {
+	lookup    func(name string) (string, bool) // reads an environment variable
+	configDir func() (string, error)           // the config directory of the user
+	homeDir   func() (string, error)           // the home directory of the user
+}
+
+// processEnvironment is the environment of the program.
+var processEnvironment = environment{lookup: os.LookupEnv, configDir: os.UserConfigDir, homeDir: os.UserHomeDir}
+
+// envNames are the names, after envPrefix, of the environment variables of
+// the options whose name is too short to tell what they set. The others
+// are named after their option, in upper case with _ for -.
+var envNames = map[string]string{
+	"i":   "INTERACTIVE",
+	"t":   "WAIT",
+	"m":   "MODE",
+	"l":   "TOPICS",
+	"r":   "REVERSED",
+	"s":   "SUMMARY",
+	"sep": "SEPARATOR",
+}
+
+// envOption is an environment variable giving the default value of an
+// option. The options without value are set when the variable holds a true
+// value like 1 or true.
+type envOption struct {
+	name    string
+	flag    string
+	boolean bool
+}
+
+// legacyEnvOptions are the environment variables read before the ones
+// named after envPrefix, which take precedence over them.
+var legacyEnvOptions = []envOption{
+	{"SL_INTERACTIVE", "-i", true},
+	{"SL_WAIT", "-t", false},
+	{"SL_MODE", "-m", false},
+	{"SL_LIMIT", "-loops", false},
+	{"SL_NOCOLOR", "-no-color", true},
+}
+
+// envName returns the name of the environment variable of the option.
+func envName(option string) string {
+	if name, found := envNames[option]; found {
+		return envPrefix + name
+	}
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(option, "-", "_"))
+}
+
+// envOptions returns the environment variables of the options, the legacy
+// ones first. -config is left out: its variable is read by configPath.
+func envOptions() []envOption {
+	options := append([]envOption(nil), legacyEnvOptions...)
+	var p InterrogationParameters
+	var failed error
+	var named []envOption
+	newFlagSet(&p, &failed).VisitAll(func(f *flag.Flag) {
+		if f.Name == "config" {
+			return
+		}
+		boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool })
+		named = append(named, envOption{envName(f.Name), "-" + f.Name, ok && boolFlag.IsBoolFlag()})
+	})
+	sort.Slice(named, func(a, b int) bool {
+		return named[a].name < named[b].name
+	})
+	return append(options, named...)
+}
+
+// applyEnvironment sets on the parameters the options found in the
+// environment through lookup.
+func applyEnvironment(p InterrogationParameters, lookup func(name string) (string, bool)) (InterrogationParameters, error) {
+	for _, option := range envOptions() {
+		value, found := lookup(option.name)
+		if !found || len(value) == 0 {
+			continue
+		}
+		args := []string{option.flag, value}
+		if option.boolean {
+			set, err := strconv.ParseBool(value)
+			if err != nil {
+				return p, fmt.Errorf("The environment variable %s (%s) must be true or false.", option.name, value)
+			}
+			// A variable set to false turns off the switch set by the
+			// config file.
+			args = []string{option.flag + "=" + strconv.FormatBool(set)}
+		}
+		var err error
+		if p, err = parseArgs(p, args...); err != nil {
+			return p, fmt.Errorf("The environment variable %s is invalid: %w", option.name, err)
+		}
+	}
+	return p, nil
+}
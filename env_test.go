@@ -0,0 +1,165 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// noEnvironment has no variable and no directory. The parsing tests read
+// it so that they do not depend on the environment of the developer.
+var noEnvironment = variables(nil)
+
+// variables returns an environment holding only the variables vars,
+// without config nor home directory.
+func variables(vars map[string]string) environment {
+	return directories(vars, "", "")
+}
+
+// directories returns an environment holding the variables vars and the
+// config and home directories. An empty directory is unknown.
+func directories(vars map[string]string, configDir string, homeDir string) environment {
+	dir := func(path string) func() (string, error) {
+		return func() (string, error) {
+			if path == "" {
+				return "", os.ErrNotExist
+			}
+			return path, nil
+		}
+	}
+	return environment{
+		lookup: func(name string) (string, bool) {
+			value, found := vars[name]
+			return value, found
+		},
+		configDir: dir(configDir),
+		homeDir:   dir(homeDir),
+	}
+}
+
+// TestParsingEnvironment checks that the environment gives the default
+// values of the options.
+func TestParsingEnvironment(t *testing.T) {
+	env := variables(map[string]string{
+		"SL_INTERACTIVE": "1",
+		"SL_WAIT":        "1500",
+		"SL_MODE":        "linear",
+		"SL_LIMIT":       "4",
+		"SL_NOCOLOR":     "true",
+	})
+
+	p, err := parse(env)
+	if err != nil {
+		t.Fatalf("A valid environment must not trigger a parsing error: %v", err)
+	}
+	if !p.interactive || p.minWait != 1500*time.Millisecond || p.mode != linear || p.limit != 4 || !p.noColor {
+		t.Errorf("The options of the environment were not applied: %+v\n", p)
+	}
+}
+
+// TestParsingFlagOverridesEnvironment checks that the command line takes
+// precedence over the environment.
+func TestParsingFlagOverridesEnvironment(t *testing.T) {
+	env := variables(map[string]string{"SL_WAIT": "1500", "SL_LIMIT": "4", "SL_INTERACTIVE": "false"})
+
+	p, err := parse(env, "-t", "500")
+	if err != nil {
+		t.Fatalf("A valid environment must not trigger a parsing error: %v", err)
+	}
+	if p.minWait != 500*time.Millisecond {
+		t.Errorf("The wait of the command line should override the environment. Found %v\n", p.minWait)
+	}
+	if p.limit != 4 {
+		t.Errorf("The limit of the environment should be kept. Found %d\n", p.limit)
+	}
+	if p.interactive {
+		t.Errorf("SL_INTERACTIVE set to false must not set the interactive mode.")
+	}
+}
+
+// TestParsingFlagTurnsOffEnvironment checks that the command line turns
+// off or changes the modes set by the environment, and that the
+// environment turns off the ones set by the config file.
+func TestParsingFlagTurnsOffEnvironment(t *testing.T) {
+	cases := []struct {
+		vars        map[string]string
+		args        []string
+		interactive bool
+		noColor     bool
+		mode        interrogationMode
+	}{
+		{map[string]string{"SIMPLE_LEARNING_INTERACTIVE": "1", "SIMPLE_LEARNING_NO_COLOR": "true"}, []string{"-i=false", "-no-color=false"}, false, false, random},
+		{map[string]string{"SIMPLE_LEARNING_INTERACTIVE": "false", "SIMPLE_LEARNING_NO_COLOR": "false"}, []string{"-i", "-no-color"}, true, true, random},
+		{map[string]string{"SIMPLE_LEARNING_MODE": "linear"}, []string{"-m", "random"}, false, false, random},
+		{map[string]string{"SL_MODE": "linear", "SL_INTERACTIVE": "true"}, []string{"-m", "random", "-i=false"}, false, false, random},
+		{map[string]string{"SIMPLE_LEARNING_MODE": "random"}, []string{"-m", "linear"}, false, false, linear},
+		{map[string]string{"SL_INTERACTIVE": "true", "SIMPLE_LEARNING_INTERACTIVE": "false"}, nil, false, false, random},
+	}
+	for _, c := range cases {
+		p, err := parse(variables(c.vars), c.args...)
+		if err != nil {
+			t.Fatalf("A valid environment must not trigger a parsing error: %v", err)
+		}
+		if p.interactive != c.interactive || p.noColor != c.noColor || p.mode != c.mode {
+			t.Errorf("With %v and %v, the interactive mode should be %t, no color %t and the mode %v but we got %+v\n", c.vars, c.args, c.interactive, c.noColor, c.mode, p)
+		}
+	}
+}
+
+// TestParsingInvalidEnvironment checks that the bad values of the
+// environment are reported.
+func TestParsingInvalidEnvironment(t *testing.T) {
+	for name, value := range map[string]string{
+		"SL_WAIT":    "soon",
+		"SL_MODE":    "shuffled",
+		"SL_LIMIT":   "0",
+		"SL_NOCOLOR": "maybe",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := parse(variables(map[string]string{name: value})); err == nil {
+				t.Errorf("The invalid value '%s' of %s is not detected.", value, name)
+			}
+		})
+	}
+}
+
+// TestParsingEnvironmentAllOptions checks that each option is read from
+// the environment variable named after it, over the legacy ones and the
+// config file.
+func TestParsingEnvironmentAllOptions(t *testing.T) {
+	vars := map[string]string{
+		"SL_WAIT":                      "1500",
+		"SIMPLE_LEARNING_WAIT":         "2500",
+		"SIMPLE_LEARNING_SEPARATOR":    "|",
+		"SIMPLE_LEARNING_PER_SECTION":  "3",
+		"SIMPLE_LEARNING_SHOW_SECTION": "1",
+		"SIMPLE_LEARNING_CONFIG":       writeConfig(t, `{"mode": "linear", "separator": ","}`),
+	}
+
+	p, err := parse(variables(vars), "-loops", "2")
+	if err != nil {
+		t.Fatalf("A valid environment must not trigger a parsing error: %v", err)
+	}
+	if p.minWait != 2500*time.Millisecond || p.separator != "|" || p.perSection != 3 || !p.showSection {
+		t.Errorf("The options of the environment were not applied: %+v\n", p)
+	}
+	if p.mode != linear || p.limit != 2 {
+		t.Errorf("The config file and the command line should still be applied: %+v\n", p)
+	}
+
+	vars["SIMPLE_LEARNING_FUZZY"] = "-2"
+	if _, err := parse(variables(vars)); !errors.Is(err, ErrInvalidValue) || !strings.Contains(err.Error(), "SIMPLE_LEARNING_FUZZY") {
+		t.Errorf("The invalid value of SIMPLE_LEARNING_FUZZY should be reported but we got %v\n", err)
+	}
+}
+
+// TestEnvName checks the names of the environment variables.
+func TestEnvName(t *testing.T) {
+	for option, expected := range map[string]string{"t": "SIMPLE_LEARNING_WAIT", "no-color": "SIMPLE_LEARNING_NO_COLOR", "loops": "SIMPLE_LEARNING_LOOPS"} {
+		if name := envName(option); name != expected {
+			t.Errorf("The variable of -%s should be %s but we got %s\n", option, expected, name)
+		}
+	}
+}
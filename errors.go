@@ -0,0 +1,70 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// The kinds of failure of the options. The errors returned by Parse wrap
+// one of them so that a caller can tell them apart with errors.Is.
+var (
+	// ErrUnknownOption is the failure of an option that does not exist.
+	ErrUnknownOption = errors.New("unknown option")
+	// ErrMissingValue is the failure of an option given without its value
+	// at the end of the command line.
+	ErrMissingValue = errors.New("missing value")
+	// ErrInvalidTime is the failure of an option whose value is not a time
+	// in milliseconds.
+	ErrInvalidTime = errors.New("invalid time")
+	// ErrInvalidValue is the failure of an option whose value is not one of
+	// the values it accepts.
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// OptionError is the failure of an option of the command line. Kind is one
+// of ErrUnknownOption, ErrMissingValue, ErrInvalidTime and ErrInvalidValue.
+// Value is the value refused, if any.
+type OptionError struct {
+	Option string
+	Kind   error
+	Value  string
+	msg    string
+}
+
+func (e *OptionError) Error() string {
+	return e.msg
+}
+
+// Unwrap returns the kind of the failure, for errors.Is.
+func (e *OptionError) Unwrap() error {
+	return e.Kind
+}
+
+// optionErrorf returns the failure of the option, the message being
+// formatted like fmt.Sprintf does.
+func optionErrorf(option string, kind error, format string, a ...interface{}) error {
+	return &OptionError{Option: option, Kind: kind, msg: fmt.Sprintf(format, a...)}
+}
+
+// ParseError is the failure of a deck that cannot be parsed. Line is the
+// line of the deck where the problem was found.
+type ParseError struct {
+	Line int
+	msg  string
+}
+
+func (e *ParseError) Error() string {
+	return e.msg
+}
+
+// ParseWarning is a problem of a deck that does not stop its parsing: the
+// line is skipped or read as well as possible. Line is the line of the deck
+// where it is found.
+type ParseWarning struct {
+	Line    int
+	Message string
+}
+
+func (w ParseWarning) String() string {
+	return fmt.Sprintf("Line %d: %s", w.Line, w.Message)
+}
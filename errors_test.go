@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+)
+
+// TestParseErrorKinds checks the kind of failure of each malformed command
+// line.
+func TestParseErrorKinds(t *testing.T) {
+	cases := []struct {
+		args   []string
+		kind   error
+		option string
+	}{
+		{[]string{"-unknown"}, ErrUnknownOption, "-unknown"},
+		{[]string{"-i", "deck.csv"}, ErrUnknownOption, "deck.csv"},
+		{[]string{"-m"}, ErrMissingValue, "-m"},
+		{[]string{"-i", "-loops"}, ErrMissingValue, "-loops"},
+		{[]string{"-t", "soon"}, ErrInvalidTime, "-t"},
+		{[]string{"-t", "3000-1000"}, ErrInvalidTime, "-t"},
+		{[]string{"-auto", "-1"}, ErrInvalidTime, "-auto"},
+		{[]string{"-m", "shuffled"}, ErrInvalidValue, "-m"},
+		{[]string{"-loops", "0"}, ErrInvalidValue, "-loops"},
+		{[]string{"-sep", ""}, ErrInvalidValue, "-sep"},
+	}
+	for _, c := range cases {
+		_, err := parse(noEnvironment, c.args...)
+		if !errors.Is(err, c.kind) {
+			t.Errorf("Parsing %v should fail with '%v' but we got %v\n", c.args, c.kind, err)
+			continue
+		}
+		var optionErr *OptionError
+		if !errors.As(err, &optionErr) || optionErr.Option != c.option {
+			t.Errorf("The failure of %v should tell the option but we got %v\n", c.args, err)
+		}
+	}
+}
+
+// TestParseMissingValues checks that each option taking a value, as told
+// by the options of the command line, fails without it.
+func TestParseMissingValues(t *testing.T) {
+	var p InterrogationParameters
+	var failed error
+	newFlagSet(&p, &failed).VisitAll(func(f *flag.Flag) {
+		if boolFlag, ok := f.Value.(interface{ IsBoolFlag() bool }); ok && boolFlag.IsBoolFlag() {
+			return
+		}
+		_, err := parse(noEnvironment, "-"+f.Name)
+		var optionErr *OptionError
+		if !errors.Is(err, ErrMissingValue) || !errors.As(err, &optionErr) || optionErr.Option != "-"+f.Name {
+			t.Errorf("The option -%s without value should fail with '%v' but we got %v\n", f.Name, ErrMissingValue, err)
+		}
+	})
+}
+
+// TestParseValueStartingWithDash checks that the value of an option is
+// not read as an option, even when it starts with a dash.
+func TestParseValueStartingWithDash(t *testing.T) {
+	p, err := parse(noEnvironment, "-sep", "-", "-i")
+	if err != nil || p.separator != "-" || !p.interactive {
+		t.Errorf("The separator '-' should be accepted but we got '%s' and %v\n", p.separator, err)
+	}
+}
+
+// TestEnvironmentErrorKind checks that the kind of failure is kept when it
+// comes from the environment.
+func TestEnvironmentErrorKind(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		if name == "SL_WAIT" {
+			return "soon", true
+		}
+		return "", false
+	}
+	if _, err := applyEnvironment(NewInterrogationParameters(), lookup); !errors.Is(err, ErrInvalidTime) {
+		t.Errorf("The wait of the environment should fail with '%v' but we got %v\n", ErrInvalidTime, err)
+	}
+}
+
+// TestYAMLParseErrorLine checks that the failure of a YAML deck tells its
+// line.
+func TestYAMLParseErrorLine(t *testing.T) {
+	_, err := ParseTopicYAML(strings.NewReader("Lesson 1:\n  - q: manger\n    a: to eat\nLesson 2:\n  - a: to drink\n"))
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Line != 5 {
+		t.Errorf("The failure should be a ParseError at line 5 but we got %v\n", err)
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"math/rand"
+)
+
+// DrawExam returns the questions of the exam: the number of questions asked
+// with the -exam option are drawn randomly in the set, each one once. If the
+// exam requires more questions than the set contains, the whole set is
+// returned shuffled.
+func (p InterrogationParameters) DrawExam(qa QuestionsAnswers) QuestionsAnswers {
+	return qa.Sample(p.exam, p.rng)
+}
+
+// Sample returns n distinct entries of the set drawn randomly, without
+// replacement. If n exceeds the number of questions, the whole set is
+// returned shuffled. The same seed of rng gives the same sample.
+func (qa QuestionsAnswers) Sample(n int, rng *rand.Rand) QuestionsAnswers {
+	drawn := NewQA()
+	for _, i := range rng.Perm(qa.GetCount()) {
+		if drawn.GetCount() == n {
+			break
+		}
+		drawn.appendEntryFrom(qa, i)
+	}
+	return drawn
+}
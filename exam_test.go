@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParsingExam checks that the exam option forces a graded session
+// without repetition.
+func TestParsingExam(t *testing.T) {
+	p, err := parse(noEnvironment, "-exam", "5", "-m", "random")
+	if err != nil {
+		t.Errorf("Parsing detects the exam option as an error")
+	}
+	if !p.IsExamMode() || p.exam != 5 {
+		t.Errorf("Parsing failed to set the exam to 5 questions. Found %d\n", p.exam)
+	}
+	if p.mode != linear || p.limit != 1 || !p.interactive || !p.graded {
+		t.Errorf("An exam must ask each question once and grade the answers.")
+	}
+	for _, value := range []string{"0", "-2", "many"} {
+		if _, err := parse(noEnvironment, "-exam", value); err == nil {
+			t.Errorf("The invalid number of questions '%s' is not detected.", value)
+		}
+	}
+}
+
+// TestDrawExam checks through Sample that the drawn questions are distinct and that their
+// number is capped by the size of the set.
+func TestDrawExam(t *testing.T) {
+	qa := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp()).BuildQuestionsSet()
+
+	for _, n := range []int{1, 4, 6, 10} {
+		drawn := qa.Sample(n, rand.New(rand.NewSource(1)))
+		expected := n
+		if n > qa.GetCount() {
+			expected = qa.GetCount()
+		}
+		if drawn.GetCount() != expected {
+			t.Errorf("Drawing %d questions should give %d questions but we got %d\n", n, expected, drawn.GetCount())
+		}
+		seen := make(map[string]bool)
+		for i, q := range drawn.questions {
+			if seen[q] {
+				t.Errorf("The question '%s' was drawn twice.", q)
+			}
+			seen[q] = true
+			if drawn.answers[i] != strings.Replace(q, "Question", "Answer", 1) {
+				t.Errorf("The question '%s' lost its answer: '%s'\n", q, drawn.answers[i])
+			}
+		}
+	}
+}
+
+// TestSampleIsReproducible checks that the same seed gives the same sample.
+func TestSampleIsReproducible(t *testing.T) {
+	qa := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp()).BuildQuestionsSet()
+
+	first := qa.Sample(3, rand.New(rand.NewSource(7)))
+	second := qa.Sample(3, rand.New(rand.NewSource(7)))
+	if !reflect.DeepEqual(first.questions, second.questions) {
+		t.Errorf("The same seed gave 2 different samples: %v and %v\n", first.questions, second.questions)
+	}
+	if empty := qa.Sample(0, rand.New(rand.NewSource(7))); empty.GetCount() != 0 {
+		t.Errorf("A sample of 0 questions should be empty but we got %d questions\n", empty.GetCount())
+	}
+}
+
+// TestAskExam checks that each question of the exam is asked once and that
+// the score reflects the answers typed by the user.
+func TestAskExam(t *testing.T) {
+	qa := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp()).BuildQuestionsSet()
+	p, err := parse(noEnvironment, "-exam", "3")
+	if err != nil {
+		t.Fatalf("Parsing the exam option failed: %v", err)
+	}
+	p.rng = rand.New(rand.NewSource(42))
+	exam := p.DrawExam(qa)
+
+	userIn, userOut := io.Pipe()
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.in = userIn
+	ip.mode, ip.limit, ip.graded = p.mode, p.limit, p.graded
+
+	// The first two answers are right, the last one is wrong.
+	go fmt.Fprintf(userOut, "%s\n%s\nwrong\n", exam.answers[0], strings.ToUpper(exam.answers[1]))
+
+	var result SessionResult
+	pr, pw := io.Pipe()
+	ip.out = pw
+	go func() {
+		defer pw.Close()
+		result = AskQuestions(exam, ip)
+	}()
+	output, _ := ioutil.ReadAll(pr)
+
+	for _, q := range exam.questions {
+		if strings.Count(string(output), q+"     --> ") != 1 {
+			t.Errorf("The question '%s' should have been asked exactly once. Output was:\n%s\n", q, output)
+		}
+	}
+	if result.Asked != 3 || result.Graded != 3 || result.Correct != 2 {
+		t.Errorf("We were expecting 2 correct answers out of 3 but got %+v\n", result)
+	}
+	if !strings.Contains(string(output), "Score: 2/3 (66%)") {
+		t.Errorf("The score is not reported. Output was:\n%s\n", output)
+	}
+}
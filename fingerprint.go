@@ -0,0 +1,47 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// Fingerprint returns a SHA-256 hash, in hexadecimal, of the subsections of
+// the topic and of their questions and answers. It does not depend on the
+// order of the subsections so that topics that are Equal share the same
+// fingerprint. It allows to detect that a deck changed.
+func (topic Topic) Fingerprint() string {
+	ids := make([]string, 0, len(topic.list))
+	for id := range topic.list {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	h := sha256.New()
+	for _, id := range ids {
+		qa := topic.list[id]
+		json.NewEncoder(h).Encode([]interface{}{id, qa.GetCount()})
+		qa.writeFingerprint(h)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// fingerprint returns a SHA-256 hash, in hexadecimal, of the questions and
+// answers of the set, in their order.
+func (qa QuestionsAnswers) fingerprint() string {
+	h := sha256.New()
+	qa.writeFingerprint(h)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeFingerprint writes to w what the fingerprints are computed on: the
+// questions and answers of the set, in their order. Encoding each pair in
+// JSON keeps "ab","c" apart from "a","bc".
+func (qa QuestionsAnswers) writeFingerprint(w io.Writer) {
+	encoder := json.NewEncoder(w)
+	for i := range qa.questions {
+		encoder.Encode([]string{qa.questions[i], qa.answers[i]})
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestFingerprint checks that equal topics share their fingerprint whatever
+// the order of their subsections, and that a single change of answer
+// changes it.
+func TestFingerprint(t *testing.T) {
+	topic := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp())
+
+	reordered := NewTopic()
+	ids := topic.GetSubsectionsName()
+	for n := len(ids) - 1; n >= 0; n-- {
+		reordered.SetSubsection(ids[n], topic.GetSubsection(ids[n]))
+	}
+	if !topic.Equal(reordered) {
+		t.Fatalf("The reordered topic should be equal to the original one.")
+	}
+	if topic.Fingerprint() != reordered.Fingerprint() {
+		t.Errorf("Equal topics should share their fingerprint.")
+	}
+	if topic.Fingerprint() != topic.Fingerprint() {
+		t.Errorf("The fingerprint is not stable.")
+	}
+
+	changed := ParseTopic(strings.NewReader(strings.Replace(getSampleCsvAsStream(), "2_Answer 2", "2_Answer two", 1)), getTpp())
+	if topic.Fingerprint() == changed.Fingerprint() {
+		t.Errorf("A changed answer should change the fingerprint.")
+	}
+
+	// Moving a question to the next subsection changes the topic.
+	moved := ParseTopic(strings.NewReader(strings.Replace(getSampleCsvAsStream(), "2_Question 2;2_Answer 2\n\n### Lesson 3", "\n### Lesson 3\n2_Question 2;2_Answer 2", 1)), getTpp())
+	if topic.Equal(moved) || topic.Fingerprint() == moved.Fingerprint() {
+		t.Errorf("A question moved to another subsection should change the fingerprint.")
+	}
+}
@@ -0,0 +1,579 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// newFlagSet returns the options of the command line. Each option is set on
+// p as soon as it is parsed, in the order of the command line, so that an
+// option can change the mode set by a previous one. The failure of a value
+// is kept in failed since the flag package only keeps its message.
+func newFlagSet(p *InterrogationParameters, failed *error) *flag.FlagSet {
+	fs := flag.NewFlagSet("repeatit", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+
+	// fail keeps the failure of an option with the value refused.
+	fail := func(err error, value string) error {
+		var optionErr *OptionError
+		if errors.As(err, &optionErr) {
+			optionErr.Value = value
+			*failed = err
+		}
+		return err
+	}
+	value := func(name, usage string, set func(value string) error) {
+		fs.Func(name, usage, func(value string) error {
+			return fail(set(value), value)
+		})
+	}
+	// The value of a switch is given to set, so that -i=false turns off a
+	// switch set by the config file or the environment.
+	boolean := func(name, usage string, set func(on bool)) {
+		fs.BoolFunc(name, usage, func(value string) error {
+			on, err := strconv.ParseBool(value)
+			if err != nil {
+				return fail(optionErrorf("-"+name, ErrInvalidValue, "The option -%s takes no value, or true or false.", name), value)
+			}
+			set(on)
+			return nil
+		})
+	}
+	count := func(name, usage string, set func(n int), message string) {
+		value(name, usage, func(value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n <= 0 {
+				return optionErrorf("-"+name, ErrInvalidValue, message, value)
+			}
+			set(n)
+			return nil
+		})
+	}
+
+	boolean("i", "stands for interactive. If set, you will have to press Return to get the\n"+
+		"answer. This allows you to be in a learning way or enforcing your knowledge.\n"+
+		"If this flag is not set, you will not have to press the Return key and you\n"+
+		"simply have to wait for a given time. See -t for details about time.\n"+
+		"Type r then Return to display the current question again, b or back then\n"+
+		"Return to display the previous question and its answer, f, flag, m or mark\n"+
+		"then Return to flag the question for a later review, h or hint then Return\n"+
+		"to see the hint of the question or the first letter of the answer, s or skip\n"+
+		"then Return to go to the next question without the answer, q or quit then\n"+
+		"Return to stop the session. When the answers are graded, the commands start\n"+
+		"with a colon, like :s or :quit, so that any answer can be typed.", func(on bool) {
+		p.interactive = on
+	})
+	value("t", "the `time` to wait between 2 questions. Default is 2 seconds. The time you set is\n"+
+		"in milliseconds. A range like 1500-3000 picks a random time within the range\n"+
+		"for each question. Without -i, type + or - then Return to lengthen or shorten\n"+
+		"the wait by half a second during the session.", func(value string) error {
+		minWait, maxWait, err := parseWait(value)
+		if err != nil {
+			return err
+		}
+		p.minWait, p.maxWait = minWait, maxWait
+		return nil
+	})
+	value("m", "the order of the questions, linear (the order of the file) or random (default).\n"+
+		"shuffle asks each question once per loop in a random order, without repeat.\n"+
+		"typed asks them in random order and checks the answers you type, telling\n"+
+		"if each one is correct with the right answer, and gives your score at the end.\n"+
+		"choice lists the right answer with wrong ones of the same subsection: you type\n"+
+		"the number of the right one.", func(value string) error {
+		switch value {
+		case "linear":
+			p.mode = linear
+		case "random":
+			// Set again since the config file or the environment can give
+			// another mode.
+			p.mode = random
+		case "shuffle":
+			p.mode = shuffle
+		case "typed":
+			// The answers are typed and checked, the questions being
+			// asked in random order.
+			p.interactive = true
+			p.graded = true
+		case "choice":
+			// The number of the answer is typed among a list.
+			p.choice = true
+			p.interactive = true
+			p.graded = true
+		default:
+			return optionErrorf("-m", ErrInvalidValue, "The mode you set (%s) must be linear, random, shuffle, typed or choice.", value)
+		}
+		return nil
+	})
+	count("loops", "the `number` of times the questions are asked. Default is 1.", func(n int) {
+		p.limit = n
+	}, "The number of loops you set (%s) is not a strictly positive integer.")
+	boolean("no-color", "do not color the output.", func(on bool) {
+		p.noColor = on
+	})
+	value("theme", "the `name` of the colors of the output: default, dark, light or mono.", func(value string) error {
+		if _, found := colorThemes[value]; !found {
+			return optionErrorf("-theme", ErrInvalidValue, "The theme you set (%s) is unknown. Choose default, dark, light or mono.", value)
+		}
+		p.theme = value
+		return nil
+	})
+	value("data-dir", "the `directory` of the files kept across sessions, like the ones of -stats,\n"+
+		"-schedule or -leitner, when their path is relative.", func(value string) error {
+		p.dataDir = expandHome(value)
+		return nil
+	})
+	boolean("show-section", "write the name of the topic as a header when it changes between two\n"+
+		"questions.", func(on bool) {
+		p.showSection = on
+	})
+	value("export", "write the deck in another `format` instead of asking the questions: html\n"+
+		"for a page of flashcards showing their answer when clicked, or anki for a text\n"+
+		"file Anki imports, with the name of the topic among the tags of each note.", func(value string) error {
+		if value != "html" && value != "anki" {
+			return optionErrorf("-export", ErrInvalidValue, "The export format you set (%s) is unknown. Choose html or anki.", value)
+		}
+		p.export = value
+		return nil
+	})
+	boolean("compact", "write each question once with its answer on a single line, like\n"+
+		"'question | answer', instead of asking them. The subsections chosen and the\n"+
+		"reverse mode are used. Handy to review or grep a deck.", func(on bool) {
+		p.compact = on
+	})
+	boolean("loop-footer", "write 'End of loop x/y' after the last question of each loop instead\n"+
+		"of the 'Loop (x/y)' banner before the first one.", func(on bool) {
+		p.loopFooter = on
+	})
+	boolean("time-loops", "write the time taken by each loop at its end.", func(on bool) {
+		p.timeLoops = on
+	})
+	boolean("s", "ask to show the different topics of the file, no more. Execution stops after this.\n"+
+		"Sections are supposed to start with ###.", func(on bool) {
+		if on {
+			p.mode = summary
+		}
+	})
+	value("l", "ask to be questionned only on the `topics` that are listed here. The topics must be\n"+
+		"separated with a comma. A topic followed by :r is asked in reverse, e.g.\n"+
+		"\"Lesson 1,Lesson 2:r\".", func(value string) error {
+		p.subsections = value
+		return nil
+	})
+	boolean("r", "reverts the questioning. This is like a Jeopardy in fact. The right column becomes\n"+
+		"the questions while the left column becomes the answer.", func(on bool) {
+		p.reversed = on
+	})
+	value("front", "the `column` used as the prompt, q for the questions (default) or a for the\n"+
+		"answers. Useful for decks written as answer;question. Combined with -r, the\n"+
+		"columns are swapped again, so -front a -r prompts with the questions.", func(value string) error {
+		switch value {
+		case "q":
+			p.answerFirst = false
+		case "a":
+			p.answerFirst = true
+		default:
+			return optionErrorf("-front", ErrInvalidValue, "The front column (%s) must be either q or a.", value)
+		}
+		return nil
+	})
+	value("auto", "in interactive mode, the `time` in milliseconds after which the answer is\n"+
+		"revealed if you did not press Return.", func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return optionErrorf("-auto", ErrInvalidTime, "The auto advance time you set (%s) is not a positive integer. Please set the time in milliseconds.", value)
+		}
+		p.autoAdvance = time.Duration(n) * time.Millisecond
+		return nil
+	})
+	count("exam", "draw randomly the given `number` of questions and ask each of them once. You\n"+
+		"have to type the answers and your score is displayed at the end.", func(n int) {
+		p.exam = n
+	}, "The number of questions of the exam (%s) is not a strictly positive integer.")
+	boolean("verbose", "before starting, print to the error output the file loaded, the separator\n"+
+		"used, the number of topics and questions found and if all the topics are taken.", func(on bool) {
+		p.verbose = on
+	})
+	value("save-wrong", "when your answers are checked (see -exam), save the questions you\n"+
+		"missed to this `file`. It can be used as a deck later.", func(value string) error {
+		p.saveWrong = value
+		return nil
+	})
+	value("save-flagged", "save the questions you flagged during the session to this `file`.", func(value string) error {
+		p.saveFlagged = value
+		return nil
+	})
+	value("append-missing", "when your answers are checked, keep in this `file` the questions you\n"+
+		"missed across the sessions. A question leaves the file once answered correctly\n"+
+		"3 times in a row.", func(value string) error {
+		p.leeches = value
+		return nil
+	})
+	value("fresh", "record in this `file` the questions seen in each session, and do not ask the\n"+
+		"ones seen in the last session. If all of them were, they are all asked.", func(value string) error {
+		p.fresh = value
+		return nil
+	})
+	count("fresh-sessions", "with -fresh, the `number` of last sessions whose questions are not\n"+
+		"asked. Default is 1.", func(n int) {
+		p.freshCount = n
+	}, "The number of sessions you set (%s) is not a strictly positive integer.")
+	value("batch", "run the decks listed in the `manifest` one after the other and report the\n"+
+		"total. Each line of the manifest is the path to a deck, relative to the\n"+
+		"manifest, optionally followed by ; and the list of topics, e.g. deck.csv;1,2", func(value string) error {
+		p.batch = value
+		return nil
+	})
+	difficulty := func(name string, set func(n int)) func(value string) error {
+		return func(value string) error {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < minDifficulty || n > maxDifficulty {
+				return optionErrorf("-"+name, ErrInvalidValue, "The difficulty you set (%s) is not an integer from %d to %d.", value, minDifficulty, maxDifficulty)
+			}
+			set(n)
+			return nil
+		}
+	}
+	value("min-difficulty", "ask only the questions whose `difficulty` is at least this one. The difficulty\n"+
+		"is an optional last field from 1 to 5, e.g. manger;to eat;2\n"+
+		"Questions without difficulty are considered of difficulty 3.", difficulty("min-difficulty", func(n int) {
+		p.minDiff = n
+	}))
+	value("max-difficulty", "ask only the questions whose `difficulty` is at most this one. See\n"+
+		"-min-difficulty.", difficulty("max-difficulty", func(n int) {
+		p.maxDiff = n
+	}))
+	boolean("hardest-first", "ask the hardest questions first.", func(on bool) {
+		p.hardFirst = on
+		if on {
+			p.mode = linear
+		}
+	})
+	boolean("interleave", "ask the first question of each topic, then the second one of each topic\n"+
+		"and so on, instead of all the questions of a topic before the next one.", func(on bool) {
+		p.interleave = on
+		if on {
+			p.mode = linear
+		}
+	})
+	boolean("mix", "for each question, pick randomly if the question or the answer is displayed\n"+
+		"first. This mixes the normal and the reversed modes.", func(on bool) {
+		p.mix = on
+	})
+	boolean("rotate-answers", "for the questions that have several answers, display one of them\n"+
+		"picked randomly each time instead of all of them.", func(on bool) {
+		p.rotate = on
+	})
+	boolean("multi-answer", "each field after the question is a distinct answer, for instance\n"+
+		"manger;to eat;to dine. Any of them is accepted when your answers are checked.", func(on bool) {
+		p.multiAnswer = on
+	})
+	value("alt-sep", "the `separator` of the acceptable answers within the answer, for instance\n"+
+		"manger;to eat|to dine. Any of them is accepted when your answers are checked.\n"+
+		"Default is '|'. Empty means that the answer is a single one.", func(value string) error {
+		p.alternativeSep = value
+		return nil
+	})
+	value("connector", "how the answers of a question are joined for the display. Default is\n"+
+		"' / '.", func(value string) error {
+		p.connector = value
+		return nil
+	})
+	boolean("normalize", "replace in the deck the curly quotes, the non-breaking spaces and the\n"+
+		"dashes of the word processors by the characters typed on a keyboard, and\n"+
+		"collapse the runs of spaces. Useful when your answers are checked.", func(on bool) {
+		p.normalize = on
+	})
+	boolean("legacy-split", "split the lines of the deck on the separator, like before the quotes were\n"+
+		"read. Otherwise, when the separator is a single character, a field between double\n"+
+		"quotes can hold the separator, line breaks and doubled quotes, as in RFC 4180\n"+
+		"CSV, like in: \"manger; dîner\";to eat", func(on bool) {
+		p.legacySplit = on
+	})
+	boolean("skip-header", "the first line of the file holds the headers of the columns, like\n"+
+		"Question;Answer in the exports of spreadsheets. It is not asked.", func(on bool) {
+		p.skipHeader = on
+	})
+	boolean("questions-only", "the lines of the file without separator are questions whose answer\n"+
+		"is empty, for a list of prompts to say out loud. The answers can be written later.", func(on bool) {
+		p.questionsOnly = on
+	})
+	boolean("hint-note", "the third field of the lines of the deck is the hint of the question, shown\n"+
+		"when you type h, and the fourth one a note shown after the answer, like in:\n"+
+		"manger;to eat;starts with t;irregular in the past", func(on bool) {
+		p.hintNote = on
+	})
+	boolean("cram", "ask first and more often the questions of the newest topics, the last ones of\n"+
+		"the file.", func(on bool) {
+		p.cram = on
+		if on {
+			p.mode = linear
+		}
+	})
+	value("dedup", "ask once the questions found several times, for instance in 2 subsections.\n"+
+		"The `strategy` tells which answer is kept: keep-first, keep-last or merge, the\n"+
+		"last one accepting all the answers found.", func(value string) error {
+		if _, found := dedupStrategies[value]; !found {
+			return optionErrorf("-dedup", ErrInvalidValue, "The dedup strategy you set (%s) is unknown. Choose keep-first, keep-last or merge.", value)
+		}
+		p.dedup = value
+		return nil
+	})
+	value("collisions", "how a topic found in several of the decks given is read: prefix (default)\n"+
+		"names the ones after the first after their file, like verbs/Lesson 1, merge\n"+
+		"asks their questions as one topic and error stops.", func(value string) error {
+		if !mergePolicies[value] {
+			return optionErrorf("-collisions", ErrInvalidValue, "The collision policy you set (%s) is unknown. Choose prefix, merge or error.", value)
+		}
+		p.mergePolicy = value
+		return nil
+	})
+	value("cram-curve", "how much more often the newest topics are asked in cram mode: flat,\n"+
+		"linear (default) or square.", func(value string) error {
+		if _, found := cramCurves[value]; !found {
+			return optionErrorf("-cram-curve", ErrInvalidValue, "The cram curve you set (%s) is unknown. Choose flat, linear or square.", value)
+		}
+		p.cramCurve = value
+		return nil
+	})
+	boolean("progress-stderr", "print the current loop and question to the error output, so that\n"+
+		"the progress can be followed when the output is redirected to a file.", func(on bool) {
+		p.progress = nil
+		if on {
+			p.progress = os.Stderr
+		}
+	})
+	value("schedule", "the `file` where the schedule of the questions is kept. Only the questions\n"+
+		"due are asked, and your answers are checked: a question is asked again\n"+
+		"after a longer and longer time as you find it, with the SM-2 algorithm.", func(value string) error {
+		// Only the questions due are asked, and their answers are
+		// checked to schedule them again.
+		p.schedule = value
+		p.interactive = true
+		p.graded = true
+		return nil
+	})
+	value("mastery", "the `file` counting the correct answers in a row of each question. A\n"+
+		"question whose answer ends with @requires:tag, after its tags, is asked only\n"+
+		"once the questions carrying #tag were answered correctly 3 times in a row.", func(value string) error {
+		p.mastery = value
+		return nil
+	})
+	value("stats", "the `file` where the attempts, the right and wrong answers and the last time\n"+
+		"each question was seen are kept across sessions, for instance\n"+
+		"~/.simple-learning/stats.db. The questions are known by a hash of their text.", func(value string) error {
+		p.stats = value
+		return nil
+	})
+	boolean("weakest-first", "with -stats, ask first the questions the most often missed in the previous\n"+
+		"sessions. Implies -m linear.", func(on bool) {
+		p.weakFirst = on
+		if on {
+			p.mode = linear
+		}
+	})
+	value("compare", "the `file` where the last session of each deck is kept. At the end of the\n"+
+		"session, your score and the time you took to answer are compared to the ones\n"+
+		"of the last session on the same deck.", func(value string) error {
+		p.compare = value
+		return nil
+	})
+	value("pin", "the questions containing this `text`, whatever the case, are asked first in\n"+
+		"each loop, before the others in the order of the mode.", func(value string) error {
+		p.pin = value
+		return nil
+	})
+	boolean("coverage", "write how many loops are needed, on average, to see each question at\n"+
+		"least once in random mode, instead of asking them.", func(on bool) {
+		p.coverage = on
+	})
+	count("review", "after the questions of each subsection, ask again this `number` of questions\n"+
+		"drawn randomly among the ones of the previous subsections. Implies -m linear.", func(n int) {
+		p.review = n
+		p.mode = linear
+	}, "The number of questions to review you set (%s) is not a strictly positive integer.")
+	count("per-section", "the maximum `number` of questions of a subsection asked in a loop, so\n"+
+		"that a loop goes through all the subsections chosen. A loop is then shorter.", func(n int) {
+		p.perSection = n
+	}, "The number of questions per subsection you set (%s) is not a strictly positive integer.")
+	value("leitner", "the `file` where the Leitner box of each question is kept. The questions you\n"+
+		"know go to the next of the 5 boxes and the others back to the first one. The\n"+
+		"box n is asked every 2^(n-1) days. Implies -self-grade.", func(value string) error {
+		// The user grades the answers to move the questions between the
+		// boxes.
+		p.leitner = value
+		p.selfGrade = true
+		p.interactive = true
+		return nil
+	})
+	boolean("self-grade", "once the answer is revealed, type y or n, or a quality from 1 to 5,\n"+
+		"to tell if you knew it. The questions you did not know, graded n or under\n"+
+		"3, are asked again at the end of the session. Implies -i.", func(on bool) {
+		// The user tells if the answer was known once it is revealed.
+		p.selfGrade = on
+		if on {
+			p.interactive = true
+		}
+	})
+	count("distractors", "in the choice mode, the `number` of wrong answers listed. Default is 3.", func(n int) {
+		p.distractors = n
+	}, "The number of wrong answers you set (%s) is not a strictly positive integer.")
+	boolean("fix-choice-order", "in the choice mode, list the right answer first, before the wrong ones. This\n"+
+		"is meant to compare the transcripts of sessions.", func(on bool) {
+		p.fixChoices = on
+	})
+	value("seed", "the `seed` of the random order of the questions. Two sessions with the same\n"+
+		"seed on the same deck ask the same questions in the same order. Default is a\n"+
+		"new seed for each session.", func(value string) error {
+		seed, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return optionErrorf("-seed", ErrInvalidValue, "The seed you set (%s) is not an integer.", value)
+		}
+		p.setSeed(seed)
+		return nil
+	})
+	value("fuzzy", "when your answers are checked, the `number` of characters you can miss,\n"+
+		"add or mistype in an answer that is still counted as correct.", func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return optionErrorf("-fuzzy", ErrInvalidValue, "The number of typing mistakes you set (%s) is not a positive integer.", value)
+		}
+		p.fuzzy = n
+		return nil
+	})
+	value("width", "wrap the questions and the answers to this `number` of columns. Default is\n"+
+		"not to wrap.", func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			return optionErrorf("-width", ErrInvalidValue, "The width you set (%s) is not a positive integer.", value)
+		}
+		p.width = n
+		return nil
+	})
+	value("announce", "the `prefix` of the lines announcing a topic. Default is '### '.", func(value string) error {
+		p.announce = value
+		return nil
+	})
+	value("prefix", "the `prefix` of the lines announcing a topic, like -announce.", func(value string) error {
+		p.announce = value
+		return nil
+	})
+	value("sep", "the `separator` between the question and the answer. Default is the one\n"+
+		"of ';', ',' and the tab found in the deck, or ';'.", func(value string) error {
+		if len(value) == 0 {
+			return optionErrorf("-sep", ErrInvalidValue, "The separator between the questions and the answers cannot be empty.")
+		}
+		p.separator = value
+		return nil
+	})
+	value("tag", "ask only the questions carrying one of these `tags`, separated by commas, from\n"+
+		"all the topics selected. Tags are the words starting with # at the end of the\n"+
+		"answer, for instance: manger;to eat #verb. Like in: -tag grammar,verbs", func(value string) error {
+		p.tag = value
+		return nil
+	})
+	value("resume", "when you stop the session with q, save its position to this `file`. The next\n"+
+		"session with the same file and the same deck starts from there.", func(value string) error {
+		p.resume = value
+		return nil
+	})
+	value("config", "the config `file` giving the default values of some options. Default is\n"+
+		"~/.config/simple-learning/config.yaml, or else ~/.simplelearningrc, if it exists.\n"+
+		"It is written in YAML, or in JSON if its extension is not .yaml or .yml. The\n"+
+		"keys are wait, mode, separator, announce, color, theme and data_dir, for instance:\n"+
+		"{\"wait\": \"1500-3000\", \"mode\": \"linear\", \"separator\": \";\", \"color\": false, \"theme\": \"dark\"}", func(value string) error {
+		// Already read by Parse before the other options.
+		return nil
+	})
+	boolean("partial-credit", "each field after the question is a required part of the answer, like\n"+
+		"with -multi-answer. You type all the parts separated by commas, in any order,\n"+
+		"and an answer with only some of them scores the fraction found.", func(on bool) {
+		// The answers of a question are its required parts.
+		p.partialCredit = on
+		if on {
+			p.multiAnswer = true
+			p.interactive = true
+			p.graded = true
+		}
+	})
+	boolean("echo", "typing practice. You type the answers, then the letters you got wrong are\n"+
+		"shown between brackets before the answer is checked.", func(on bool) {
+		// Typing practice: the answers are typed and checked.
+		p.echo = on
+		if on {
+			p.interactive = true
+			p.graded = true
+		}
+	})
+	return fs
+}
+
+// flagError returns the failure reported by the flag package as an
+// OptionError. The flag package tells the option only in its message.
+func flagError(err error) error {
+	msg := err.Error()
+	if name, found := strings.CutPrefix(msg, "flag provided but not defined: "); found {
+		return optionErrorf(name, ErrUnknownOption, "The option %s is unknown.", name)
+	}
+	if name, found := strings.CutPrefix(msg, "flag needs an argument: "); found {
+		return optionErrorf(name, ErrMissingValue, "The option %s needs a value.", name)
+	}
+	if arg, found := strings.CutPrefix(msg, "bad flag syntax: "); found {
+		return optionErrorf(arg, ErrUnknownOption, "The option %s is malformed.", arg)
+	}
+	return err
+}
+
+// writeUsage writes how to call the program, with the options of the
+// command line listed by the flag package.
+func writeUsage(w io.Writer, program string) {
+	fmt.Fprintf(w, `Syntax:
+	%[1]s [learn] <csvFile>... [-i]
+	%[1]s review <csvFile>... [-i]
+	%[1]s -batch <manifest> [-i]
+	%[1]s list <csvFile>
+	%[1]s stats <csvFile> -stats <file>
+	%[1]s validate <csvFile>
+	%[1]s convert <csvFile> [-from anki|quizlet] [-o <file>] [-to csv|yaml|html|anki]
+	%[1]s serve <csvFile> [-addr host:port]
+	%[1]s fmt <csvFile> [-w] [-sort]
+Several decks, directories of decks or patterns like 'decks/*.csv' are merged in one
+deck, see -collisions. A csv deck includes the topics of another deck with a line like
+'### include verbs.csv', the announce of -announce then include, the path being relative
+to the deck.
+The review subcommand asks only the questions due today in the Leitner boxes of the deck,
+kept in <csvFile>.leitner unless -leitner is set. Implies -self-grade.
+The list subcommand writes the topics of the deck, like -s. The stats one writes the
+statistics kept with -stats on its questions, the most often missed first. The validate
+one writes the lines of the deck that cannot be read, the empty questions and answers, the
+questions found twice or with conflicting answers, the lines ending with spaces and the
+ones that are not UTF-8, and fails if there is any. The convert one writes the deck in the format of -to, or of the
+extension of -o, and reads the exports of Anki and Quizlet with -from. The serve one serves
+its flashcards, like -export html, on -addr (default %[2]s). The fmt one writes the deck in
+the canonical format, trimmed, with its topics sorted by name with -sort, to the file of
+the deck with -w. These subcommands only take
+the options telling how the deck is read:
+-sep, -announce, -prefix, -connector, -alt-sep, -multi-answer, -normalize, -skip-header,
+-questions-only, -hint-note, -legacy-split and -config.
+where:
+`, program, defaultAddress)
+	var p InterrogationParameters
+	var failed error
+	fs := newFlagSet(&p, &failed)
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+	fmt.Fprint(w, `
+Each option can be set in the environment, in a variable named after it in upper case with
+_ for -, like SIMPLE_LEARNING_LOOPS for -loops. -i, -t, -m, -l, -r, -s and -sep are set by
+SIMPLE_LEARNING_INTERACTIVE, _WAIT, _MODE, _TOPICS, _REVERSED, _SUMMARY and _SEPARATOR. The
+options without value are set by true or 1. The environment overrides the config file, and
+the command line takes precedence over both. SL_INTERACTIVE, SL_WAIT, SL_MODE, SL_LIMIT and
+SL_NOCOLOR are still read, below the other variables.
+`)
+}
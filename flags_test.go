@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"strings"
+	"testing"
+)
+
+// TestParseFlagSyntax checks the other ways of writing the options that
+// the flag package reads.
+func TestParseFlagSyntax(t *testing.T) {
+	p, err := parse(noEnvironment, "--loops", "3", "-m=linear", "-i=true", "-r=false")
+	if err != nil {
+		t.Fatalf("The options should be read but we got %v\n", err)
+	}
+	if p.limit != 3 || p.mode != linear || !p.interactive || p.reversed {
+		t.Errorf("The options are not the ones set: %+v\n", p)
+	}
+	if _, err := parse(noEnvironment, "-h"); !errors.Is(err, flag.ErrHelp) {
+		t.Errorf("-h should ask for the usage but we got %v\n", err)
+	}
+}
+
+// TestOptionErrorValue checks that the failure of an option tells the
+// value refused.
+func TestOptionErrorValue(t *testing.T) {
+	cases := []struct {
+		args   []string
+		option string
+		value  string
+	}{
+		{[]string{"-loops", "many"}, "-loops", "many"},
+		{[]string{"-i", "-m=shuffled"}, "-m", "shuffled"},
+		{[]string{"-i=maybe"}, "-i", "maybe"},
+		{[]string{"-min-difficulty", "9"}, "-min-difficulty", "9"},
+	}
+	for _, c := range cases {
+		_, err := parse(noEnvironment, c.args...)
+		var optionErr *OptionError
+		if !errors.As(err, &optionErr) || optionErr.Option != c.option || optionErr.Value != c.value {
+			t.Errorf("Parsing %v should refuse the value '%s' of %s but we got %v\n", c.args, c.value, c.option, err)
+		}
+	}
+}
+
+// TestWriteUsage checks that the usage lists the options with their
+// description.
+func TestWriteUsage(t *testing.T) {
+	var usage strings.Builder
+	writeUsage(&usage, "repeatit")
+	for _, expected := range []string{"repeatit review <csvFile>", "-loops number", "-seed seed", "-no-color", "SL_INTERACTIVE"} {
+		if !strings.Contains(usage.String(), expected) {
+			t.Errorf("The usage should contain '%s'. Usage was:\n%s\n", expected, usage.String())
+		}
+	}
+}
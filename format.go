@@ -0,0 +1,46 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// FormatTopic returns the topic as the fmt subcommand writes it: the
+// spaces around the questions, the answers, the hints and the notes are
+// trimmed, and the questions found before any subsection come first. The
+// other subsections are sorted by name if sorted is set, or keep their
+// order.
+func FormatTopic(topic Topic, sorted bool) Topic {
+	ids := topic.GetSubsectionsName()
+	if sorted {
+		sort.Strings(ids)
+	}
+	// The questions before any subsection are written without header: they
+	// would join the subsection before them.
+	sort.SliceStable(ids, func(a, b int) bool {
+		return ids[a] == "" && ids[b] != ""
+	})
+	formatted := NewTopic()
+	formatted.metadata = topic.metadata
+	for _, id := range ids {
+		qa := topic.list[id]
+		// Two ids trimmed to the same one are a single subsection.
+		trimmed := formatted.GetSubsection(strings.TrimSpace(id))
+		for i := 0; i < qa.GetCount(); i++ {
+			e := qa.entry(i)
+			e.question = strings.TrimSpace(e.question)
+			e.answer = strings.TrimSpace(e.answer)
+			e.hint = strings.TrimSpace(e.hint)
+			e.note = strings.TrimSpace(e.note)
+			e.cloze = strings.TrimSpace(e.cloze)
+			// The alternatives are shared with the topic given.
+			e.alternatives = append([]string(nil), e.alternatives...)
+			for n := range e.alternatives {
+				e.alternatives[n] = strings.TrimSpace(e.alternatives[n])
+			}
+			trimmed.addEntry(e)
+		}
+		formatted.SetSubsection(strings.TrimSpace(id), trimmed)
+	}
+	return formatted
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestFormatTopic checks that a deck is written trimmed, with a single
+// blank line between its topics, and sorted only when asked.
+func TestFormatTopic(t *testing.T) {
+	deck := "avant ; before\n\n\n### Lesson 2\n  boire ;  to drink  \n\n### Lesson 1\nmanger;to eat\n"
+	tpp := NewInterrogationParameters().GetTopicParsingParameters()
+	topic := ParseTopic(strings.NewReader(deck), tpp)
+	cases := []struct {
+		sorted   bool
+		expected string
+	}{
+		{false, "avant;before\n\n### Lesson 2\nboire;to drink\n\n### Lesson 1\nmanger;to eat\n"},
+		{true, "avant;before\n\n### Lesson 1\nmanger;to eat\n\n### Lesson 2\nboire;to drink\n"},
+	}
+	for _, c := range cases {
+		var out strings.Builder
+		if err := WriteTopic(&out, FormatTopic(topic, c.sorted), tpp); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != c.expected {
+			t.Errorf("The deck formatted with sorted %t should be:\n%s\nbut we got:\n%s\n", c.sorted, c.expected, out.String())
+		}
+	}
+}
+
+// TestFormatTopicSameSubsection checks that the subsections whose names
+// differ only by spaces are written as a single one, with the questions of
+// both.
+func TestFormatTopicSameSubsection(t *testing.T) {
+	deck := "### Lesson 1\nmanger;to eat\n\n### Lesson 1 \nboire;to drink\n"
+	tpp := NewInterrogationParameters().GetTopicParsingParameters()
+	var out strings.Builder
+	if err := WriteTopic(&out, FormatTopic(ParseTopic(strings.NewReader(deck), tpp), false), tpp); err != nil {
+		t.Fatal(err)
+	}
+	if expected := "### Lesson 1\nmanger;to eat\nboire;to drink\n"; out.String() != expected {
+		t.Errorf("The deck formatted should be:\n%s\nbut we got:\n%s\n", expected, out.String())
+	}
+}
+
+// TestFormatTopicKeepsTopic checks that the topic formatted is left as it
+// was given.
+func TestFormatTopicKeepsTopic(t *testing.T) {
+	qa := NewQA()
+	qa.addEntry(entry{question: "manger", answer: "to eat / to dine", alternatives: []string{" to eat ", " to dine"}})
+	topic := NewTopic()
+	topic.SetSubsection("Lesson 1", qa)
+
+	formatted := FormatTopic(topic, false)
+	if alternatives := formatted.GetSubsection("Lesson 1").alternatives[0]; alternatives[0] != "to eat" || alternatives[1] != "to dine" {
+		t.Errorf("The alternatives formatted should be trimmed but we got %q\n", alternatives)
+	}
+	if alternatives := topic.GetSubsection("Lesson 1").alternatives[0]; alternatives[0] != " to eat " || alternatives[1] != " to dine" {
+		t.Errorf("The alternatives of the topic given should not be trimmed but we got %q\n", alternatives)
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// SeenCards records, across the sessions, the last session where each
+// question was shown, keyed by the text of the question.
+type SeenCards struct {
+	Session int            `json:"session"` // number of the last session
+	Cards   map[string]int `json:"cards"`   // number of the last session where the question was shown
+}
+
+// GetFreshPath returns the path of the file recording when the questions
+// were last seen. It is empty if not requested.
+func (p InterrogationParameters) GetFreshPath() string {
+	return p.fresh
+}
+
+// GetFreshSessions returns the number of last sessions whose questions are
+// not asked.
+func (p InterrogationParameters) GetFreshSessions() int {
+	return p.freshCount
+}
+
+// ReadSeenCards reads the questions seen saved in JSON by Write.
+func ReadSeenCards(r io.Reader) (SeenCards, error) {
+	var seen SeenCards
+	if err := json.NewDecoder(r).Decode(&seen); err != nil {
+		return seen, err
+	}
+	if seen.Cards == nil {
+		seen.Cards = make(map[string]int)
+	}
+	return seen, nil
+}
+
+// Write saves the questions seen in JSON.
+func (s SeenCards) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// FilterFresh returns the entries of the set that were not seen in the last
+// n sessions. If all of them were, the whole set is returned along with
+// false so that the session can still take place.
+func (s SeenCards) FilterFresh(qa QuestionsAnswers, n int) (QuestionsAnswers, bool) {
+	fresh := NewQA()
+	for i := 0; i < qa.GetCount(); i++ {
+		last, found := s.Cards[qa.questions[i]]
+		if !found || s.Session-last >= n {
+			fresh.appendEntryFrom(qa, i)
+		}
+	}
+	if fresh.GetCount() == 0 {
+		return qa, false
+	}
+	return fresh, true
+}
+
+// Update records a new session where the questions of the result were
+// shown.
+func (s *SeenCards) Update(qa QuestionsAnswers, r SessionResult) {
+	if s.Cards == nil {
+		s.Cards = make(map[string]int)
+	}
+	s.Session++
+	for _, i := range r.Seen {
+		s.Cards[qa.questions[i]] = s.Session
+	}
+}
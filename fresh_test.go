@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// getFreshSet returns the questions set used by the tests of the fresh
+// option.
+func getFreshSet() QuestionsAnswers {
+	qa := NewQA()
+	qa.AddEntry("manger", "to eat")
+	qa.AddEntry("boire", "to drink")
+	qa.AddEntry("dormir", "to sleep")
+	return qa
+}
+
+// TestFilterFresh checks that the questions seen in the last sessions are
+// left out.
+func TestFilterFresh(t *testing.T) {
+	seen := SeenCards{Session: 4, Cards: map[string]int{"manger": 4, "boire": 3}}
+
+	fresh, ok := seen.FilterFresh(getFreshSet(), 1)
+	if !ok || !reflect.DeepEqual(fresh.questions, []string{"boire", "dormir"}) {
+		t.Errorf("Only the question seen in the last session should be left out but we got %v\n", fresh.questions)
+	}
+	fresh, ok = seen.FilterFresh(getFreshSet(), 2)
+	if !ok || !reflect.DeepEqual(fresh.questions, []string{"dormir"}) {
+		t.Errorf("The questions seen in the last 2 sessions should be left out but we got %v\n", fresh.questions)
+	}
+}
+
+// TestFilterFreshFallback checks that all the questions are asked when all
+// of them were seen recently.
+func TestFilterFreshFallback(t *testing.T) {
+	seen := SeenCards{Session: 1, Cards: map[string]int{"manger": 1, "boire": 1, "dormir": 1}}
+
+	fresh, ok := seen.FilterFresh(getFreshSet(), 1)
+	if ok {
+		t.Errorf("The fallback to the whole set is not reported.")
+	}
+	if fresh.GetCount() != 3 {
+		t.Errorf("The whole set should be asked but we got %d questions\n", fresh.GetCount())
+	}
+}
+
+// TestUpdateSeenCards checks that the questions shown are recorded with the
+// number of the new session, and that the file can be read back.
+func TestUpdateSeenCards(t *testing.T) {
+	seen := SeenCards{Session: 2, Cards: map[string]int{"manger": 1}}
+	seen.Update(getFreshSet(), SessionResult{Seen: []int{1}})
+
+	expected := SeenCards{Session: 3, Cards: map[string]int{"manger": 1, "boire": 3}}
+	if !reflect.DeepEqual(seen, expected) {
+		t.Errorf("The questions seen should be %v but we got %v\n", expected, seen)
+	}
+
+	var buf bytes.Buffer
+	if err := seen.Write(&buf); err != nil {
+		t.Fatalf("Writing the questions seen failed: %v", err)
+	}
+	read, err := ReadSeenCards(&buf)
+	if err != nil || !reflect.DeepEqual(read, seen) {
+		t.Errorf("The questions seen read back %v differ from the ones written %v (%v)\n", read, seen, err)
+	}
+}
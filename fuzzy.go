@@ -0,0 +1,78 @@
+package main
+
+import "strings"
+
+// GetFuzzyDistance returns the number of typing mistakes accepted in a
+// graded answer. 0 means that the answers must match.
+func (p InterrogationParameters) GetFuzzyDistance() int {
+	return p.fuzzy
+}
+
+// MatchOptions tell how close a given answer must be to the expected one.
+type MatchOptions struct {
+	// MaxDistance is the number of characters inserted, deleted or
+	// replaced that are accepted. 0 means that the answers must match.
+	MaxDistance int
+}
+
+// matchOptions returns the options to grade the answers of the session.
+func (p InterrogationParameters) matchOptions() MatchOptions {
+	return MatchOptions{MaxDistance: p.fuzzy}
+}
+
+// CompareAnswers tells if the answer given is accepted for the expected
+// one. It is when it matches like isCorrect does, or when the Levenshtein
+// distance between the normalized answers is at most opts.MaxDistance. A
+// near-miss must still keep some characters of the expected answer: an
+// answer as long as the distance is never accepted for a short one.
+func CompareAnswers(expected, given string, opts MatchOptions) bool {
+	if isCorrect(given, expected) {
+		return true
+	}
+	if opts.MaxDistance <= 0 {
+		return false
+	}
+	e := []rune(strings.ToLower(normalizeText(expected)))
+	g := []rune(strings.ToLower(normalizeText(given)))
+	distance := levenshtein(e, g)
+	return distance <= opts.MaxDistance && distance < len(e)
+}
+
+// matchesAny tells if the answer given is accepted for one of the
+// alternatives.
+func (opts MatchOptions) matchesAny(given string, alternatives []string) bool {
+	for _, alternative := range alternatives {
+		if CompareAnswers(alternative, given, opts) {
+			return true
+		}
+	}
+	return false
+}
+
+// levenshtein returns the minimum number of runes to insert, delete or
+// replace to change a into b. Only 2 rows of the table are kept.
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
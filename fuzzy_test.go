@@ -0,0 +1,54 @@
+package main
+
+import "testing"
+
+// TestLevenshtein checks the distance on insertions, deletions and
+// replacements.
+func TestLevenshtein(t *testing.T) {
+	cases := []struct {
+		a, b     string
+		distance int
+	}{
+		{"", "", 0},
+		{"manger", "manger", 0},
+		{"manger", "mangér", 1},
+		{"kitten", "sitting", 3},
+		{"", "eau", 3},
+		{"boire", "bore", 1},
+	}
+	for _, c := range cases {
+		if d := levenshtein([]rune(c.a), []rune(c.b)); d != c.distance {
+			t.Errorf("The distance between '%s' and '%s' should be %d but we got %d\n", c.a, c.b, c.distance, d)
+		}
+	}
+}
+
+// TestCompareAnswers checks that the near-misses are accepted within the
+// distance, but not the answers far from the expected one.
+func TestCompareAnswers(t *testing.T) {
+	cases := []struct {
+		expected, given string
+		maxDistance     int
+		accepted        bool
+	}{
+		{"to eat", " To Eat", 0, true},
+		{"to eat", "to aet", 0, false},
+		{"to eat", "to aet", 2, true},
+		{"to eat", "to eaten", 2, true},
+		{"to eat", "to drink", 2, false},
+		{"to", "at", 2, false},
+	}
+	for _, c := range cases {
+		if CompareAnswers(c.expected, c.given, MatchOptions{MaxDistance: c.maxDistance}) != c.accepted {
+			t.Errorf("With a distance of %d, accepting '%s' for '%s' should be %v\n", c.maxDistance, c.given, c.expected, c.accepted)
+		}
+	}
+
+	p, err := parse(noEnvironment, "-fuzzy", "2")
+	if err != nil || p.GetFuzzyDistance() != 2 {
+		t.Errorf("The distance should be 2 but we got %d and %v\n", p.GetFuzzyDistance(), err)
+	}
+	if _, err := parse(noEnvironment, "-fuzzy", "-1"); err == nil {
+		t.Errorf("A negative distance should be refused.")
+	}
+}
@@ -0,0 +1,14 @@
+module github.com/boris-lenzinger/simple-learning
+
+go 1.25.0
+
+require (
+	github.com/fatih/color v1.19.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/mattn/go-colorable v0.1.14 // indirect
+	github.com/mattn/go-isatty v0.0.20 // indirect
+	golang.org/x/sys v0.42.0 // indirect
+)
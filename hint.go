@@ -0,0 +1,30 @@
+package main
+
+import "strings"
+
+// extractHintNote reads the hint and the note of a question in the fields
+// that follow it: the second one is the hint and the third one the note.
+// The fields are returned with the answer only. The hint and the note are
+// empty if their field is missing.
+func extractHintNote(fields []string) ([]string, string, string) {
+	var hint, note string
+	if len(fields) > 1 {
+		hint = strings.TrimSpace(fields[1])
+	}
+	if len(fields) > 2 {
+		note = strings.TrimSpace(fields[2])
+	}
+	return fields[:1], hint, note
+}
+
+// GetHint returns the hint of the entry of index i, revealed on demand
+// before the answer. It is empty if the entry has none.
+func (qa QuestionsAnswers) GetHint(i int) string {
+	return qa.hints[i]
+}
+
+// GetNote returns the note of the entry of index i, shown after the
+// answer. It is empty if the entry has none.
+func (qa QuestionsAnswers) GetNote(i int) string {
+	return qa.notes[i]
+}
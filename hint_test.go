@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// TestParseHintNote checks that the third and fourth fields are read as the
+// hint and the note of the question with HintNoteFields, and written back.
+func TestParseHintNote(t *testing.T) {
+	deck := "### Lesson 1\nmanger;to eat #verb;starts with t;irregular\nboire;to drink;starts with d\ncourir;to run\n"
+
+	tpp := getTpp()
+	tpp.TagPrefix = "#"
+	topic := ParseTopic(strings.NewReader(deck), tpp)
+	if qa := topic.GetSubsection("1"); !strings.Contains(qa.answers[0], "starts with t") || qa.GetHint(0) != "" {
+		t.Errorf("By default the fields should be part of the answer but we got '%s' and the hint '%s'\n", qa.answers[0], qa.GetHint(0))
+	}
+
+	tpp.HintNoteFields = true
+	topic = ParseTopic(strings.NewReader(deck), tpp)
+	qa := topic.GetSubsection("1")
+	expected := []struct{ answer, hint, note string }{
+		{"to eat", "starts with t", "irregular"},
+		{"to drink", "starts with d", ""},
+		{"to run", "", ""},
+	}
+	for i, e := range expected {
+		if qa.answers[i] != e.answer || qa.GetHint(i) != e.hint || qa.GetNote(i) != e.note {
+			t.Errorf("The entry %d should be %+v but we got '%s', '%s' and '%s'\n", i, e, qa.answers[i], qa.GetHint(i), qa.GetNote(i))
+		}
+	}
+
+	var out bytes.Buffer
+	if err := WriteTopic(&out, topic, tpp); err != nil {
+		t.Fatalf("Writing the topic failed: %v", err)
+	}
+	if out.String() != deck {
+		t.Errorf("The deck should be written back identically but we got:\n%s\n", out.String())
+	}
+}
+
+// TestSessionHintNote checks that the hint of a question is given when
+// asked and that its note is shown after the answer.
+func TestSessionHintNote(t *testing.T) {
+	tpp := getTpp()
+	tpp.HintNoteFields = true
+	topic := ParseTopic(strings.NewReader("### Lesson 1\nmanger;to eat;starts with t;irregular\nboire;to drink\n"), tpp)
+	qa := topic.BuildQuestionsSet()
+
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.limit = 1
+	ip.mode = linear
+	ip.noColor = true
+	ip.in = strings.NewReader(hintCommand + "\n\n" + hintCommand + "\n\n")
+
+	pr, pw := io.Pipe()
+	ip.out = pw
+	go func() {
+		defer pw.Close()
+		AskQuestions(qa, ip)
+	}()
+	output, _ := ioutil.ReadAll(pr)
+
+	for _, expected := range []string{"Hint: starts with t", "Note: irregular", "Hint: t..."} {
+		if !strings.Contains(string(output), expected) {
+			t.Errorf("The output should contain '%s'. Output was:\n%s\n", expected, output)
+		}
+	}
+	if strings.Count(string(output), "Note: ") != 1 {
+		t.Errorf("Only the question with a note should show one. Output was:\n%s\n", output)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bufio"
+	"html"
+	"io"
+)
+
+// htmlHeader starts the page of the flashcards. The style and the script
+// are inline so that the page can be opened without anything else: a click
+// on a card shows or hides its answer.
+const htmlHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Flashcards</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+.card { display: inline-block; width: 16em; min-height: 4em; margin: 0.5em; padding: 1em; border: 1px solid #888; border-radius: 0.5em; cursor: pointer; vertical-align: top; }
+.answer { display: none; margin-top: 0.5em; color: #06c; }
+.card.revealed .answer { display: block; }
+</style>
+</head>
+<body>
+`
+
+// htmlFooter ends the page of the flashcards.
+const htmlFooter = `<script>
+document.querySelectorAll(".card").forEach(function (card) {
+  card.addEventListener("click", function () { card.classList.toggle("revealed"); });
+});
+</script>
+</body>
+</html>
+`
+
+// WriteHTML writes the topic as a page of flashcards: each question is a
+// card that shows its answer when clicked. The cards are grouped under the
+// heading of their subsection, in the order of the subsections.
+func (topic Topic) WriteHTML(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(htmlHeader)
+	for _, id := range topic.GetSubsectionsName() {
+		// Questions found before any subsection have an empty id and no
+		// heading.
+		if len(id) != 0 {
+			bw.WriteString("<h2>" + html.EscapeString(id) + "</h2>\n")
+		}
+		qa := topic.list[id]
+		for i := range qa.questions {
+			bw.WriteString(`<div class="card"><div class="question">` + html.EscapeString(qa.questions[i]) + "</div>")
+			bw.WriteString(`<div class="answer">` + html.EscapeString(qa.answers[i]) + "</div></div>\n")
+		}
+	}
+	bw.WriteString(htmlFooter)
+	return bw.Flush()
+}
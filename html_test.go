@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestWriteHTML checks that the page has one card per question, under the
+// heading of its subsection, and that the special characters are escaped.
+func TestWriteHTML(t *testing.T) {
+	topic := ParseTopic(strings.NewReader(getSampleCsvAsStream()+"\n### Lesson <b>\nx < y;x & y\n"), getTpp())
+
+	var out bytes.Buffer
+	if err := topic.WriteHTML(&out); err != nil {
+		t.Fatalf("Writing the page failed: %v", err)
+	}
+	page := out.String()
+	if cards := strings.Count(page, `<div class="card">`); cards != 7 {
+		t.Errorf("The page should have 7 cards but has %d\n", cards)
+	}
+	if headings := strings.Count(page, "<h2>"); headings != 4 {
+		t.Errorf("The page should have a heading per subsection but has %d\n", headings)
+	}
+	if !strings.Contains(page, "<h2>&lt;b&gt;</h2>") || !strings.Contains(page, "x &lt; y</div>") || !strings.Contains(page, "x &amp; y</div>") {
+		t.Errorf("The special characters should be escaped. Page was:\n%s\n", page)
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// includeDirective follows the announce of the topics at the start of the
+// lines of a csv deck that include another deck, like '### include
+// verbs.csv' with the default announce. The path is relative to the deck.
+const includeDirective = "include "
+
+// followIncludes returns the parameters reading the include directives of
+// the deck at path with the opener: the topics of the deck included are
+// added where the directive is. chain holds the decks including this one,
+// to stop on a deck including itself.
+func (p TopicParsingParameters) followIncludes(open opener, path string, c clock, chain []string) TopicParsingParameters {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	chain = append(append([]string{}, chain...), abs)
+	p.include = func(included string) (Topic, error) {
+		if !filepath.IsAbs(included) {
+			included = filepath.Join(filepath.Dir(path), included)
+		}
+		if abs, err := filepath.Abs(included); err == nil {
+			for _, including := range chain {
+				if including == abs {
+					return NewTopic(), fmt.Errorf("The deck %s includes itself: %s.", included, strings.Join(append(chain, abs), " includes "))
+				}
+			}
+		}
+		file, err := openWithRetry(open, included, c)
+		if err != nil {
+			return NewTopic(), err
+		}
+		defer file.Close()
+		return ParseDeck(included, file, p.followIncludes(open, included, c, chain))
+	}
+	return p
+}
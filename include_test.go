@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeDecks writes the decks in a temporary directory and returns it.
+func writeDecks(t *testing.T, decks map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range decks {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// includeTpp returns the parameters of the decks of the tests, where the
+// topics are announced by '### ' like by default.
+func includeTpp() TopicParsingParameters {
+	tpp := getTpp()
+	tpp.TopicAnnounce = "### "
+	return tpp
+}
+
+// TestInclude checks that the topics of the decks included are added where
+// their directive is, the paths being relative to the deck including them.
+func TestInclude(t *testing.T) {
+	dir := writeDecks(t, map[string]string{
+		"main.csv":        "### Lesson 1\nmanger;to eat\n### include parts/verbs.csv\ncourir;to run\n### Lesson 3\nchat;cat\n",
+		"parts/verbs.csv": "### Lesson 1\nboire;to drink\n### include more.yaml\n",
+		"parts/more.yaml": "Lesson 2:\n  - q: dormir\n    a: to sleep\n",
+	})
+	topic, err := ParseTopicFromFile(filepath.Join(dir, "main.csv"), includeTpp())
+	if err != nil {
+		t.Fatalf("The deck should be read but we got %v\n", err)
+	}
+	if expected := []string{"Lesson 1", "Lesson 2", "Lesson 3"}; !reflect.DeepEqual(topic.GetSubsectionsName(), expected) {
+		t.Errorf("The topics should be %v but we got %v\n", expected, topic.GetSubsectionsName())
+	}
+	if qa := topic.GetSubsection("Lesson 1"); !reflect.DeepEqual(qa.questions, []string{"manger", "boire", "courir"}) {
+		t.Errorf("The questions after the directive should stay in their topic but we got %v\n", qa.questions)
+	}
+}
+
+// TestIncludeCycle checks that a deck including itself, directly or not,
+// is refused.
+func TestIncludeCycle(t *testing.T) {
+	dir := writeDecks(t, map[string]string{
+		"a.csv": "### Lesson 1\nmanger;to eat\n### include b.csv\n",
+		"b.csv": "### Lesson 2\nboire;to drink\n### include a.csv\n",
+	})
+	_, err := ParseTopicFromFile(filepath.Join(dir, "a.csv"), includeTpp())
+	if err == nil || !strings.Contains(err.Error(), "includes itself") {
+		t.Errorf("The cycle should be reported but we got %v\n", err)
+	}
+	if _, err := ParseTopicFromFile(filepath.Join(dir, "missing.csv"), includeTpp()); err == nil {
+		t.Errorf("A missing deck should be reported\n")
+	}
+}
+
+// TestIncludeFromReader checks that the directives of a deck that is not
+// read from a file are reported and ignored.
+func TestIncludeFromReader(t *testing.T) {
+	topic, warnings, err := ParseTopicWithWarnings(strings.NewReader("### Lesson 1\nmanger;to eat\n### include verbs.csv\n"), includeTpp())
+	if err != nil || topic.GetSubsectionsCount() != 1 || len(warnings) != 1 || warnings[0].Line != 3 {
+		t.Errorf("The directive should be ignored with a warning but we got %v, %v and %v\n", topic.GetSubsectionsName(), warnings, err)
+	}
+}
+
+// TestIncludeAnnounce checks that the directive starts with the announce of
+// the topics of the deck.
+func TestIncludeAnnounce(t *testing.T) {
+	dir := writeDecks(t, map[string]string{
+		"main.csv":  "## Lesson 1\nmanger;to eat\n## include verbs.csv\n### include verbs.csv;\n",
+		"verbs.csv": "## Lesson 2\nboire;to drink\n",
+	})
+	tpp := getTpp()
+	tpp.TopicAnnounce = "## "
+	topic, err := ParseTopicFromFile(filepath.Join(dir, "main.csv"), tpp)
+	if err != nil {
+		t.Fatalf("The deck should be read but we got %v\n", err)
+	}
+	if expected := []string{"Lesson 1", "Lesson 2"}; !reflect.DeepEqual(topic.GetSubsectionsName(), expected) {
+		t.Errorf("The topics should be %v but we got %v\n", expected, topic.GetSubsectionsName())
+	}
+	if qa := topic.GetSubsection("Lesson 1"); !reflect.DeepEqual(qa.questions, []string{"manger", "### include verbs.csv"}) {
+		t.Errorf("The line starting with the default announce should be a question but we got %v\n", qa.questions)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// masteredStreak is the number of correct answers in a row after which a
+// question leaves the leeches.
+const masteredStreak = 3
+
+// Leech is a question that was missed and that is kept until the user
+// answers it correctly enough times in a row.
+type Leech struct {
+	Answer string `json:"answer"`
+	Streak int    `json:"streak"` // number of correct answers in a row
+}
+
+// Leeches are the questions missed across the sessions, keyed by the text
+// of the question.
+type Leeches map[string]Leech
+
+// ReadLeeches reads the leeches saved in JSON by Write.
+func ReadLeeches(r io.Reader) (Leeches, error) {
+	leeches := make(Leeches)
+	if err := json.NewDecoder(r).Decode(&leeches); err != nil {
+		return nil, err
+	}
+	return leeches, nil
+}
+
+// Write saves the leeches in JSON.
+func (l Leeches) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(l)
+}
+
+// Update records the answers of the session on the questions set: the
+// questions missed are added or start their streak again, and the leeches
+// answered correctly get closer to be mastered. Those answered correctly
+// masteredStreak times in a row are removed.
+func (l Leeches) Update(qa QuestionsAnswers, r SessionResult) {
+	for _, i := range r.Wrong {
+		l[qa.questions[i]] = Leech{Answer: qa.answers[i]}
+	}
+	for _, i := range r.Right {
+		leech, found := l[qa.questions[i]]
+		if !found || containsIndex(r.Wrong, i) {
+			continue
+		}
+		leech.Streak++
+		if leech.Streak >= masteredStreak {
+			delete(l, qa.questions[i])
+			continue
+		}
+		l[qa.questions[i]] = leech
+	}
+}
+
+// containsIndex tells if i is one of the indexes.
+func containsIndex(indexes []int, i int) bool {
+	for _, index := range indexes {
+		if index == i {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+// getLeechesSet returns the questions set used by the tests of the leeches.
+func getLeechesSet() QuestionsAnswers {
+	qa := NewQA()
+	qa.AddEntry("manger", "to eat")
+	qa.AddEntry("boire", "to drink")
+	qa.AddEntry("dormir", "to sleep")
+	return qa
+}
+
+// TestLeechesAddMiss checks that a question missed is added to the leeches
+// and that a question already there starts its streak again.
+func TestLeechesAddMiss(t *testing.T) {
+	leeches := Leeches{"boire": {Answer: "to drink", Streak: 2}}
+	leeches.Update(getLeechesSet(), SessionResult{Wrong: []int{0, 1}, Right: []int{2}})
+
+	expected := Leeches{
+		"manger": {Answer: "to eat"},
+		"boire":  {Answer: "to drink"},
+	}
+	if !reflect.DeepEqual(leeches, expected) {
+		t.Errorf("The leeches should be %v but we got %v\n", expected, leeches)
+	}
+}
+
+// TestLeechesIncrementOnCorrect checks that a correct answer increases the
+// streak of a leech, unless the question was also missed in the session.
+func TestLeechesIncrementOnCorrect(t *testing.T) {
+	leeches := Leeches{
+		"manger": {Answer: "to eat"},
+		"boire":  {Answer: "to drink", Streak: 1},
+	}
+	leeches.Update(getLeechesSet(), SessionResult{Wrong: []int{0}, Right: []int{0, 1}})
+
+	if leeches["manger"].Streak != 0 {
+		t.Errorf("A question missed in the session must not increase its streak. Found %d\n", leeches["manger"].Streak)
+	}
+	if leeches["boire"].Streak != 2 {
+		t.Errorf("A correct answer should increase the streak to 2. Found %d\n", leeches["boire"].Streak)
+	}
+}
+
+// TestLeechesPruneMastered checks that a leech answered correctly enough
+// times in a row leaves the file, and that the file can be read back.
+func TestLeechesPruneMastered(t *testing.T) {
+	leeches := Leeches{
+		"manger": {Answer: "to eat", Streak: masteredStreak - 1},
+		"boire":  {Answer: "to drink"},
+	}
+	leeches.Update(getLeechesSet(), SessionResult{Right: []int{0, 1}})
+
+	if _, found := leeches["manger"]; found {
+		t.Errorf("The question 'manger' is mastered and should have been removed.")
+	}
+
+	var buf bytes.Buffer
+	if err := leeches.Write(&buf); err != nil {
+		t.Fatalf("Writing the leeches failed: %v", err)
+	}
+	read, err := ReadLeeches(&buf)
+	if err != nil {
+		t.Fatalf("Reading the leeches back failed: %v", err)
+	}
+	if !reflect.DeepEqual(read, leeches) {
+		t.Errorf("The leeches read back %v differ from the ones written %v\n", read, leeches)
+	}
+}
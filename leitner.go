@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// reviewCommand is the subcommand asking the questions of a deck due in
+// its Leitner boxes, like in: repeatit review deck.csv
+const reviewCommand = "review"
+
+// leitnerBoxes is the number of Leitner boxes. The questions of the box n,
+// from 1, are asked every 2^(n-1) days.
+const leitnerBoxes = 5
+
+// leitnerExtension is added to the path of a deck to get the file of its
+// boxes in the review subcommand, when -leitner is not set.
+const leitnerExtension = ".leitner"
+
+// GetLeitnerPath returns the path of the file where the Leitner boxes of
+// the questions are kept. Empty means no boxes.
+func (p InterrogationParameters) GetLeitnerPath() string {
+	return p.leitner
+}
+
+// IsDueOnly tells if only the questions due in their Leitner box are
+// asked.
+func (p InterrogationParameters) IsDueOnly() bool {
+	return p.dueOnly
+}
+
+// reviewDeck sets the parameters of the review subcommand on the deck at
+// path: only the questions due are asked, and the user grades the answers
+// to move the questions between the boxes.
+func (p InterrogationParameters) reviewDeck(path string) InterrogationParameters {
+	if p.leitner == "" {
+		p.leitner = path + leitnerExtension
+	}
+	p.dueOnly = true
+	p.selfGrade = true
+	p.interactive = true
+	return p
+}
+
+// LeitnerCard is the box of a question and the day it is due.
+type LeitnerCard struct {
+	Box int       `json:"box"`
+	Due time.Time `json:"due"`
+}
+
+// LeitnerBoxes holds the box of the questions of a deck, keyed by their
+// text. The questions that are not in a box are new ones, due at once.
+type LeitnerBoxes map[string]LeitnerCard
+
+// ReadLeitnerBoxes reads the boxes saved in JSON by Write.
+func ReadLeitnerBoxes(r io.Reader) (LeitnerBoxes, error) {
+	boxes := make(LeitnerBoxes)
+	if err := json.NewDecoder(r).Decode(&boxes); err != nil {
+		return nil, err
+	}
+	return boxes, nil
+}
+
+// Write saves the boxes in JSON.
+func (b LeitnerBoxes) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(b)
+}
+
+// FilterDue returns the entries of the set due at now, the new ones
+// included.
+func (b LeitnerBoxes) FilterDue(qa QuestionsAnswers, now time.Time) QuestionsAnswers {
+	return qa.filterDue(now, func(question string) (time.Time, bool) {
+		card, found := b[question]
+		return card.Due, found
+	})
+}
+
+// Update moves the questions answered during the session at now: a
+// question known goes to the next box, if any, and a question missed at
+// least once goes back to the first one. A question is due at the start of
+// the day its box is asked again.
+func (b LeitnerBoxes) Update(qa QuestionsAnswers, r SessionResult, now time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	for _, i := range r.Right {
+		if containsIndex(r.Wrong, i) {
+			continue
+		}
+		box := b[qa.questions[i]].Box
+		if box == 0 {
+			// A new question starts in the first box.
+			box = 1
+		}
+		box++
+		if box > leitnerBoxes {
+			box = leitnerBoxes
+		}
+		b[qa.questions[i]] = LeitnerCard{Box: box, Due: today.AddDate(0, 0, 1<<uint(box-1))}
+	}
+	for _, i := range r.Wrong {
+		b[qa.questions[i]] = LeitnerCard{Box: 1, Due: today.AddDate(0, 0, 1)}
+	}
+}
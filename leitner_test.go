@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLeitnerUpdate checks that the questions known move to the next box,
+// up to the last one, and that the ones missed go back to the first box.
+func TestLeitnerUpdate(t *testing.T) {
+	qa := ParseTopic(strings.NewReader("### Lesson 1\nmanger;to eat\nboire;to drink\ndormir;to sleep\ncourir;to run\n"), getTpp()).BuildQuestionsSet()
+	now := time.Date(2020, 1, 1, 15, 30, 0, 0, time.UTC)
+	today := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	boxes := LeitnerBoxes{
+		"boire":  {Box: 2},
+		"dormir": {Box: leitnerBoxes},
+		"courir": {Box: 4},
+	}
+	boxes.Update(qa, SessionResult{Right: []int{0, 1, 2, 3}, Wrong: []int{3}}, now)
+
+	expected := LeitnerBoxes{
+		"manger": {Box: 2, Due: today.AddDate(0, 0, 2)},
+		"boire":  {Box: 3, Due: today.AddDate(0, 0, 4)},
+		"dormir": {Box: leitnerBoxes, Due: today.AddDate(0, 0, 16)},
+		"courir": {Box: 1, Due: today.AddDate(0, 0, 1)},
+	}
+	if !reflect.DeepEqual(boxes, expected) {
+		t.Errorf("The boxes should be %v but we got %v\n", expected, boxes)
+	}
+
+	var out bytes.Buffer
+	if err := boxes.Write(&out); err != nil {
+		t.Fatalf("Writing the boxes failed: %v", err)
+	}
+	read, err := ReadLeitnerBoxes(&out)
+	if err != nil {
+		t.Fatalf("Reading the boxes failed: %v", err)
+	}
+	if !reflect.DeepEqual(read, boxes) {
+		t.Errorf("The boxes read back %v differ from the ones written %v\n", read, boxes)
+	}
+}
+
+// TestLeitnerFilterDue checks that the questions due today and the new
+// ones are asked.
+func TestLeitnerFilterDue(t *testing.T) {
+	qa := ParseTopic(strings.NewReader("### Lesson 1\nmanger;to eat\nboire;to drink\ndormir;to sleep\n"), getTpp()).BuildQuestionsSet()
+	now := time.Date(2020, 1, 1, 8, 0, 0, 0, time.UTC)
+	boxes := LeitnerBoxes{
+		"manger": {Box: 2, Due: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		"boire":  {Box: 3, Due: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+	}
+	due := boxes.FilterDue(qa, now)
+	if expected := []string{"manger", "dormir"}; !reflect.DeepEqual(due.questions, expected) {
+		t.Errorf("The questions due should be %v but we got %v\n", expected, due.questions)
+	}
+
+	p, err := parse(noEnvironment, "-m", "linear")
+	if err != nil {
+		t.Fatalf("The parse failed: %v", err)
+	}
+	p = p.reviewDeck("deck.csv")
+	if p.GetLeitnerPath() != "deck.csv.leitner" || !p.IsDueOnly() || !p.IsSelfGraded() {
+		t.Errorf("The review should ask the questions due in the boxes of the deck, self-graded.")
+	}
+}
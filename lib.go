@@ -2,7 +2,9 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"github.com/fatih/color"
 	"io"
 	"math/rand"
 	"os"
@@ -10,7 +12,8 @@ import (
 	"strings"
 	"sync"
 	"time"
-	"github.com/fatih/color"
+	"unicode"
+	"unicode/utf8"
 )
 
 const (
@@ -19,14 +22,47 @@ const (
 )
 
 type QuestionsAnswers struct {
-	questions []string
-	answers   []string
+	questions  []string
+	answers    []string
+	tags       [][]string // tags of each entry. nil when the entry has no tag.
+	difficulty []int      // difficulty of each entry, from 1 to 5
+	origin     []string   // subsection each entry comes from, when the set is built from a topic
+	media      []string   // path of the media file of each entry. Empty when the entry has none
+	// alternatives holds the acceptable answers of each entry when it has
+	// several of them. nil when the entry has a single answer.
+	alternatives [][]string
+	reversed     []bool // tells for each entry if its answer is the prompt
+	// requires holds the tags that must be mastered before each entry is
+	// asked. nil when the entry has no prerequisite.
+	requires [][]string
+	hints    []string // hint of each entry, revealed on demand. Empty when the entry has none
+	notes    []string // note of each entry, shown after the answer. Empty when the entry has none
+	cloze    []string // line with the cloze deletions each entry is made from. Empty for the other entries
+}
+
+// entry gathers what is known about one question of a set. It allows to fill
+// the parallel slices of QuestionsAnswers in one go.
+type entry struct {
+	question     string
+	answer       string
+	tags         []string
+	difficulty   int
+	origin       string
+	media        string
+	alternatives []string
+	reversed     bool
+	requires     []string
+	hint         string
+	note         string
+	cloze        string
 }
 
 // Topic represents the list of subsections of the file with the questions
 // attached for that section.
 type Topic struct {
-	list map[string]QuestionsAnswers
+	list     map[string]QuestionsAnswers
+	order    []string     // the ids of the subsections in the order they were added
+	metadata DeckMetadata // what the deck tells about itself in its front matter
 }
 
 // TopicParsingParameters is a data structure that helps to parse the lines that
@@ -40,28 +76,204 @@ type TopicParsingParameters struct {
 	// the csv file. If this separator is found multiple times on the line, the
 	// first one is considered as the separator.
 	QaSep string
+	// TagPrefix is the prefix of the tags that can be put at the end of the
+	// answer, for instance '#' for 'answer #verb #common'. Tags are removed
+	// from the answer. If empty, no tag is extracted.
+	TagPrefix string
+	// DifficultyField tells that the last field of the line may be the
+	// difficulty of the question, an integer from 1 to 5.
+	DifficultyField bool
+	// DefaultDifficulty is the difficulty of the questions that do not set
+	// it.
+	DefaultDifficulty int
+	// MediaField tells that the last field of the line, after the answer and
+	// before the difficulty, may be the path of a media file, for instance
+	// the pronunciation of the question.
+	MediaField bool
+	// MultiAnswer tells that each field after the question is a distinct
+	// acceptable answer, instead of being part of a single answer containing
+	// the separator.
+	MultiAnswer bool
+	// AlternativeSep separates the acceptable answers within the answer, for
+	// instance '|' in 'manger;to eat|to dine'. Empty means that the answer
+	// is a single one.
+	AlternativeSep string
+	// AnswerConnector joins the answers of a question for the display when
+	// it has several. Empty means " / ".
+	AnswerConnector string
+	// Normalize tells that the curly quotes, the non-breaking spaces and the
+	// dashes of the questions and answers are replaced by the characters
+	// typed on a keyboard, and that the runs of spaces are collapsed.
+	Normalize bool
+	// SkipHeader tells that the first line of the file, when it is not the
+	// announce of a subsection, holds the headers of the columns, like
+	// 'Question;Answer' in the exports of spreadsheets. It is not a question.
+	SkipHeader bool
+	// QuestionsOnly tells that the lines without separator that do not
+	// announce a subsection are questions with an empty answer, for a list
+	// of prompts whose answers are not written yet.
+	QuestionsOnly bool
+	// Prerequisites tells that the answer may end with annotations like
+	// '@requires:verbs', after its tags: the question is asked only once the
+	// questions carrying the tag are mastered.
+	Prerequisites bool
+	// LegacySplit tells that the lines are split on the separator without
+	// reading the quotes. Otherwise, when the separator is a single
+	// character, the deck is read as RFC 4180 CSV: a field between double
+	// quotes may hold the separator, line breaks and doubled quotes.
+	LegacySplit bool
+	// HintNoteFields tells that the third field of the line is the hint of
+	// the question, revealed on demand, and the fourth one a note shown after
+	// the answer, for instance 'manger;to eat;starts with t;irregular'. The
+	// tags end the answer.
+	HintNoteFields bool
+	// Strict tells that the questions found twice are reported among the
+	// warnings of the deck, telling when their answers conflict.
+	Strict bool
+	// include reads the deck of an include directive. nil means that the
+	// deck is not read from a file: the directives are ignored.
+	include func(path string) (Topic, error)
+	// DetectSeparator tells that the separator was not set by the user: the
+	// one of a csv deck is found among ';', ',' and the tab, QaSep being
+	// used when none of them is.
+	DetectSeparator bool
 }
 
+// defaultAlternativeSep separates the acceptable answers within the answer
+// when no separator is set.
+const defaultAlternativeSep = "|"
+
+// defaultAnswerConnector joins the answers of a question when no connector
+// is set.
+const defaultAnswerConnector = " / "
+
 type interrogationMode int
 
 const (
 	linear  interrogationMode = iota // will ask questions in the same order as the file
 	random                           // will ask questions in a random order
 	summary                          // ask to show the list of subsections
+	shuffle                          // will ask each question once per loop, in a random order
+)
+
+// The interrogation modes that can be chosen with the WithMode option.
+const (
+	LinearMode = linear
+	RandomMode = random
 )
 
 type InterrogationParameters struct {
-	interactive bool
-	wait        time.Duration     // Default is to wait 2 seconds
-	mode        interrogationMode // Default is random.
-	in          io.Reader         // Default is to use io.Stdin. Allows to send command to the engine
-	out         io.Writer         // The place where the questions are written to
-	subsections string            // the list of selected subsections chosen for the questioning
-	limit       int               // Limit is the number of times the list is repeated during interrogation. Default is 10
-	reversed    bool              // Requires that questions becomes answers and answers becomes questions
-	qachan      chan string       // Experimental. Channel to receive questions and answers
-	command     chan string       // Experimental. Channel to receive commands
-	publisher   chan string       // Experimental. Channel to publish to the output. This channel collects all that needs to be put to the user.
+	interactive    bool
+	minWait        time.Duration     // Default is to wait 2 seconds
+	maxWait        time.Duration     // When greater than minWait, the wait is picked randomly between both
+	mode           interrogationMode // Default is random.
+	in             io.Reader         // Default is to use io.Stdin. Allows to send command to the engine
+	out            io.Writer         // The place where the questions are written to
+	subsections    string            // the list of selected subsections chosen for the questioning
+	limit          int               // Limit is the number of times the list is repeated during interrogation. Default is 10
+	reversed       bool              // Requires that questions becomes answers and answers becomes questions
+	answerFirst    bool              // The answers column is used as the prompt. Combined with reversed, the questions column is the prompt again
+	interleave     bool              // The questions are taken from each subsection in rotation
+	cram           bool              // The questions of the newest subsections are asked first and more often
+	cramCurve      string            // The weighting of the subsections in cram mode. Default is linear
+	mix            bool              // The direction of each question is picked randomly
+	rotate         bool              // For the entries with several answers, the one displayed is picked randomly
+	multiAnswer    bool              // Each field after the question is a distinct answer
+	connector      string            // Joins the answers of a question for the display. Empty means " / "
+	alternativeSep string            // Separates the acceptable answers within the answer. Empty means a single answer
+	normalize      bool              // The typographic characters of the deck are replaced by the ones of a keyboard
+	legacySplit    bool              // The lines of the deck are split on the separator without reading the quotes
+	hintNote       bool              // The third and fourth fields of the lines of the deck are the hint and the note
+	skipHeader     bool              // The first line of the deck holds the headers of the columns
+	dedup          string            // How the questions found twice are deduplicated: keep-first, keep-last or merge. Empty means no deduplication
+	mergePolicy    string            // How the topics found in several decks are merged: prefix, merge or error. Empty means prefix
+	questionsOnly  bool              // The lines without separator of the deck are questions with an empty answer
+	graded         bool              // In interactive mode, the user types the answers and they are checked
+	leitner        string            // Path of the file where the Leitner box of each question of the deck is kept
+	dueOnly        bool              // Only the questions due in their Leitner box are asked
+	selfGrade      bool              // In interactive mode, the user grades each answer once revealed. The answers not known are asked again at the end
+	choice         bool              // The answer is chosen by its number in a list where wrong answers are mixed with it
+	distractors    int               // In the multiple choice mode, the number of wrong answers listed. Default is defaultDistractors
+	fixChoices     bool              // In the multiple choice mode, the answer expected is listed first
+	fuzzy          int               // In graded mode, the number of typing mistakes accepted in an answer
+	partialCredit  bool              // The answers of a question are required parts, an answer with some of them scores the fraction given
+	echo           bool              // In graded mode, the answer typed is displayed with its differences to the expected one
+	verbose        bool              // Prints what was loaded before starting
+	verboseOut     io.Writer         // The place where the verbose information is written to. Default is os.Stderr
+	saveWrong      string            // Path of the deck where the questions wrongly answered are saved
+	saveFlagged    string            // Path of the deck where the questions flagged for review are saved
+	leeches        string            // Path of the file accumulating the questions missed across sessions
+	fresh          string            // Path of the file recording the session where each question was last seen
+	freshCount     int               // The questions seen in this number of last sessions are not asked. Default is 1
+	exam           int               // Number of questions drawn for an exam. 0 means no exam
+	adjustWait     bool              // In unattended mode, + and - typed by the user lengthen and shorten the wait
+	autoAdvance    time.Duration     // In interactive mode, the answer is revealed after this time if the user did not press Return. 0 means wait forever
+	batch          string            // Path to a manifest listing the decks to run one after the other
+	minDiff        int               // Only the questions with at least this difficulty are asked. 0 means no minimum
+	maxDiff        int               // Only the questions with at most this difficulty are asked. 0 means no maximum
+	hardFirst      bool              // The hardest questions are asked first
+	width          int               // Width of the lines where the questions and answers are wrapped. 0 means no wrapping
+	noColor        bool              // The output is not colored
+	showSection    bool              // The subsection is written as a header when it changes between 2 questions
+	schedule       string            // Path of the file where the schedule of the questions in SM-2 is kept
+	mastery        string            // Path of the file counting the correct answers in a row of each question, for the prerequisites
+	compare        string            // Path of the file where the last session of each deck is kept to compare the next one to it
+	stats          string            // Path of the file where the attempts and answers of each question are kept across sessions
+	weakFirst      bool              // With stats, the questions the most often missed in the previous sessions are asked first
+	pin            string            // The questions containing this text are asked first in each loop
+	coverage       bool              // The number of loops needed to see all the questions is written instead of asking them
+	review         int               // Number of questions of the previous subsections asked again after each subsection. 0 means no review
+	perSection     int               // Maximum number of questions of a subsection asked in a loop. 0 means no maximum
+	export         string            // The format the deck is written in instead of being asked. Empty means no export
+	compact        bool              // The cards are listed one per line instead of being asked
+	loopFooter     bool              // The loop is announced at its end instead of its start
+	timeLoops      bool              // The time taken by each loop is written at its end
+	progress       io.Writer         // When set, a status line with the current loop and question is written to it
+	announce       string            // The prefix of the lines announcing a subsection. Default is '### '
+	separator      string            // The separator between the question and the answer. Default is ';'
+	tag            string            // When set, only the questions carrying one of these tags, separated by commas, are asked
+	theme          string            // The name of the colors of the output, among colorThemes. Empty means the default theme
+	dataDir        string            // The directory of the files kept across sessions given with a relative path. Empty means the current directory
+	clock          clock             // Gives the time and waits. Default is the system clock.
+	rng            *rand.Rand        // Source of randomness for the random mode and the wait times.
+	seed           int64             // The seed of rng, saved to resume the session
+	start          int               // Number of questions already asked when the session is resumed
+	resume         string            // Path of the file where the state of the session is saved when the user quits
+	shutdownWait   time.Duration     // How long the end of the session waits for the output to be written. Default is defaultShutdownWait
+	// PlaybackHook is called with the path of the media of a card when the
+	// card is shown. It allows to play a pronunciation without making this
+	// package depend on an audio library. Default is nil: nothing is played.
+	PlaybackHook func(path string)
+	// Stats records the attempts and the graded answers of each question
+	// asked. Default is nil: nothing is recorded.
+	Stats Stats
+}
+
+// clock gives access to the time. It is part of the parameters so that the
+// tests do not have to wait for real.
+type clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	After(d time.Duration) <-chan time.Time
+}
+
+// defaultShutdownWait is how long the end of a session waits for the
+// goroutines writing the output when no other value is set.
+const defaultShutdownWait = 5 * time.Second
+
+// systemClock is the clock of the operating system.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
 }
 
 // IsSummaryMode tells if the parameters require to have a summary of the subsections.
@@ -79,13 +291,192 @@ func (p InterrogationParameters) IsReversedMode() bool {
 	return p.reversed
 }
 
+// IsAnswerInFront tells if the user chose the answers column as the prompt
+// with the -front option.
+func (p InterrogationParameters) IsAnswerInFront() bool {
+	return p.answerFirst
+}
+
+// IsInterleaved tells if the questions must be taken from each subsection
+// in rotation.
+func (p InterrogationParameters) IsInterleaved() bool {
+	return p.interleave
+}
+
+// IsMixMode tells if the direction of each question is picked randomly.
+func (p InterrogationParameters) IsMixMode() bool {
+	return p.mix
+}
+
+// pickSwapped picks randomly the direction of a question in mix mode. It
+// returns true when the answer has to be used as the prompt.
+func pickSwapped(rng *rand.Rand) bool {
+	return rng.Intn(2) == 1
+}
+
+// isPromptSwapped tells if the answer has to be displayed as the prompt. The
+// -front option and the reverse mode each swap the columns so combining them
+// gets back to the questions column as the prompt.
+func (p InterrogationParameters) isPromptSwapped() bool {
+	return p.IsAnswerInFront() != p.IsReversedMode()
+}
+
+// GetExportFormat returns the format the deck is written in instead of
+// being asked. Empty if the user did not ask for an export.
+func (p InterrogationParameters) GetExportFormat() string {
+	return p.export
+}
+
+// GetBatchManifest returns the path to the manifest listing the decks to
+// run. Empty if the user did not ask for a batch.
+func (p InterrogationParameters) GetBatchManifest() string {
+	return p.batch
+}
+
+// GetSeed returns the seed of the random sequence of the session. Two
+// sessions with the same seed on the same deck ask the same questions.
+func (p InterrogationParameters) GetSeed() int64 {
+	return p.seed
+}
+
+// setSeed starts the random sequence of the session from the seed.
+func (p *InterrogationParameters) setSeed(seed int64) {
+	p.seed = seed
+	p.rng = rand.New(rand.NewSource(seed))
+}
+
+// IsVerbose tells if the user wants to see what was loaded before starting.
+func (p InterrogationParameters) IsVerbose() bool {
+	return p.verbose
+}
+
+// GetVerboseStream gets the Writer where the verbose information is written
+// to.
+func (p InterrogationParameters) GetVerboseStream() io.Writer {
+	return p.verboseOut
+}
+
+// GetLeechesPath returns the path of the file where the questions missed
+// are accumulated across sessions. It is empty if not requested.
+func (p InterrogationParameters) GetLeechesPath() string {
+	return p.leeches
+}
+
+// GetSaveFlaggedPath returns the path of the deck where the questions
+// flagged for review are saved. It is empty if not requested.
+func (p InterrogationParameters) GetSaveFlaggedPath() string {
+	return p.saveFlagged
+}
+
+// GetSaveWrongPath returns the path of the deck where the questions wrongly
+// answered must be saved. Empty if they must not be saved.
+func (p InterrogationParameters) GetSaveWrongPath() string {
+	return p.saveWrong
+}
+
+// IsExamMode tells if the user asked for an exam with the -exam option.
+func (p InterrogationParameters) IsExamMode() bool {
+	return p.exam > 0
+}
+
+// UsesDifficulty tells if the user filters or orders the questions by their
+// difficulty.
+func (p InterrogationParameters) UsesDifficulty() bool {
+	return p.minDiff != 0 || p.maxDiff != 0 || p.hardFirst
+}
+
+// ApplyDifficulty filters the questions set on the range of difficulty
+// chosen by the user and puts the hardest questions first if asked to.
+func (p InterrogationParameters) ApplyDifficulty(qa QuestionsAnswers) QuestionsAnswers {
+	min, max := p.minDiff, p.maxDiff
+	if min == 0 {
+		min = minDifficulty
+	}
+	if max == 0 {
+		max = maxDifficulty
+	}
+	qa = qa.FilterByDifficulty(min, max)
+	if p.hardFirst {
+		qa = qa.SortByDifficulty()
+	}
+	return qa
+}
+
+// GetTopicParsingParameters returns the parameters to parse the decks
+// according to the options chosen by the user.
+func (p InterrogationParameters) GetTopicParsingParameters() TopicParsingParameters {
+	tpp := TopicParsingParameters{
+		TopicAnnounce: p.announce,
+		QaSep:         p.separator,
+	}
+	if p.separator == "" {
+		tpp.QaSep = defaultSeparator
+		tpp.DetectSeparator = true
+	}
+	if p.GetTag() != "" || p.GetMasteryPath() != "" {
+		tpp.TagPrefix = "#"
+	}
+	if p.UsesDifficulty() {
+		tpp.DifficultyField = true
+		tpp.DefaultDifficulty = defaultDifficulty
+	}
+	tpp.MultiAnswer = p.multiAnswer
+	tpp.AlternativeSep = p.alternativeSep
+	tpp.AnswerConnector = p.connector
+	tpp.Normalize = p.normalize
+	tpp.SkipHeader = p.skipHeader
+	tpp.QuestionsOnly = p.questionsOnly
+	tpp.LegacySplit = p.legacySplit
+	tpp.HintNoteFields = p.hintNote
+	tpp.Prerequisites = p.GetMasteryPath() != ""
+	return tpp
+}
+
+// GetTag returns the tags the questions must carry to be asked, separated
+// by commas. Empty means that all the questions are asked.
+func (p InterrogationParameters) GetTag() string {
+	return p.tag
+}
+
+// GetTags returns the tags selected by the end user: a question carrying
+// one of them is asked. nil means that all the questions are asked.
+func (p InterrogationParameters) GetTags() []string {
+	var tags []string
+	for _, tag := range strings.Split(p.tag, ",") {
+		if tag = strings.TrimSpace(tag); len(tag) != 0 {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
 // GetListOfSubsections returns a string array containing all the subsections selected by
 // the end user.
 func (p InterrogationParameters) GetListOfSubsections() []string {
 	if len(p.subsections) == 0 {
 		return nil
 	}
-	return strings.Split(p.subsections, ",")
+	ids := strings.Split(p.subsections, ",")
+	for n, id := range ids {
+		ids[n] = strings.TrimSuffix(id, reversedSuffix)
+	}
+	return ids
+}
+
+// reversedSuffix follows the subsections of the -l option whose questions
+// are asked in reverse, like in "Lesson 1,Lesson 2:r".
+const reversedSuffix = ":r"
+
+// GetReversedSubsections returns the subsections selected by the end user
+// whose questions are asked in reverse.
+func (p InterrogationParameters) GetReversedSubsections() []string {
+	var reversed []string
+	for _, id := range strings.Split(p.subsections, ",") {
+		if strings.HasSuffix(id, reversedSuffix) {
+			reversed = append(reversed, strings.TrimSuffix(id, reversedSuffix))
+		}
+	}
+	return reversed
 }
 
 // NewQA builds an empty set of questions/answers.
@@ -95,43 +486,175 @@ func NewQA() QuestionsAnswers {
 
 // NewCommeLineParameters is parsing a list of strings to build a set of parameters
 // for the AskQuestion function.
+//
+// The config file, then the environment variables listed in envOptions give
+// the default values of some options. The command line takes precedence
+// over them.
 func Parse(args ...string) (InterrogationParameters, error) {
-	p := InterrogationParameters{
-		interactive: false,
-		wait:        2 * time.Second,
-		mode:        random,
-		in:          os.Stdin,
-		out:         os.Stdout,
-		subsections: "",
-		limit:       1,
-		qachan:      make(chan string),
-		command:     make(chan string),
-		publisher:   make(chan string),
-	}
-	for i, opt := range args {
-		switch opt {
-		case "-i":
-			p.interactive = true
-		case "-t":
-			value, err := strconv.Atoi(args[i+1])
-			if err != nil {
-				return p, fmt.Errorf("The time you set (%s) is not an integer. Please set the time in milliseconds.", args[i+1])
+	return parse(processEnvironment, args...)
+}
+
+// parse is Parse with the defaults read in the environment env.
+func parse(env environment, args ...string) (InterrogationParameters, error) {
+	p, err := loadDefaults(env, args)
+	if err != nil {
+		return p, err
+	}
+	p, err = parseArgs(p, args...)
+	if err != nil {
+		return p, err
+	}
+	p.resolveDataPaths()
+	if p.cram || p.interleave || p.hardFirst || p.weakFirst || p.review > 0 {
+		// These modes build the order of the questions, whatever the mode
+		// set by -m or by the defaults.
+		p.mode = linear
+	}
+	if p.IsExamMode() {
+		// An exam asks each question once, in the order of the draw, and
+		// checks the answers.
+		p.mode = linear
+		p.limit = 1
+		p.interactive = true
+		p.graded = true
+	}
+	// In unattended mode, the wait can be changed from a terminal. The
+	// terminal is not put in raw mode: the keys are read once Return is
+	// pressed.
+	if !p.interactive && p.in == os.Stdin && isTerminal(os.Stdin) {
+		p.adjustWait = true
+	}
+	return p, nil
+}
+
+// loadDefaults returns the parameters with the default values given by the
+// config file, then by the variables of the environment env.
+func loadDefaults(env environment, args []string) (InterrogationParameters, error) {
+	path, required := configPath(env, args)
+	p, err := loadConfig(NewInterrogationParameters(), path, required)
+	if err != nil {
+		return p, err
+	}
+	return applyEnvironment(p, env.lookup)
+}
+
+// parseArgs sets the options of the list of strings on the parameters.
+// The arguments that are not options are refused.
+func parseArgs(p InterrogationParameters, args ...string) (InterrogationParameters, error) {
+	var failed error
+	fs := newFlagSet(&p, &failed)
+	if err := fs.Parse(args); err != nil {
+		if failed != nil {
+			return p, failed
+		}
+		return p, flagError(err)
+	}
+	if fs.NArg() != 0 {
+		return p, optionErrorf(fs.Arg(0), ErrUnknownOption, "The argument %s is not an option.", fs.Arg(0))
+	}
+	return p, nil
+}
+
+// parseWait reads the value of the -t option. It is either a single time
+// in milliseconds or a range like 1500-3000.
+func parseWait(value string) (time.Duration, time.Duration, error) {
+	bounds := strings.SplitN(value, "-", 2)
+	waits := make([]time.Duration, len(bounds))
+	for i, bound := range bounds {
+		ms, err := strconv.Atoi(bound)
+		if err != nil {
+			return 0, 0, optionErrorf("-t", ErrInvalidTime, "The time you set (%s) is not an integer or a range of integers. Please set the time in milliseconds.", value)
+		}
+		waits[i] = time.Duration(ms) * time.Millisecond
+	}
+	if len(waits) == 1 {
+		return waits[0], waits[0], nil
+	}
+	if waits[0] > waits[1] {
+		return 0, 0, optionErrorf("-t", ErrInvalidTime, "The range of time you set (%s) is malformed: the lower bound is greater than the upper one.", value)
+	}
+	return waits[0], waits[1], nil
+}
+
+// nextWait returns the time to wait before revealing the answer.
+func (p InterrogationParameters) nextWait() time.Duration {
+	if p.maxWait <= p.minWait {
+		return p.minWait
+	}
+	return p.minWait + time.Duration(p.rng.Int63n(int64(p.maxWait-p.minWait)+1))
+}
+
+// waitStep is how much + and - change the wait in unattended mode.
+const waitStep = 500 * time.Millisecond
+
+// readsCommands tells if what the user types has to be read during the
+// session.
+func (p InterrogationParameters) readsCommands() bool {
+	return p.interactive || p.adjustWait
+}
+
+// waitUnattended waits before the answer is revealed in unattended mode.
+// When the wait can be adjusted, + and - typed by the user change it by
+// waitStep, the new value is written to the verbose stream and the wait
+// starts again.
+func (p *InterrogationParameters) waitUnattended(ctx context.Context, commands <-chan string) {
+	if !p.adjustWait {
+		p.sleep(ctx, p.nextWait())
+		return
+	}
+	for {
+		select {
+		case cmd, ok := <-commands:
+			if !ok {
+				// The input is exhausted: the wait cannot change anymore.
+				p.adjustWait = false
+				p.sleep(ctx, p.nextWait())
+				return
 			}
-			p.wait = time.Duration(value) * time.Millisecond
-		case "-m":
-			// The other mode is the default so we have nothing to do.
-			if args[i+1] == "linear" {
-				p.mode = linear
+			if p.changeWait(cmd) && p.GetVerboseStream() != nil {
+				fmt.Fprintf(p.GetVerboseStream(), "Wait: %v\n", p.minWait)
 			}
-		case "-s":
-			p.mode = summary
-		case "-l":
-			p.subsections = args[i+1]
-		case "-r":
-			p.reversed = true
+		case <-p.clock.After(p.nextWait()):
+			return
+		case <-ctx.Done():
+			return
 		}
 	}
-	return p, nil
+}
+
+// sleep waits for d, or less if the context is cancelled first. A context
+// that cannot be cancelled sleeps on the clock, which a fake clock does
+// without waiting.
+func (p InterrogationParameters) sleep(ctx context.Context, d time.Duration) {
+	if ctx.Done() == nil {
+		p.clock.Sleep(d)
+		return
+	}
+	select {
+	case <-p.clock.After(d):
+	case <-ctx.Done():
+	}
+}
+
+// changeWait lengthens the wait for + and shortens it for -, keeping at
+// least waitStep. The range of the wait, if any, is kept. It tells if the
+// command was one of them.
+func (p *InterrogationParameters) changeWait(cmd string) bool {
+	var delta time.Duration
+	switch strings.TrimSpace(cmd) {
+	case "+":
+		delta = waitStep
+	case "-":
+		delta = -waitStep
+		if p.minWait+delta < waitStep {
+			delta = waitStep - p.minWait
+		}
+	default:
+		return false
+	}
+	p.minWait += delta
+	p.maxWait += delta
+	return true
 }
 
 // GetCount returns the number of entries for the questions.
@@ -158,7 +681,7 @@ func (topic *Topic) GetSubsection(id string) QuestionsAnswers {
 	qa := topic.list[id]
 	if qa.questions == nil {
 		qa = NewQA()
-		topic.list[id] = qa
+		topic.SetSubsection(id, qa)
 	}
 	return qa
 }
@@ -166,6 +689,9 @@ func (topic *Topic) GetSubsection(id string) QuestionsAnswers {
 // SetSubsection defines a subsection with a given id and associates
 // to it a list of questions.
 func (topic *Topic) SetSubsection(id string, qa QuestionsAnswers) {
+	if _, found := topic.list[id]; !found {
+		topic.order = append(topic.order, id)
+	}
 	topic.list[id] = qa
 }
 
@@ -178,69 +704,578 @@ func (topic Topic) GetSubsectionsCount() int {
 	return size
 }
 
-// GetSubTopics returns the list of subtopics that have been imported.
+// GetSubTopics returns the list of subtopics that have been imported, in
+// the order they were added to the topic.
 func (topic Topic) GetSubsectionsName() []string {
-	subsections := []string{}
-	if topic.GetSubsectionsCount() != 0 {
-		subsections = make([]string, 0, len(topic.list))
-		for id := range topic.list {
-			subsections = append(subsections, id)
-		}
+	subsections := make([]string, len(topic.order))
+	copy(subsections, topic.order)
+	return subsections
+}
+
+// WriteTopicsList writes the names of the subsections of the topic, in
+// their order.
+func (topic Topic) WriteTopicsList(w io.Writer) error {
+	out := &stickyWriter{w: w}
+	list := topic.GetSubsectionsName()
+	if len(list) == 0 {
+		fmt.Fprintln(out, "No topic found in this file")
+		return out.err
+	}
+	fmt.Fprintln(out, "List of topics:")
+	fmt.Fprintln(out, "===============")
+	for _, id := range list {
+		fmt.Fprintf(out, "  * %s\n", id)
+	}
+	return out.err
+}
+
+// Subsections returns a copy of the subsections of the topic with their
+// questions. The sets of questions are copied too: since they would share
+// their storage with the ones of the topic otherwise, adding entries to them
+// could overwrite the entries added later to the topic.
+func (topic Topic) Subsections() map[string]QuestionsAnswers {
+	subsections := make(map[string]QuestionsAnswers, len(topic.list))
+	for id, qa := range topic.list {
+		clone := NewQA()
+		clone.Concatenate(qa)
+		subsections[id] = clone
 	}
 	return subsections
 }
 
+// Equal tells if both topics have the same subsections with the same
+// questions and answers. The order of the subsections does not matter but
+// the order of the questions inside a subsection does.
+func (topic Topic) Equal(other Topic) bool {
+	if topic.GetSubsectionsCount() != other.GetSubsectionsCount() {
+		return false
+	}
+	for id, qa := range topic.list {
+		otherQa, found := other.list[id]
+		if !found || !qa.equal(otherQa) {
+			return false
+		}
+	}
+	return true
+}
+
+// equal tells if both sets have the same questions and answers in the same
+// order.
+func (qa QuestionsAnswers) equal(other QuestionsAnswers) bool {
+	if qa.GetCount() != other.GetCount() {
+		return false
+	}
+	for i := range qa.questions {
+		if qa.questions[i] != other.questions[i] || qa.answers[i] != other.answers[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // ParseQuestions is reading the data source and transforms it to a topic
-// structure.
+// structure. The problems of the deck are ignored, see
+// ParseTopicWithWarnings.
 func ParseTopic(r io.Reader, p TopicParsingParameters) Topic {
-	// Reading the file line by line
-	s := bufio.NewScanner(r)
+	topic, _, _ := parseTopic(r, p)
+	return topic
+}
 
-	lines := make([]string, 50)
-	for s.Scan() {
-		lines = append(lines, s.Text())
+// ParseTopicWithWarnings reads the topic like ParseTopic does. It also
+// returns the problems of the deck that did not stop its parsing, with
+// their line: the lines that are neither a topic nor a question, the empty
+// questions and answers, the questions before any topic and the topics
+// announced twice. The error is the one of the reader, if any.
+func ParseTopicWithWarnings(r io.Reader, p TopicParsingParameters) (Topic, []ParseWarning, error) {
+	return parseTopic(r, p)
+}
+
+// AppendFromReader parses the reader and adds its subsections to the
+// topic. The questions of a subsection already in the topic are added after
+// its own questions, the other subsections are added after the ones of the
+// topic. It returns an error if the reader fails, the topic being then
+// unchanged.
+func (topic *Topic) AppendFromReader(r io.Reader, p TopicParsingParameters) error {
+	added, _, err := parseTopic(r, p)
+	if err != nil {
+		return err
+	}
+	topic.merge(added)
+	return nil
+}
+
+// merge adds the subsections of other to the topic, in their order. The
+// questions of a subsection found in both are concatenated in a new set so
+// that the sets obtained before from the topic are not modified.
+func (topic *Topic) merge(other Topic) {
+	if topic.list == nil {
+		topic.list = make(map[string]QuestionsAnswers)
+	}
+	if topic.metadata == (DeckMetadata{}) {
+		topic.metadata = other.metadata
+	}
+	for _, id := range other.order {
+		merged := NewQA()
+		merged.Concatenate(topic.list[id], other.list[id])
+		topic.SetSubsection(id, merged)
 	}
+}
 
+// parseTopic reads the topic like ParseTopicWithWarnings does.
+func parseTopic(r io.Reader, p TopicParsingParameters) (Topic, []ParseWarning, error) {
+	metadata, r, offset, warnings, err := readFrontMatter(r)
+	if err != nil {
+		return NewTopic(), nil, err
+	}
+	if p.DetectSeparator && metadata.Separator != "" {
+		p.QaSep, p.DetectSeparator = metadata.Separator, false
+	}
+	p, r = p.detectSeparator(r)
 	topic := NewTopic()
+	topic.metadata = metadata
+	warn := func(line int, format string, a ...interface{}) {
+		warnings = append(warnings, ParseWarning{Line: line, Message: fmt.Sprintf(format, a...)})
+	}
+	// The line where each topic was announced.
+	announced := make(map[string]int)
+	// The line and the answer where each question was first found, to
+	// report the ones found twice in strict mode.
+	type firstSeen struct {
+		line   int
+		answer string
+	}
+	seen := make(map[string]firstSeen)
+	duplicate := func(line int, question string, answer string) {
+		answer = strings.TrimSpace(answer)
+		first, found := seen[question]
+		switch {
+		case !p.Strict || len(strings.TrimSpace(question)) == 0:
+		case !found:
+			seen[question] = firstSeen{line: line, answer: answer}
+		case first.answer != answer:
+			warn(line, "The question %s is also at line %d with another answer: %s", question, first.line, first.answer)
+		default:
+			warn(line, "The question %s is also at line %d.", question, first.line)
+		}
+	}
 	var subsectionId string
 	qaSubsection := NewQA()
-	for i := 0; i < len(lines); i++ {
-		input := lines[i]
-		// Ignore empty lines
-		if len(input) > 0 {
-			split := strings.Split(input, p.QaSep)
-			switch len(split) {
-			case 1:
-				if strings.HasPrefix(input, p.TopicAnnounce) {
-					subsectionId = strings.TrimPrefix(input, p.TopicAnnounce)
-					qaSubsection = topic.GetSubsection(subsectionId)
+	// The header can only be the first line of the file that is not empty.
+	headerExpected := p.SkipHeader
+	// The failure of a deck included. The lines after it are ignored.
+	var failed error
+	directive := p.TopicAnnounce + includeDirective
+	// Each line is added to the topic as soon as it is read: the deck is
+	// never held in memory.
+	err = p.readRecords(r, func(line int, split []string) {
+		line += offset
+		input := split[0]
+		if failed != nil {
+			return
+		}
+		if len(split) == 1 && strings.HasPrefix(input, directive) {
+			path := strings.TrimSpace(strings.TrimPrefix(input, directive))
+			if p.include == nil {
+				warn(line, "The deck %s is not included: the deck is not read from a file.", path)
+				return
+			}
+			included, err := p.include(path)
+			if err != nil {
+				failed = fmt.Errorf("Line %d: the include of %s failed: %w", line, path, err)
+				return
+			}
+			topic.merge(included)
+			qaSubsection = topic.list[subsectionId]
+			return
+		}
+		if headerExpected {
+			headerExpected = false
+			if len(split) > 1 {
+				return
+			}
+		}
+		switch len(split) {
+		case 1:
+			if strings.HasPrefix(input, p.TopicAnnounce) {
+				subsectionId = strings.TrimPrefix(input, p.TopicAnnounce)
+				qaSubsection = topic.GetSubsection(subsectionId)
+				if first, found := announced[subsectionId]; found {
+					warn(line, "The topic %s is also announced at line %d: its questions are added to the ones of this line.", subsectionId, first)
+				} else {
+					announced[subsectionId] = line
 				}
-			default:
-				// Question is in split[0] while answer in in split[1]. It may happen
-				// the answer contains the separator so we have to join the different
-				// elements.
-				qaSubsection.AddEntry(split[0], strings.Join(split[1:], p.QaSep))
+			} else if cards := p.clozeEntries(input); len(cards) != 0 {
+				if len(announced) == 0 {
+					warn(line, "The question %s is before any topic.", cards[0].question)
+				}
+				for _, card := range cards {
+					duplicate(line, card.question, card.answer)
+					qaSubsection.addEntry(card)
+				}
+				topic.SetSubsection(subsectionId, qaSubsection)
+			} else if p.QuestionsOnly {
+				question := input
+				if p.Normalize {
+					question = normalizeText(question)
+				}
+				if len(announced) == 0 {
+					warn(line, "The question %s is before any topic.", question)
+				}
+				duplicate(line, question, "")
+				qaSubsection.addEntry(entry{question: question, difficulty: p.DefaultDifficulty})
 				topic.SetSubsection(subsectionId, qaSubsection)
+			} else {
+				warn(line, "The line is neither a topic nor a question: the separator %s is missing.", p.QaSep)
+			}
+		default:
+			// Question is in split[0] while answer in in split[1]. It may happen
+			// the answer contains the separator so we have to join the different
+			// elements.
+			fields := split[1:]
+			difficulty := p.DefaultDifficulty
+			if p.DifficultyField {
+				fields, difficulty = extractDifficulty(fields, p.DefaultDifficulty)
+			}
+			var media string
+			if p.MediaField {
+				fields, media = extractMedia(fields)
+			}
+			var hint, note string
+			if p.HintNoteFields {
+				fields, hint, note = extractHintNote(fields)
+			}
+			var answer string
+			var tags, alternatives, requires []string
+			if p.MultiAnswer && len(fields) > 1 {
+				alternatives = make([]string, len(fields))
+				for n, field := range fields {
+					alternatives[n] = strings.TrimSpace(field)
+				}
+				// The tags and the prerequisites end the last answer.
+				last := len(alternatives) - 1
+				if p.Prerequisites {
+					alternatives[last], requires = extractTags(alternatives[last], requiresPrefix)
+				}
+				if p.TagPrefix != "" {
+					alternatives[last], tags = extractTags(alternatives[last], p.TagPrefix)
+				}
+				answer = strings.Join(alternatives, p.answerConnector())
+			} else {
+				answer = strings.Join(fields, p.QaSep)
+				if p.Prerequisites {
+					answer, requires = extractTags(answer, requiresPrefix)
+				}
+				if p.TagPrefix != "" {
+					answer, tags = extractTags(answer, p.TagPrefix)
+				}
+				if p.AlternativeSep != "" && strings.Contains(answer, p.AlternativeSep) {
+					alternatives = strings.Split(answer, p.AlternativeSep)
+					for n := range alternatives {
+						alternatives[n] = strings.TrimSpace(alternatives[n])
+					}
+					answer = strings.Join(alternatives, p.answerConnector())
+				}
+			}
+			question := split[0]
+			if p.Normalize {
+				question = normalizeText(question)
+				answer = normalizeText(answer)
+				for n := range alternatives {
+					alternatives[n] = normalizeText(alternatives[n])
+				}
+			}
+			switch {
+			case len(strings.TrimSpace(question)) == 0:
+				warn(line, "The question is empty.")
+			case len(strings.TrimSpace(answer)) == 0:
+				warn(line, "The question %s has no answer.", question)
+			}
+			if len(announced) == 0 {
+				warn(line, "The question %s is before any topic.", question)
 			}
+			duplicate(line, question, answer)
+			qaSubsection.addEntry(entry{question: question, answer: answer, tags: tags, difficulty: difficulty, media: media, alternatives: alternatives, requires: requires, hint: hint, note: note})
+			topic.SetSubsection(subsectionId, qaSubsection)
 		}
+	})
+	if err == nil {
+		err = failed
 	}
-	return topic
+	if err != nil {
+		return NewTopic(), nil, err
+	}
+	return topic, warnings, nil
+}
+
+// readRecords calls record with the fields of each line of the csv deck
+// that is not empty, and the number of the line where it starts. The
+// fields are split as told by LegacySplit. The answer of a card goes on
+// over the next lines without separator while its line ends with a \, and
+// over the indented lines without separator that follow it: these lines
+// are added to its last field, each one on its own line. A blank line ends
+// the answer. A \ followed by nothing to add is kept, like the \ escaped
+// as \\ at the end of a line.
+func (p TopicParsingParameters) readRecords(r io.Reader, record func(line int, fields []string)) error {
+	var pending []string // the fields of the last line, which may go on
+	pendingLine := 0
+	continued := false // the last line ends with a \
+	flush := func() {
+		if pending == nil {
+			return
+		}
+		if continued {
+			pending[len(pending)-1] += `\`
+		}
+		record(pendingLine, pending)
+		pending, continued = nil, false
+	}
+	read := func(line int, fields []string) {
+		blank := len(strings.TrimSpace(strings.Join(fields, ""))) == 0
+		if len(pending) > 1 && len(fields) == 1 && !blank && (continued || strings.TrimLeft(fields[0], " \t") != fields[0]) {
+			var text string
+			text, continued = trimContinuation(fields[0])
+			pending[len(pending)-1] += "\n" + strings.TrimSpace(text)
+			return
+		}
+		flush()
+		if len(fields) == 0 {
+			return
+		}
+		pending, pendingLine = fields, line
+		if len(fields) > 1 {
+			fields[len(fields)-1], continued = trimContinuation(fields[len(fields)-1])
+		}
+	}
+	if err := p.splitRecords(r, read); err != nil {
+		return err
+	}
+	flush()
+	return nil
+}
+
+// trimContinuation removes the \ ending the text of a line that goes on
+// over the next one. It tells if there was one. The \ doubled at the end of
+// the text are escaped ones, kept once, that do not go on.
+func trimContinuation(text string) (string, bool) {
+	trimmed := strings.TrimSpace(text)
+	body := strings.TrimRight(trimmed, `\`)
+	escaped := len(trimmed) - len(body)
+	if escaped == 0 {
+		return text, false
+	}
+	kept := body + strings.Repeat(`\`, escaped/2)
+	if escaped%2 == 0 {
+		return kept, false
+	}
+	return strings.TrimSpace(kept), true
+}
+
+// escapeContinuation doubles the \ ending the text so that they are not read
+// as the continuation of the line.
+func escapeContinuation(text string) string {
+	trimmed := strings.TrimRightFunc(text, unicode.IsSpace)
+	body := strings.TrimRight(trimmed, `\`)
+	return trimmed + trimmed[len(body):] + text[len(trimmed):]
+}
+
+// splitRecords calls record with the fields of each line of the csv deck,
+// none for an empty one, and the number of the line where it starts. With a
+// separator of one character, a field fully between double quotes may hold
+// the separator, line breaks and doubled quotes, like in RFC 4180 CSV. The
+// quotes inside the other fields are kept as they are.
+func (p TopicParsingParameters) splitRecords(r io.Reader, record func(line int, fields []string)) error {
+	sep := []rune(p.QaSep)
+	quoted := !p.LegacySplit && len(sep) == 1
+	var fields []string
+	var field strings.Builder
+	inQuotes := false // the line goes on with the quoted field of the previous one
+	start := 0        // the line where the record of the quoted field starts
+	s := bufio.NewScanner(r)
+	line := 1
+	for ; s.Scan(); line++ {
+		input := s.Text()
+		if !quoted || (!inQuotes && len(input) == 0) {
+			if len(input) == 0 {
+				record(line, nil)
+			} else {
+				record(line, strings.Split(input, p.QaSep))
+			}
+			continue
+		}
+		if inQuotes {
+			field.WriteString("\n")
+		} else {
+			start = line
+		}
+		atStart := !inQuotes // the next rune starts a field
+		for n := 0; n < len(input); {
+			c, size := utf8.DecodeRuneInString(input[n:])
+			n += size
+			switch {
+			case inQuotes && c == '"' && strings.HasPrefix(input[n:], `"`):
+				field.WriteRune('"')
+				n++
+			case inQuotes && c == '"':
+				inQuotes = false
+				if next, _ := utf8.DecodeRuneInString(input[n:]); n < len(input) && next != sep[0] {
+					return &ParseError{Line: line, msg: fmt.Sprintf("The deck is malformed: the quoted field is followed by %q (line %d). Double the quotes inside a field between quotes.", input[n:], line)}
+				}
+			case inQuotes:
+				field.WriteRune(c)
+			case atStart && c == '"':
+				inQuotes = true
+			case c == sep[0]:
+				fields = append(fields, field.String())
+				field.Reset()
+				atStart = true
+				continue
+			default:
+				field.WriteRune(c)
+			}
+			atStart = false
+		}
+		if !inQuotes {
+			record(start, append(fields, field.String()))
+			fields = nil
+			field.Reset()
+		}
+	}
+	if err := s.Err(); err != nil {
+		return err
+	}
+	if inQuotes {
+		return &ParseError{Line: start, msg: fmt.Sprintf("The deck is malformed: the quoted field of the line %d is not closed.", start)}
+	}
+	return nil
+}
+
+// answerConnector returns the string joining the answers of a question.
+func (p TopicParsingParameters) answerConnector() string {
+	if len(p.AnswerConnector) == 0 {
+		return defaultAnswerConnector
+	}
+	return p.AnswerConnector
+}
+
+// WriteParseSummary writes what was loaded from a deck: its path, the
+// separator used and the number of subsections and questions found.
+func WriteParseSummary(w io.Writer, path string, p TopicParsingParameters, topic Topic) {
+	fmt.Fprintf(w, "Deck: %s\n", path)
+	if p.DetectSeparator {
+		fmt.Fprintln(w, "Separator: found in the deck")
+	} else {
+		fmt.Fprintf(w, "Separator: '%s'\n", p.QaSep)
+	}
+	fmt.Fprintf(w, "Subsections: %d\n", topic.GetSubsectionsCount())
+	count := 0
+	for _, qa := range topic.list {
+		count += qa.GetCount()
+	}
+	fmt.Fprintf(w, "Questions: %d\n", count)
 }
 
 // AddEntry adds a set of question/answer to the already existing set.
 func (qa *QuestionsAnswers) AddEntry(q string, a string) {
-	qa.questions = append(qa.questions, q)
-	qa.answers = append(qa.answers, a)
+	qa.AddTaggedEntry(q, a, nil)
+}
+
+// AddTaggedEntry adds a set of question/answer carrying some tags to the
+// already existing set.
+func (qa *QuestionsAnswers) AddTaggedEntry(q string, a string, tags []string) {
+	qa.addEntry(entry{question: q, answer: a, tags: tags})
+}
+
+// addEntry adds an entry to the set.
+func (qa *QuestionsAnswers) addEntry(e entry) {
+	qa.questions = append(qa.questions, e.question)
+	qa.answers = append(qa.answers, e.answer)
+	qa.tags = append(qa.tags, e.tags)
+	qa.difficulty = append(qa.difficulty, e.difficulty)
+	qa.origin = append(qa.origin, e.origin)
+	qa.media = append(qa.media, e.media)
+	qa.alternatives = append(qa.alternatives, e.alternatives)
+	qa.reversed = append(qa.reversed, e.reversed)
+	qa.requires = append(qa.requires, e.requires)
+	qa.hints = append(qa.hints, e.hint)
+	qa.notes = append(qa.notes, e.note)
+	qa.cloze = append(qa.cloze, e.cloze)
+}
+
+// invariant checks that the parallel slices of the set are aligned: they
+// hold one element per question.
+func (qa QuestionsAnswers) invariant() error {
+	lengths := []struct {
+		name   string
+		length int
+	}{
+		{"answers", len(qa.answers)},
+		{"tags", len(qa.tags)},
+		{"difficulties", len(qa.difficulty)},
+		{"origins", len(qa.origin)},
+		{"media", len(qa.media)},
+		{"alternatives", len(qa.alternatives)},
+		{"directions", len(qa.reversed)},
+		{"prerequisites", len(qa.requires)},
+		{"hints", len(qa.hints)},
+		{"notes", len(qa.notes)},
+		{"clozes", len(qa.cloze)},
+	}
+	for _, l := range lengths {
+		if l.length != len(qa.questions) {
+			return fmt.Errorf("The set has %d questions but %d %s.", len(qa.questions), l.length, l.name)
+		}
+	}
+	return nil
+}
+
+// Validate returns an error if the set is corrupted: each question must
+// have its answer. It can be called after a manual manipulation of the set.
+func (qa QuestionsAnswers) Validate() error {
+	return qa.invariant()
+}
+
+// entry returns the entry of index i.
+func (qa QuestionsAnswers) entry(i int) entry {
+	return entry{
+		question:     qa.questions[i],
+		answer:       qa.answers[i],
+		tags:         qa.tags[i],
+		difficulty:   qa.difficulty[i],
+		origin:       qa.origin[i],
+		media:        qa.media[i],
+		alternatives: qa.alternatives[i],
+		reversed:     qa.reversed[i],
+		requires:     qa.requires[i],
+		hint:         qa.hints[i],
+		note:         qa.notes[i],
+		cloze:        qa.cloze[i],
+	}
+}
+
+// appendEntryFromSubsection adds to the set the entry of index i of the
+// subsection id, recording where it comes from.
+func (qa *QuestionsAnswers) appendEntryFromSubsection(id string, from QuestionsAnswers, i int) {
+	e := from.entry(i)
+	e.origin = id
+	qa.addEntry(e)
+}
+
+// GetOrigin returns the subsection the entry of index i comes from. It is
+// empty if the set was not built from a topic.
+func (qa QuestionsAnswers) GetOrigin(i int) string {
+	return qa.origin[i]
+}
+
+// appendEntryFrom adds to the set the entry of index i of another set.
+func (qa *QuestionsAnswers) appendEntryFrom(from QuestionsAnswers, i int) {
+	qa.addEntry(from.entry(i))
 }
 
 // Concatenate adds the entries of the parameter to an existing QA set.
 func (qa *QuestionsAnswers) Concatenate(qaToAdd ...QuestionsAnswers) {
-	var count int
 	for _, toAdd := range qaToAdd {
-		count = toAdd.GetCount()
-		if count > 0 {
-			qa.questions = append(qa.questions, toAdd.questions...)
-			qa.answers = append(qa.answers, toAdd.answers...)
+		for i := 0; i < toAdd.GetCount(); i++ {
+			qa.appendEntryFrom(toAdd, i)
 		}
 	}
 }
@@ -254,123 +1289,1035 @@ func (topic Topic) BuildQuestionsSet(ids ...string) QuestionsAnswers {
 	var qaForId QuestionsAnswers
 	var subsections = ids
 	if len(subsections) == 0 {
-		fmt.Println("     *** You supplied no subsection, we take them all ***")
 		subsections = topic.GetSubsectionsName()
 	}
 	for _, id := range subsections {
-		qaForId = topic.GetSubsection(id)
-		qa.Concatenate(qaForId)
+		// Unknown ids are read from the map directly so that they are not
+		// added to the topic.
+		qaForId = topic.list[id]
+		for i := 0; i < qaForId.GetCount(); i++ {
+			qa.appendEntryFromSubsection(id, qaForId, i)
+		}
 	}
 
 	return qa
 }
 
-// fanOutChannel reads from the readFrom channel and dispatch the elements
-// to the writeTo channel. When reading from the readFrom channel breaks,
-// we write to the stopper channel the name of the channel from which we
-// cannot read anymore.
-func fanOutChannel(wg *sync.WaitGroup, readFrom <-chan string, writeTo chan<- string) {
-	defer wg.Done()
+// BuildQuestionsSet builds the set of questions of the subsections like
+// Topic.BuildQuestionsSet. In verbose mode, it tells when all the
+// subsections are taken because none was supplied.
+func (p InterrogationParameters) BuildQuestionsSet(topic Topic, ids ...string) QuestionsAnswers {
+	if len(ids) == 0 && p.IsVerbose() {
+		fmt.Fprintln(p.GetVerboseStream(), "*** You supplied no subsection, we take them all ***")
+	}
+	return topic.BuildQuestionsSet(ids...)
+}
 
-	for {
-		select {
-		case v, ok := <- readFrom:
-			if !ok {
-				return
+// ReverseSubsections returns a copy of the set where the entries of the
+// subsections are asked in reverse: their answer is the prompt.
+func (qa QuestionsAnswers) ReverseSubsections(ids ...string) QuestionsAnswers {
+	reversed := NewQA()
+	for i := 0; i < qa.GetCount(); i++ {
+		e := qa.entry(i)
+		for _, id := range ids {
+			if e.origin == id {
+				e.reversed = true
 			}
-			if len(v) != 0 {
-				writeTo <- v
+		}
+		reversed.addEntry(e)
+	}
+	return reversed
+}
+
+// BuildInterleavedSet creates a set of questions that takes the questions
+// of the subsections in rotation: the first question of each subsection,
+// then the second one of each subsection and so on. The subsections that
+// have no more questions are skipped. If no subsection is supplied, the
+// whole topic is used.
+func (topic Topic) BuildInterleavedSet(ids ...string) QuestionsAnswers {
+	subsections := ids
+	if len(subsections) == 0 {
+		subsections = topic.GetSubsectionsName()
+	}
+	qa := NewQA()
+	for rank, added := 0, true; added; rank++ {
+		added = false
+		for _, id := range subsections {
+			qaForId := topic.list[id]
+			if rank < qaForId.GetCount() {
+				qa.appendEntryFromSubsection(id, qaForId, rank)
+				added = true
 			}
 		}
 	}
+	return qa
+}
+
+const (
+	// repeatCommand is typed by the user in interactive mode to display the
+	// current question again without revealing the answer.
+	repeatCommand = "r"
+	// backCommand and backLongCommand are typed by the user in interactive
+	// mode to display the previous question and its answer.
+	backCommand     = "b"
+	backLongCommand = "back"
+	// flagCommand and flagLongCommand mark the current question for a later
+	// review. They do not reveal the answer.
+	flagCommand     = "f"
+	flagLongCommand = "flag"
+	// quitCommand and quitLongCommand stop the session. It can be resumed
+	// later with the -resume option.
+	quitCommand     = "q"
+	quitLongCommand = "quit"
+	// skipCommand and skipLongCommand go to the next question without
+	// revealing the answer. The question is not graded.
+	skipCommand     = "s"
+	skipLongCommand = "skip"
+	// hintCommand and hintLongCommand reveal the first letter of the answer.
+	hintCommand     = "h"
+	hintLongCommand = "hint"
+	// markCommand and markLongCommand flag the current question like
+	// flagCommand does.
+	markCommand     = "m"
+	markLongCommand = "mark"
+	// yesCommand and noCommand are typed by the user after the answer is
+	// revealed, in self-grading mode, to tell if it was known. A quality
+	// from 1 to 5 can be typed instead.
+	yesCommand = "y"
+	noCommand  = "n"
+	// commandPrefix starts the commands typed when the answers are graded,
+	// like :s or :hint, so that an answer like s or back can be given. It
+	// is optional in the other sessions.
+	commandPrefix = ":"
+)
+
+// sessionCommands are the commands recognized while a question is shown.
+var sessionCommands = []string{
+	repeatCommand, backCommand, backLongCommand, flagCommand, flagLongCommand,
+	quitCommand, quitLongCommand, skipCommand, skipLongCommand,
+	hintCommand, hintLongCommand, markCommand, markLongCommand,
+}
+
+// sessionCommand tells if the line typed by the user is a command and
+// returns it without its prefix. When the answers are graded, only the
+// lines starting with commandPrefix are commands.
+func (p InterrogationParameters) sessionCommand(line string) (string, bool) {
+	cmd := strings.TrimPrefix(line, commandPrefix)
+	if p.graded && cmd == line {
+		return "", false
+	}
+	for _, c := range sessionCommands {
+		if c == cmd {
+			return cmd, true
+		}
+	}
+	return "", false
+}
+
+// readCommands scans the user input and sends each line to the commands
+// channel. The channel is closed when the input is exhausted or when the
+// context is done. A line being read then is lost.
+func readCommands(ctx context.Context, in io.Reader, commands chan<- string) {
+	defer close(commands)
+	s := bufio.NewScanner(in)
+	for s.Scan() {
+		select {
+		case commands <- s.Text():
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// terminal writes the events of a session as text. Repeated questions are
+// not counted as items so that the loop banners stay aligned with the
+// questions set.
+type terminal struct {
+	out         *stickyWriter
+	r           rendering
+	c           *color.Color
+	qCount      int
+	maxLoops    int
+	failure     *outputFailure
+	started     bool   // the number of questions is written
+	itemsRead   int    // questions and answers written, the ones asked again excepted
+	currentLoop int    // number of the loop of the last question, from 1
+	column      int    // width of the last line of the question, where the answer starts
+	section     string // subsection of the last question
+	answered    bool   // an answer is written, waiting for its verdict before its separator
+	endsLoop    bool   // the answer waiting for its separator is the last one of its loop
+}
+
+// newTerminal returns the terminal writing to w the session of qCount
+// questions per loop. asked is the number of questions asked before, when
+// the session is resumed. The errors of w are reported through failure.
+func newTerminal(w io.Writer, qCount int, asked int, maxLoops int, r rendering, failure *outputFailure) *terminal {
+	c := color.New(colorThemes[r.theme]...)
+	if r.noColor {
+		c.DisableColor()
+	}
+	return &terminal{
+		out:         &stickyWriter{w: w},
+		r:           r,
+		c:           c,
+		qCount:      qCount,
+		maxLoops:    maxLoops,
+		failure:     failure,
+		itemsRead:   2 * asked,
+		currentLoop: (asked + qCount - 1) / qCount,
+	}
+}
+
+// render writes the event. It is the listener of the session.
+func (t *terminal) render(e Event) {
+	if t.out.err != nil {
+		// Nothing can be written anymore.
+		if e.Kind == SessionEnded {
+			t.r.endStatus()
+		}
+		return
+	}
+	if !t.started {
+		fmt.Fprintf(t.out, "Nb of questions: %d\n", t.qCount)
+		t.started = true
+	}
+	// The verdict comes after the answer, before its separator.
+	if e.Kind != AnswerGraded {
+		t.endAnswer()
+	}
+	switch e.Kind {
+	case LoopStarted:
+		t.currentLoop = e.Loop
+		if !t.r.loopFooter {
+			fmt.Fprint(t.out, t.c.Sprintf("Loop (%d/%d)\n", t.currentLoop, t.maxLoops))
+		}
+	case QuestionShown:
+		if e.Repeated {
+			fmt.Fprint(t.out, "\n")
+			t.column = t.r.writeQuestion(t.out, e.Text)
+			break
+		}
+		// The questions asked again are not part of the loops.
+		if !e.Again {
+			t.r.writeStatus(t.currentLoop, t.maxLoops, t.itemsRead/2+1)
+			t.itemsRead++
+		}
+		if t.r.showSection && len(e.Section) != 0 && e.Section != t.section {
+			fmt.Fprintf(t.out, "### %s\n", e.Section)
+		}
+		t.section = e.Section
+		t.column = t.r.writeQuestion(t.out, e.Text)
+	case AnswerRevealed:
+		if !e.Again {
+			t.itemsRead++
+		}
+		t.r.writeAnswer(t.out, e.Text, t.column)
+		if len(e.Echo) != 0 {
+			fmt.Fprintln(t.out, "You typed: "+e.Echo)
+		}
+		// The answer of the last question of the loop ends it.
+		t.answered = true
+		t.endsLoop = t.r.loopFooter && !e.Again && t.itemsRead%(2*t.qCount) == 0
+	case AnswerGraded:
+		fmt.Fprintln(t.out, e.Text)
+	case InfoShown:
+		if e.Aside {
+			fmt.Fprint(t.out, "\n"+e.Text)
+		} else {
+			fmt.Fprintln(t.out, e.Text)
+		}
+	case SessionEnded:
+		t.r.endStatus()
+		if t.itemsRead >= 2*t.qCount*t.maxLoops {
+			fmt.Fprintf(t.out, "Limit reached. Exiting. Number of loops set to: %d\n", t.maxLoops)
+		}
+	}
+	if t.out.err != nil {
+		t.failure.set(t.out.err)
+	}
+}
+
+// endAnswer writes the separator after the last answer and its verdict,
+// once, and the end of the loop if the answer ends it.
+func (t *terminal) endAnswer() {
+	if !t.answered {
+		return
+	}
+	t.answered = false
+	fmt.Fprint(t.out, "---------------------------\n")
+	if t.endsLoop {
+		fmt.Fprint(t.out, t.c.Sprintf("End of loop %d/%d\n", t.currentLoop, t.maxLoops))
+	}
+}
+
+// answerArrow introduces the answer after the question.
+const answerArrow = "     --> "
+
+// rendering holds the options of the display of the questions.
+type rendering struct {
+	width       int       // the width of the lines. 0 means no wrapping
+	noColor     bool      // the output is not colored
+	theme       string    // the name of the colors of the output, among colorThemes
+	showSection bool      // a header is written when the subsection of the questions changes
+	loopFooter  bool      // the loop is announced at its end instead of its start
+	status      io.Writer // where the status line is written. nil means no status line
+	overwrite   bool      // the status line is updated in place
+}
+
+// rendering returns the display options chosen by the user.
+func (p InterrogationParameters) rendering() rendering {
+	return rendering{
+		width:       p.width,
+		noColor:     p.noColor,
+		theme:       p.GetTheme(),
+		showSection: p.showSection,
+		loopFooter:  p.loopFooter,
+		status:      p.progress,
+		overwrite:   isTerminal(p.progress),
+	}
+}
+
+// isTerminal tells if the writer is a terminal, where a line can be
+// rewritten with a carriage return.
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// writeStatus updates the status line with the loop and the number of the
+// question asked.
+func (r rendering) writeStatus(loop int, maxLoops int, question int) {
+	if r.status == nil {
+		return
+	}
+	if r.overwrite {
+		fmt.Fprintf(r.status, "\rLoop %d/%d, question %d", loop, maxLoops, question)
+		return
+	}
+	fmt.Fprintf(r.status, "Loop %d/%d, question %d\n", loop, maxLoops, question)
+}
+
+// endStatus leaves the status line once the session is over.
+func (r rendering) endStatus() {
+	if r.status != nil && r.overwrite {
+		fmt.Fprintln(r.status)
+	}
+}
+
+// writeQuestion writes the question, wrapped to the width, without going to
+// the next line so that the answer can follow. It returns the width of the
+// last line.
+func (r rendering) writeQuestion(out io.Writer, question string) int {
+	lines := wrapText(question, r.width, r.width)
+	fmt.Fprint(out, strings.Join(lines, "\n"))
+	return utf8.RuneCountInString(lines[len(lines)-1])
+}
+
+// writeAnswer writes the answer after the question whose last line has the
+// given width. The answer is wrapped to the width and its continuation
+// lines are indented under the arrow. The lines of an answer written on
+// several lines in the deck are kept, as a block under the arrow.
+func (r rendering) writeAnswer(out io.Writer, answer string, column int) {
+	indent := strings.Repeat(" ", utf8.RuneCountInString(answerArrow))
+	first, next := 0, 0
+	if r.width > 0 {
+		next = r.width - len(indent)
+		first = next - column
+		if first < 1 {
+			// No room left after the question: the answer starts on its
+			// own line.
+			fmt.Fprint(out, "\n")
+			first = next
+		}
+	}
+	var lines []string
+	for n, paragraph := range strings.Split(answer, "\n") {
+		if n > 0 {
+			first = next
+		}
+		lines = append(lines, wrapText(paragraph, first, next)...)
+	}
+	fmt.Fprint(out, answerArrow+strings.Join(lines, "\n"+indent)+"\n")
+}
+
+// stickyWriter remembers the first error met while writing. The following
+// writes are not attempted.
+type stickyWriter struct {
+	w   io.Writer
+	err error
 }
 
-// 
-func publishChanToWriter(wg *sync.WaitGroup, readFrom <-chan string, out io.Writer, qCount int, maxLoops int) {
-	defer wg.Done()
-	itemsRead := 0
-	currentLoop := 0
-	c := color.New(color.FgBlue).Add(color.Bold)
+func (sw *stickyWriter) Write(b []byte) (int, error) {
+	if sw.err != nil {
+		return 0, sw.err
+	}
+	n, err := sw.w.Write(b)
+	sw.err = err
+	return n, err
+}
+
+// outputFailure tells the session that the terminal cannot write to the
+// output anymore, for instance because the pipe was closed by the reader.
+// The failed channel is closed when it happens.
+type outputFailure struct {
+	failed chan struct{}
+	err    error
+}
+
+func newOutputFailure() *outputFailure {
+	return &outputFailure{failed: make(chan struct{})}
+}
+
+// set records the error the first time it is called.
+func (f *outputFailure) set(err error) {
+	if f.err == nil {
+		f.err = err
+		close(f.failed)
+	}
+}
+
+// hasFailed tells, without blocking, if the output failed.
+func (f *outputFailure) hasFailed() bool {
+	select {
+	case <-f.failed:
+		return true
+	default:
+		return false
+	}
+}
 
-	fmt.Fprintf(out, "Nb of questions: %d\n", qCount)
+// shownCard is a question as it was displayed to the user.
+type shownCard struct {
+	index  int // index of the entry in the questions set
+	prompt string
+	answer string
+	hint   string // revealed by the hint command. Empty means the first letter of the answer
+}
 
+// waitForAnswer blocks until the user asks for the answer. In the meantime,
+// the commands that do not reveal the answer are processed. When the auto
+// advance is set, the answer is revealed if the user does not answer in
+// time. It returns what the user typed, the command without its prefix when
+// the line is the quit or the skip command, and false when there is no more
+// input to read from, when the timer fired or when the output failed. The
+// cards flagged by the user are recorded in the result.
+func (s *Session) waitForAnswer(ctx context.Context, current shownCard, previous *shownCard, result *SessionResult) (given string, cmd string, ok bool) {
 	for {
-		if itemsRead%(2*qCount) == 0 {
-			currentLoop++
-			if currentLoop > maxLoops {
-				fmt.Fprintf(out, "Limit reached. Exiting. Number of loops set to: %d\n",maxLoops)
-				return
-			}
-			fmt.Fprintf(out, c.Sprintf("Loop (%d/%d)\n", currentLoop, maxLoops))
+		var timeout <-chan time.Time
+		if s.p.autoAdvance > 0 {
+			timeout = s.p.clock.After(s.p.autoAdvance)
 		}
 		select {
-		case v, ok := <- readFrom:
+		case line, ok := <-s.commands:
 			if !ok {
-				return
+				return "", "", false
 			}
-			itemsRead++
-			switch {
-			case itemsRead%2==1:
-				fmt.Fprintf(out, v)
-				// Questions asked. Must publish the answer now.
-			case itemsRead%2==0:
-				fmt.Fprintf(out, "     --> " +v+"\n")
-				fmt.Fprintf(out, "---------------------------\n")
+			cmd, isCommand := s.p.sessionCommand(line)
+			if !isCommand {
+				return line, "", true
+			}
+			switch cmd {
+			case repeatCommand:
+			case backCommand, backLongCommand:
+				back := "No previous question"
+				if previous != nil {
+					back = "Previous: " + previous.prompt + "     --> " + previous.answer
+				}
+				s.emit(Event{Kind: InfoShown, Text: back, Aside: true})
+			case hintCommand, hintLongCommand:
+				text := current.hint
+				if text == "" {
+					text = hint(current.answer)
+				}
+				s.emit(Event{Kind: InfoShown, Text: "Hint: " + text, Aside: true})
+			case flagCommand, flagLongCommand, markCommand, markLongCommand:
+				result.addFlagged(current.index)
+				s.emit(Event{Kind: InfoShown, Text: "Flagged for review", Aside: true})
+			default:
+				return line, cmd, true
+			}
+			s.emit(Event{Kind: QuestionShown, Text: current.prompt, Repeated: true})
+		case <-timeout:
+			// The user may have pressed Return just as the timer fired. This
+			// input was meant for this question: it must not reveal the answer
+			// of the next one.
+			select {
+			case <-s.commands:
+			default:
+			}
+			return "", "", false
+		case <-s.failure.failed:
+			return "", "", false
+		case <-ctx.Done():
+			return "", "", false
+		}
+	}
+}
+
+// skippedAnswer is shown instead of the answer of a question skipped.
+const skippedAnswer = "(skipped)"
+
+// hint returns the first letter of the answer followed by an ellipsis. The
+// spaces before it are ignored.
+func hint(answer string) string {
+	for _, r := range strings.TrimSpace(answer) {
+		return string(r) + "..."
+	}
+	return "(empty answer)"
+}
+
+// SessionResult sums up what happened during a session of questions.
+type SessionResult struct {
+	Asked   int // number of questions asked to the user
+	Graded  int // number of answers that were graded
+	Correct int // number of graded answers that were correct
+	// Credit is the sum of the scores, from 0 to 1, of the graded answers.
+	// An answer that gives only some of the required parts of a question
+	// scores the fraction of the parts given.
+	Credit  float64
+	Wrong   []int // indexes in the questions set of the wrong answers, each one once
+	Right   []int // indexes in the questions set of the correct answers, each one once
+	Flagged []int // indexes in the questions set of the questions flagged for review, each one once
+	Seen    []int // indexes in the questions set of the questions shown, each one once
+	// ResponseTime is the time taken by the user to answer the questions,
+	// in interactive mode.
+	ResponseTime time.Duration
+	// Answers holds the time taken to answer each question, in the order
+	// they were asked, in interactive mode.
+	Answers []TimedAnswer
+	// Duration is the time the session lasted.
+	Duration time.Duration
+	// LoopDurations holds the time taken by each full loop on the questions.
+	LoopDurations []time.Duration
+	Err           error // the error that stopped the session early, if any
+	// Subsections holds the questions asked and the graded answers per
+	// subsection, in the order the subsections were met.
+	Subsections []SubsectionResult
+	// Quit tells that the user stopped the session with the quit command,
+	// Interrupted that the context of the session was done. Position is
+	// then the number of questions asked since the start of the session,
+	// including the ones of the session resumed, if any.
+	Quit        bool
+	Interrupted bool
+	Position    int
+	// Choices holds, in the multiple choice mode, the number of the answer
+	// expected and the answer typed for each question graded, in the order
+	// they were asked.
+	Choices []ChoiceAnswer
+}
+
+// ChoiceAnswer is the answer typed to the question of index Index in the
+// questions set, asked in the multiple choice mode. Key is the number of
+// the answer expected in the list, from 1.
+type ChoiceAnswer struct {
+	Index int
+	Key   int
+	Given string
+}
+
+// SubsectionResult counts the questions asked and the graded answers of a
+// subsection.
+type SubsectionResult struct {
+	Name    string
+	Asked   int
+	Graded  int
+	Correct int
+}
+
+// TimedAnswer is the time taken by the user to answer the question of
+// index Index in the questions set.
+type TimedAnswer struct {
+	Index int
+	Time  time.Duration
+}
+
+// Add accumulates the result of another session to this one.
+func (r *SessionResult) Add(other SessionResult) {
+	r.Asked += other.Asked
+	r.Graded += other.Graded
+	r.Correct += other.Correct
+	r.Credit += other.Credit
+	for _, s := range other.Subsections {
+		sub := r.subsection(s.Name)
+		sub.Asked += s.Asked
+		sub.Graded += s.Graded
+		sub.Correct += s.Correct
+	}
+}
+
+// subsection returns the result of the subsection, adding it if needed.
+func (r *SessionResult) subsection(name string) *SubsectionResult {
+	for i := range r.Subsections {
+		if r.Subsections[i].Name == name {
+			return &r.Subsections[i]
+		}
+	}
+	r.Subsections = append(r.Subsections, SubsectionResult{Name: name})
+	return &r.Subsections[len(r.Subsections)-1]
+}
+
+// addGraded records a graded answer to a question of the subsection. The
+// score goes from 0 to 1, the answer being correct only for 1.
+func (r *SessionResult) addGraded(subsection string, score float64) {
+	r.Graded++
+	r.Credit += score
+	sub := r.subsection(subsection)
+	sub.Graded++
+	if score == 1 {
+		r.Correct++
+		sub.Correct++
+	}
+}
+
+// SubsectionsBreakdown returns the score of each subsection, like
+// "Lesson 1: 8/10, Lesson 2: 5/12".
+func (r SessionResult) SubsectionsBreakdown() string {
+	scores := make([]string, 0, len(r.Subsections))
+	for _, s := range r.Subsections {
+		scores = append(scores, fmt.Sprintf("%s: %d/%d", s.Name, s.Correct, s.Graded))
+	}
+	return strings.Join(scores, ", ")
+}
+
+// addWrong records that the question of index i was not answered correctly.
+func (r *SessionResult) addWrong(i int) {
+	r.Wrong = addIndex(r.Wrong, i)
+}
+
+// addSeen records that the question of index i was shown to the user.
+func (r *SessionResult) addSeen(i int) {
+	r.Seen = addIndex(r.Seen, i)
+}
+
+// addFlagged records that the question of index i was flagged for review.
+func (r *SessionResult) addFlagged(i int) {
+	r.Flagged = addIndex(r.Flagged, i)
+}
+
+// addRight records that the question of index i was answered correctly.
+func (r *SessionResult) addRight(i int) {
+	r.Right = addIndex(r.Right, i)
+}
+
+// addIndex appends i to the indexes unless it is already there.
+func addIndex(indexes []int, i int) []int {
+	for _, index := range indexes {
+		if index == i {
+			return indexes
+		}
+	}
+	return append(indexes, i)
+}
+
+// Score returns the score of the graded answers like "3/5 (60%)". The
+// percentage includes the partial credit of the answers.
+func (r SessionResult) Score() string {
+	return fmt.Sprintf("%d/%d (%d%%)", r.Correct, r.Graded, int(r.percentage()))
+}
+
+// percentage returns the percentage of the score, 0 when no answer was
+// graded.
+func (r SessionResult) percentage() float64 {
+	if r.Graded == 0 {
+		return 0
+	}
+	return 100 * r.Credit / float64(r.Graded)
+}
+
+// AverageResponseTime returns the average time taken by the user to answer
+// a question. It is 0 when the answers were not typed.
+func (r SessionResult) AverageResponseTime() time.Duration {
+	if r.Asked == 0 {
+		return 0
+	}
+	return r.ResponseTime / time.Duration(r.Asked)
+}
+
+// isCorrect tells if the answer given by the user matches the expected one.
+// Case, spaces and typographic characters are not significant: the
+// answers are compared once normalized by normalizeText.
+func isCorrect(given string, expected string) bool {
+	return given == expected || strings.EqualFold(normalizeText(given), normalizeText(expected))
+}
+
+// isCorrectAlternative tells if the answer given by the user matches one of
+// the acceptable answers.
+func isCorrectAlternative(given string, alternatives []string) bool {
+	for _, alternative := range alternatives {
+		if isCorrect(given, alternative) {
+			return true
+		}
+	}
+	return false
+}
+
+// partialScore returns the fraction, from 0 to 1, of the required parts
+// found in the answer given by the user. The parts are given separated by
+// commas, in any order, and compared like isCorrect does.
+func partialScore(given string, parts []string) float64 {
+	found := make([]bool, len(parts))
+	matched := 0
+	for _, g := range strings.Split(given, ",") {
+		for n, part := range parts {
+			if !found[n] && isCorrect(g, part) {
+				found[n] = true
+				matched++
+				break
 			}
 		}
 	}
+	return float64(matched) / float64(len(parts))
 }
 
+// diffAnswer compares character by character the answer given by the user
+// to the expected one, with the same tolerance as isCorrect. The characters
+// typed that do not match are put between brackets, the missing ones are
+// shown as [_]. An answer that matches is returned unchanged.
+func diffAnswer(given string, expected string) string {
+	got := []rune(strings.TrimSpace(given))
+	want := []rune(strings.TrimSpace(expected))
+	var b strings.Builder
+	for i := 0; i < len(got) || i < len(want); i++ {
+		switch {
+		case i >= len(got):
+			b.WriteString("[_]")
+		case i >= len(want) || !strings.EqualFold(string(got[i]), string(want[i])):
+			b.WriteString("[" + string(got[i]) + "]")
+		default:
+			b.WriteRune(got[i])
+		}
+	}
+	return b.String()
+}
 
 // AskQuestions will question the user on the set of questions. The
 // parameter object will supply data to refine the questioning.
-func AskQuestions(qa QuestionsAnswers, p InterrogationParameters) {
+func AskQuestions(qa QuestionsAnswers, p InterrogationParameters) SessionResult {
+	return AskQuestionsContext(context.Background(), qa, p)
+}
+
+// AskQuestionsContext is AskQuestions stopping when the context is done,
+// for instance when the user presses Ctrl-C. The session then ends like
+// when the user quits, with Interrupted set in the result.
+func AskQuestionsContext(ctx context.Context, qa QuestionsAnswers, p InterrogationParameters) SessionResult {
+	return askQuestions(ctx, qa, p, make(chan string))
+}
+
+// askQuestions runs the session of AskQuestions on a Session whose events
+// are written to the output of the parameters. The commands of the user are
+// read from the channel, and from the input of the parameters if any.
+func askQuestions(ctx context.Context, qa QuestionsAnswers, p InterrogationParameters, commands chan string) SessionResult {
+	s := newSession(qa, p, commands)
+	s.Listen(newTerminal(p.GetOutputStream(), p.loopLength(qa), p.start, p.limit, p.rendering(), s.failure).render)
+	return s.Run(ctx)
+}
+
+// ask runs the session, giving what happens to the listeners as events,
+// until the end of the session or until the context is done.
+func (s *Session) ask(ctx context.Context) SessionResult {
+	qa, p := s.qa, &s.p
 	fullLoop, i, j := 0, 0, 0
 
-	var wg sync.WaitGroup
-	wg.Add(3)
-	nbOfQuestions := qa.GetCount()
+	if p.clock == nil {
+		p.clock = systemClock{}
+	}
+	if p.rng == nil {
+		p.setSeed(newSeed())
+	}
 
-	go fanOutChannel(&wg, p.qachan, p.publisher)
-	go publishChanToWriter(&wg, p.publisher, p.GetOutputStream(), nbOfQuestions, p.limit)
-  go fanOutChannel(&wg, p.command, p.publisher)
+	nbOfQuestions := p.loopLength(qa)
+	quota := p.newLoopQuota(qa)
+	// The next card in linear order. The pinned cards asked first do not
+	// move it.
+	cursor := 0
 
-	var question, answer string
-	s := bufio.NewScanner(p.in)
+	// A resumed session draws the cards already asked again, without
+	// asking them, so that the random sequence continues where it stopped.
+	for j < p.start {
+		if j%nbOfQuestions == 0 {
+			quota.startLoop(j / nbOfQuestions)
+		}
+		var answer string
+		var swapped bool
+		i, _, answer, swapped = p.pickCard(qa, cursor, quota)
+		if p.choice {
+			qa.multipleChoice(i, answer, swapped, p.distractors, p.fixChoices, p.rng)
+		}
+		if p.mode == linear && !quota.pinned[i] {
+			cursor = (i + 1) % qa.GetCount()
+		}
+		j++
+	}
+	fullLoop = (j + nbOfQuestions - 1) / nbOfQuestions
+
+	// A nil input means that the commands are already read from elsewhere.
+	// The reader stops with the session instead of waiting for a line
+	// nobody reads.
+	if p.readsCommands() && p.in != nil {
+		reading, stopReading := context.WithCancel(ctx)
+		defer stopReading()
+		go readCommands(reading, p.in, s.commands)
+	}
+
+	var result SessionResult
+	started := p.clock.Now()
+	var question, answer, verdict, echo string
+	var previous *shownCard
+	// The cards graded low by the user, asked again at the end.
+	var again []shownCard
+	loopStart := p.clock.Now()
+	// A resumed session does not time the loop it starts in the middle of.
+	timed := p.start%nbOfQuestions == 0
 	for {
+		if s.failure.hasFailed() {
+			break
+		}
+		if ctx.Err() != nil {
+			result.Interrupted = true
+			result.Position = j
+			s.sendScore(result)
+			s.sendFlagged(result)
+			break
+		}
 		if j%nbOfQuestions == 0 {
+			now := p.clock.Now()
+			if j > p.start && timed {
+				took := now.Sub(loopStart)
+				result.LoopDurations = append(result.LoopDurations, took)
+				if p.timeLoops {
+					s.emit(Event{Kind: InfoShown, Text: fmt.Sprintf("Loop %d took %.1fs", fullLoop, took.Seconds())})
+				}
+			}
+			loopStart, timed = now, true
+			quota.startLoop(j / nbOfQuestions)
 			fullLoop++
 			if fullLoop > p.limit {
-				// if the qa chan is closed, then we have to close the others.
-				close(p.qachan)
-				close(p.command)
+				s.askAgain(ctx, again, &result)
+				s.sendScore(result)
+				s.sendFlagged(result)
 				break
 			}
+			s.emit(Event{Kind: LoopStarted, Loop: fullLoop})
 		}
-		if p.mode == random {
-			i = int(rand.Int31n(int32(nbOfQuestions)))
+		var swapped bool
+		i, question, answer, swapped = p.pickCard(qa, cursor, quota)
+		var key int
+		if p.choice {
+			var options []string
+			options, key = qa.multipleChoice(i, answer, swapped, p.distractors, p.fixChoices, p.rng)
+			question = choicePrompt(question, options)
 		}
-		question = qa.questions[i]
-		answer = qa.answers[i]
-		if p.IsReversedMode() {
-			question = qa.answers[i]
-			answer = qa.questions[i]
+		s.emit(Event{Kind: QuestionShown, Text: question, Section: qa.origin[i]})
+		result.addSeen(i)
+		if p.PlaybackHook != nil && len(qa.media[i]) != 0 {
+			p.PlaybackHook(qa.media[i])
 		}
-		p.qachan <- fmt.Sprintf("%s", question)
+		verdict, echo = "", ""
+		revealed, skipped, correct := answer, false, false
 		if p.interactive {
-			if s.Scan() {
-				p.command <- s.Text()
+			shownAt := p.clock.Now()
+			card := shownCard{index: i, prompt: question, answer: answer}
+			if !swapped {
+				card.hint = qa.hints[i]
+			}
+			given, cmd, _ := s.waitForAnswer(ctx, card, previous, &result)
+			interrupted := ctx.Err() != nil
+			if interrupted || cmd == quitCommand || cmd == quitLongCommand {
+				// The question shown is not counted: it is asked again when
+				// the session is resumed.
+				result.Quit, result.Interrupted = !interrupted, interrupted
+				result.Position = j
+				s.sendScore(result)
+				s.sendFlagged(result)
+				break
+			}
+			took := p.clock.Now().Sub(shownAt)
+			result.ResponseTime += took
+			result.Answers = append(result.Answers, TimedAnswer{Index: i, Time: took})
+			if cmd == skipCommand || cmd == skipLongCommand {
+				// The answer is not revealed and the question is not graded.
+				revealed, skipped = skippedAnswer, true
+			}
+			if p.echo && !skipped {
+				echo = diffAnswer(given, answer)
+			}
+			if p.graded && !skipped {
+				if p.choice {
+					correct = isChosen(given, key)
+					result.Choices = append(result.Choices, ChoiceAnswer{Index: i, Key: key + 1, Given: given})
+				} else {
+					match := p.matchOptions()
+					correct = CompareAnswers(answer, given, match)
+					if !swapped {
+						correct = correct || match.matchesAny(given, qa.alternatives[i])
+					}
+				}
+				score := 0.0
+				if correct {
+					score = 1
+				}
+				if p.partialCredit && !p.choice && !swapped && len(qa.alternatives[i]) > 1 {
+					score = partialScore(given, qa.alternatives[i])
+					correct = score == 1
+				}
+				result.addGraded(qa.origin[i], score)
+				verdict = "Wrong"
+				if score > 0 && !correct {
+					verdict = fmt.Sprintf("Partially correct (%.0f%%)", 100*score)
+				}
+				if correct {
+					verdict = "Correct"
+					result.addRight(i)
+				} else {
+					result.addWrong(i)
+				}
+				if p.Stats != nil {
+					p.Stats.answered(qa.questions[i], correct)
+				}
 			}
 		} else {
-			time.Sleep(p.wait)
+			p.waitUnattended(ctx, s.commands)
+		}
+		if p.Stats != nil && !skipped {
+			p.Stats.seen(qa.questions[i], p.clock.Now())
 		}
-		p.qachan <- fmt.Sprintf("%s", answer)
+		s.emit(Event{Kind: AnswerRevealed, Text: revealed, Echo: echo})
+		if len(verdict) != 0 {
+			s.emit(Event{Kind: AnswerGraded, Text: verdict, Correct: correct})
+		}
+		if len(qa.notes[i]) != 0 && !skipped {
+			s.emit(Event{Kind: InfoShown, Text: "Note: " + qa.notes[i]})
+		}
+		result.subsection(qa.origin[i]).Asked++
+		current := shownCard{index: i, prompt: question, answer: answer}
+		if p.selfGrade && !p.graded && !skipped {
+			quality, cmd, ok := s.selfGrade(ctx, current, previous, &result)
+			if cmd == quitCommand || cmd == quitLongCommand {
+				// The answer was revealed: the session is resumed after it.
+				j++
+				result.Quit = true
+				result.Position = j
+				s.sendScore(result)
+				s.sendFlagged(result)
+				break
+			}
+			if ok {
+				known := quality >= passingQuality
+				if known {
+					result.addGraded(qa.origin[i], 1)
+					result.addRight(i)
+				} else {
+					result.addGraded(qa.origin[i], 0)
+					result.addWrong(i)
+					again = append(again, current)
+				}
+				if p.Stats != nil {
+					p.Stats.answered(qa.questions[i], known)
+				}
+			}
+		}
+		previous = &current
 
-		if p.mode == linear {
-			i = (i + 1) % nbOfQuestions
+		if p.mode == linear && !quota.pinned[i] {
+			cursor = (i + 1) % qa.GetCount()
 		}
 		j++
 	}
 
-	wg.Wait()
+	result.Duration = p.clock.Now().Sub(started)
+	result.Asked = j - p.start
+	result.Err = s.failure.err
+	return result
+}
+
+// waitGoroutines waits for the goroutines of the session to finish. If they
+// are stuck, a warning is written to the verbose stream after the shutdown
+// wait and the session ends anyway, instead of hanging forever. The wait is
+// measured with the real time: the clock of the parameters may be a fake one
+// that fires its timers at once.
+func waitGoroutines(wg *sync.WaitGroup, p InterrogationParameters) {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	wait := p.shutdownWait
+	if wait == 0 {
+		wait = defaultShutdownWait
+	}
+	select {
+	case <-done:
+	case <-time.After(wait):
+		if out := p.GetVerboseStream(); out != nil {
+			fmt.Fprintf(out, "Warning: the output of the session did not finish within %v, leaving anyway.\n", wait)
+		}
+	}
+}
+
+// sendScore writes the score of the answers graded during the session,
+// and the one of each subsection if there are several.
+func (s *Session) sendScore(result SessionResult) {
+	if !s.p.graded && !s.p.selfGrade {
+		return
+	}
+	s.emit(Event{Kind: InfoShown, Text: "Score: " + result.Score()})
+	if len(result.Subsections) > 1 {
+		s.emit(Event{Kind: InfoShown, Text: "Per subsection: " + result.SubsectionsBreakdown()})
+	}
+}
+
+// sendFlagged lists the questions flagged for review during the session.
+func (s *Session) sendFlagged(result SessionResult) {
+	if len(result.Flagged) == 0 {
+		return
+	}
+	s.emit(Event{Kind: InfoShown, Text: "Flagged for review:"})
+	for _, i := range result.Flagged {
+		s.emit(Event{Kind: InfoShown, Text: "  " + s.qa.questions[i] + "     --> " + s.qa.answers[i]})
+	}
+}
+
+// pickCard picks the card asked after the one of index i, in the order of
+// the mode, and the way it is shown. It returns the index of the card, the
+// prompt, the answer expected and if the prompt is the answer column. The
+// pinned cards come first in each loop, then the cards the quota does not
+// allow in the loop are skipped.
+func (p InterrogationParameters) pickCard(qa QuestionsAnswers, i int, quota *loopQuota) (int, string, string, bool) {
+	if pinned, found := quota.nextPinned(); found {
+		i = pinned
+	} else if p.mode == shuffle {
+		i = quota.nextInBag(p.rng)
+	} else if p.mode == random {
+		i = p.rng.Intn(qa.GetCount())
+		for !quota.allows(i, false) {
+			i = p.rng.Intn(qa.GetCount())
+		}
+	} else {
+		// When the pinned cards fill the quota, the cycle would never end.
+		for n := 0; n < qa.GetCount() && !quota.allows(i, true); n++ {
+			i = (i + 1) % qa.GetCount()
+		}
+	}
+	quota.add(i)
+	question := qa.questions[i]
+	answer := qa.answers[i]
+	if p.rotate && len(qa.alternatives[i]) > 1 {
+		answer = qa.alternatives[i][p.rng.Intn(len(qa.alternatives[i]))]
+	}
+	swapped := p.isPromptSwapped() != qa.reversed[i]
+	if p.mix {
+		swapped = pickSwapped(p.rng)
+	}
+	if qa.IsCloze(i) {
+		// The blank of a cloze card is meaningless as an answer.
+		swapped = false
+	}
+	if swapped {
+		question, answer = answer, question
+	}
+	return i, question, answer, swapped
 }
@@ -2,22 +2,30 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"testing/iotest"
 	"time"
 )
 
 var (
-	emptyLine, _ = regexp.Compile("^\\s*$")
-	loop, _ = regexp.Compile("^Loop\\s{1,}\\([0-9]{1,}/[0-9]{1,}\\)$")
-	separator, _ = regexp.Compile("^-{1,}")
+	emptyLine, _     = regexp.Compile("^\\s*$")
+	loop, _          = regexp.Compile("^Loop\\s{1,}\\([0-9]{1,}/[0-9]{1,}\\)$")
+	separator, _     = regexp.Compile("^-{1,}")
 	nbOfQuestions, _ = regexp.Compile("^Nb of questions:\\s[0-9]{1,}")
-	limitReached, _ = regexp.Compile("^Limit reached. Exiting. Number of loops set to:\\s[0-9]{1,}")
+	limitReached, _  = regexp.Compile("^Limit reached. Exiting. Number of loops set to:\\s[0-9]{1,}")
 )
 
 // TestAddEntry is testing not only the AddEntry function but the GetCount
@@ -68,15 +76,15 @@ func TestNewTopic(t *testing.T) {
 
 // TestParsing validates the parsing of the command line.
 func TestParsingEmptyParameters(t *testing.T) {
-	p, err := Parse()
+	p, err := parse(noEnvironment)
 	if err != nil {
 		t.Errorf("Parsing should not fail with empty parameters")
 	}
 	if p.interactive {
 		t.Errorf("Default is to be in non interactive. But the parameters says the contrary.")
 	}
-	if p.wait != 2*time.Second {
-		t.Errorf("Default is to wait for 2 seconds. But the current value is %v.\n", p.wait)
+	if p.minWait != 2*time.Second || p.maxWait != 2*time.Second {
+		t.Errorf("Default is to wait for 2 seconds. But the current value is %v-%v.\n", p.minWait, p.maxWait)
 	}
 }
 
@@ -85,15 +93,15 @@ func TestParsingEmptyParameters(t *testing.T) {
 func TestParsingNonEmptyParameters(t *testing.T) {
 	wt := 1500
 	arguments := []string{"-i", "-t", strconv.Itoa(wt)}
-	p, err := Parse(arguments[:]...)
+	p, err := parse(noEnvironment, arguments[:]...)
 	if err != nil {
 		t.Errorf("A valid list of parameters must not trigger a parsing error.")
 	}
 	if !p.interactive {
 		t.Errorf("The parameter -i was not detected.")
 	}
-	if p.wait != time.Duration(wt)*time.Millisecond {
-		t.Errorf("Failed to detect wait time as %dms. Found %v instead.\n", wt, p.wait)
+	if p.minWait != time.Duration(wt)*time.Millisecond || p.maxWait != p.minWait {
+		t.Errorf("Failed to detect wait time as %dms. Found %v-%v instead.\n", wt, p.minWait, p.maxWait)
 	}
 }
 
@@ -101,7 +109,7 @@ func TestParsingNonEmptyParameters(t *testing.T) {
 func TestParsingSelectedTopics(t *testing.T) {
 	selected := "Topic 1,Topic 2"
 	arguments := []string{"-l", selected}
-	p, err := Parse(arguments[:]...)
+	p, err := parse(noEnvironment, arguments[:]...)
 	if err != nil {
 		t.Errorf("Parsing detects list of selected topics as an error")
 	}
@@ -118,7 +126,7 @@ func TestParsingSelectedTopics(t *testing.T) {
 // specific topics, the array in nil.
 func TestNoSelectedTopicsReturnsNil(t *testing.T) {
 	arguments := []string{}
-	p, err := Parse(arguments[:]...)
+	p, err := parse(noEnvironment, arguments[:]...)
 	if err != nil {
 		t.Errorf("Passing no argument make the parsing fail")
 	}
@@ -130,7 +138,7 @@ func TestNoSelectedTopicsReturnsNil(t *testing.T) {
 // TestParsingReverseMode checks that reverse mode is detected and works.
 func TestParsingReverseMode(t *testing.T) {
 	arguments := []string{"-r"}
-	p, err := Parse(arguments[:]...)
+	p, err := parse(noEnvironment, arguments[:]...)
 	if err != nil {
 		t.Errorf("Parsing detects reverse mode as an error")
 	}
@@ -142,7 +150,7 @@ func TestParsingReverseMode(t *testing.T) {
 // TestParsingSummaryMode checks that the feature about the parameter summary works fine.
 func TestParsingSummaryMode(t *testing.T) {
 	arguments := []string{"-s"}
-	p, err := Parse(arguments[:]...)
+	p, err := parse(noEnvironment, arguments[:]...)
 	if err != nil {
 		t.Errorf("Parsing detects summary mode as an error")
 	}
@@ -156,7 +164,7 @@ func TestParsingSummaryMode(t *testing.T) {
 
 func TestDetectingLinearMode(t *testing.T) {
 	arguments := []string{"-m", "linear"}
-	p, err := Parse(arguments[:]...)
+	p, err := parse(noEnvironment, arguments[:]...)
 	if err != nil {
 		t.Errorf("Parsing detects linear mode as an error")
 	}
@@ -165,9 +173,39 @@ func TestDetectingLinearMode(t *testing.T) {
 	}
 }
 
+// TestParsingWaitRange checks that a range of wait times is accepted and
+// that the waits picked stay within the range.
+func TestParsingWaitRange(t *testing.T) {
+	arguments := []string{"-t", "1500-3000"}
+	p, err := parse(noEnvironment, arguments[:]...)
+	if err != nil {
+		t.Errorf("A valid range of wait times must not trigger a parsing error: %v", err)
+	}
+	if p.minWait != 1500*time.Millisecond || p.maxWait != 3000*time.Millisecond {
+		t.Errorf("Failed to detect the range 1500ms-3000ms. Found %v-%v instead.\n", p.minWait, p.maxWait)
+	}
+	p.rng = rand.New(rand.NewSource(1))
+	for i := 0; i < 100; i++ {
+		wait := p.nextWait()
+		if wait < p.minWait || wait > p.maxWait {
+			t.Errorf("The wait %v is out of the range %v-%v\n", wait, p.minWait, p.maxWait)
+		}
+	}
+}
+
+// TestParsingMalformedWaitRange checks that the malformed ranges are reported.
+func TestParsingMalformedWaitRange(t *testing.T) {
+	for _, value := range []string{"3000-1500", "1500-", "-1500", "1500-3000-4000", "a-b"} {
+		_, err := parse(noEnvironment, "-t", value)
+		if err == nil {
+			t.Errorf("The malformed range '%s' is not detected.", value)
+		}
+	}
+}
+
 func TestErrorParsing(t *testing.T) {
 	arguments := []string{"-t", "15aaa"}
-	_, err := Parse(arguments[:]...)
+	_, err := parse(noEnvironment, arguments[:]...)
 	if err == nil {
 		t.Errorf("We do not detect when a time is not an integer.")
 	}
@@ -192,7 +230,7 @@ func getSampleCsvAsStream() string {
 }
 
 func getTpp() TopicParsingParameters {
-	return TopicParsingParameters {
+	return TopicParsingParameters{
 		TopicAnnounce: "### Lesson ",
 		QaSep:         ";",
 	}
@@ -226,15 +264,160 @@ func TestParseStream(t *testing.T) {
 
 }
 
+// TestTopicEqual checks the comparison of topics.
+func TestTopicEqual(t *testing.T) {
+	topic := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp())
+	same := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp())
+	if !topic.Equal(same) {
+		t.Errorf("Topics parsed from the same content should be equal.")
+	}
+
+	differentAnswer := ParseTopic(strings.NewReader(strings.Replace(getSampleCsvAsStream(), "3_Answer 2", "3_Answer X", 1)), getTpp())
+	if topic.Equal(differentAnswer) || differentAnswer.Equal(topic) {
+		t.Errorf("Topics differing by one answer should not be equal.")
+	}
+
+	reordered := ParseTopic(strings.NewReader(`
+### Lesson 3
+3_Question 1;3_Answer 1
+3_Question 2;3_Answer 2
+3_Question 3;3_Answer 3
+
+### Lesson 1
+1_Question 1;1_Answer 1
+
+### Lesson 2
+2_Question 1;2_Answer 1
+2_Question 2;2_Answer 2
+`), getTpp())
+	if !topic.Equal(reordered) {
+		t.Errorf("The order of the subsections should not matter when comparing topics.")
+	}
+
+	swapped := ParseTopic(strings.NewReader(`
+### Lesson 1
+1_Question 1;1_Answer 1
+
+### Lesson 2
+2_Question 2;2_Answer 2
+2_Question 1;2_Answer 1
+
+### Lesson 3
+3_Question 1;3_Answer 1
+3_Question 2;3_Answer 2
+3_Question 3;3_Answer 3
+`), getTpp())
+	if topic.Equal(swapped) {
+		t.Errorf("The order of the questions inside a subsection should matter when comparing topics.")
+	}
+
+	if topic.Equal(NewTopic()) || NewTopic().Equal(topic) {
+		t.Errorf("A topic should not be equal to an empty one.")
+	}
+}
+
+// TestSubsectionsIsACopy checks that changing the subsections returned by
+// Subsections does not change the topic.
+func TestSubsectionsIsACopy(t *testing.T) {
+	topic := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp())
+	reference := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp())
+
+	subsections := topic.Subsections()
+	if len(subsections) != 3 || subsections["2"].GetCount() != 2 {
+		t.Fatalf("The subsections should be a copy of the ones of the topic.")
+	}
+	delete(subsections, "1")
+	subsections["4"] = NewQA()
+	qa := subsections["2"]
+	qa.AddEntry("new question", "new answer")
+	qa.answers[0] = "changed"
+	subsections["2"] = qa
+
+	if topic.GetSubsectionsCount() != 3 {
+		t.Errorf("Changing the returned map should not change the count of subsections of the topic. Found %d\n", topic.GetSubsectionsCount())
+	}
+	if !topic.Equal(reference) {
+		t.Errorf("Changing the returned subsections should not change the topic.")
+	}
+}
+
+// TestBuildInterleavedSet checks that the questions are taken from each
+// subsection in rotation.
+func TestBuildInterleavedSet(t *testing.T) {
+	topic := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp())
+
+	expected := []string{"1_Question 1", "2_Question 1", "3_Question 1", "2_Question 2", "3_Question 2", "3_Question 3"}
+	qa := topic.BuildInterleavedSet()
+	if !reflect.DeepEqual(qa.questions, expected) {
+		t.Errorf("The interleaved set should be %v but we got %v\n", expected, qa.questions)
+	}
+	for i := range qa.questions {
+		if qa.answers[i] != strings.Replace(qa.questions[i], "Question", "Answer", 1) {
+			t.Errorf("The question '%s' lost its answer.", qa.questions[i])
+		}
+	}
+
+	expected = []string{"3_Question 1", "1_Question 1", "3_Question 2", "3_Question 3"}
+	qa = topic.BuildInterleavedSet("3", "1")
+	if !reflect.DeepEqual(qa.questions, expected) {
+		t.Errorf("The interleaved set of the subsections 3 and 1 should be %v but we got %v\n", expected, qa.questions)
+	}
+
+	p, err := parse(noEnvironment, "-interleave", "-m", "random")
+	if err != nil || !p.IsInterleaved() || p.mode != linear {
+		t.Errorf("Parsing failed to set the interleaved mode.")
+	}
+}
+
+// TestParsingAnnounceAndSeparator checks that the markers of the deck can be
+// set from the command line and that a deck using them is parsed.
+func TestParsingAnnounceAndSeparator(t *testing.T) {
+	p, err := parse(noEnvironment)
+	if err != nil {
+		t.Fatalf("Parsing should not fail with empty parameters")
+	}
+	tpp := p.GetTopicParsingParameters()
+	if tpp.TopicAnnounce != "### " || tpp.QaSep != ";" {
+		t.Errorf("The default markers should be '### ' and ';' but we got '%s' and '%s'\n", tpp.TopicAnnounce, tpp.QaSep)
+	}
+
+	p, err = parse(noEnvironment, "-announce", "== ", "-sep", "|")
+	if err != nil {
+		t.Fatalf("Parsing detects the markers options as an error: %v", err)
+	}
+	tpp = p.GetTopicParsingParameters()
+	if tpp.TopicAnnounce != "== " || tpp.QaSep != "|" {
+		t.Errorf("The markers should be '== ' and '|' but we got '%s' and '%s'\n", tpp.TopicAnnounce, tpp.QaSep)
+	}
+
+	topic := ParseTopic(strings.NewReader(`
+== Verbs
+manger|to eat
+courir|to run
+
+== Animals
+chat|cat
+`), tpp)
+	if !reflect.DeepEqual(topic.GetSubsectionsName(), []string{"Verbs", "Animals"}) {
+		t.Errorf("The subsections should be [Verbs Animals] but we got %v\n", topic.GetSubsectionsName())
+	}
+	qa := topic.BuildQuestionsSet("Verbs")
+	if !reflect.DeepEqual(qa.answers, []string{"to eat", "to run"}) {
+		t.Errorf("The answers should be [to eat to run] but we got %v\n", qa.answers)
+	}
+
+	if _, err := parse(noEnvironment, "-sep", ""); err == nil {
+		t.Errorf("An empty separator is not detected.")
+	}
+}
+
 func getGenericInterrogationParameters() InterrogationParameters {
 	ip := InterrogationParameters{
 		interactive: false,
-		wait:        1*time.Millisecond,
+		minWait:     1 * time.Millisecond,
+		maxWait:     1 * time.Millisecond,
 		limit:       10,
 		mode:        linear,
-		qachan:	     make(chan string),
-		command:	   make(chan string),
-		publisher:	 make(chan string),
 	}
 	return ip
 }
@@ -300,7 +483,7 @@ func TestAskQuestionsInUnattendedMode(t *testing.T) {
 	pr, pw := io.Pipe()
 	defer pw.Close()
 	ip := getGenericUnattendedInterrogationParameters()
-  ip.out = pw
+	ip.out = pw
 
 	fmt.Println("    ****************")
 	fmt.Println("Test Ask Question in Linear Mode...")
@@ -332,7 +515,7 @@ func TestAskQuestionsInReverseAndUnattendedMode(t *testing.T) {
 
 	pr, pw := io.Pipe()
 	ip := getGenericUnattendedInterrogationParameters()
-  ip.out = pw
+	ip.out = pw
 	ip.reversed = true
 
 	questionsSet := topic.BuildQuestionsSet()
@@ -350,12 +533,117 @@ func TestAskQuestionsInReverseAndUnattendedMode(t *testing.T) {
 	validateOutput(tpp, questionsSet, *s, t, ip.reversed)
 }
 
-//
+// TestReverseModeWithSelectedTopics checks end to end that the reverse mode
+// set on the command line prompts with the answers of the selected topics
+// only, and that it is kept along with the summary mode.
+func TestReverseModeWithSelectedTopics(t *testing.T) {
+	p, err := parse(noEnvironment, "-r", "-l", "2", "-m", "linear", "-s")
+	if err != nil {
+		t.Fatalf("Parsing the reverse mode with selected topics failed: %v", err)
+	}
+	if !p.IsSummaryMode() || !p.IsReversedMode() {
+		t.Errorf("The summary mode must not drop the reverse mode.")
+	}
+
+	p, err = parse(noEnvironment, "-r", "-l", "2", "-m", "linear")
+	if err != nil {
+		t.Fatalf("Parsing the reverse mode with selected topics failed: %v", err)
+	}
+	topic := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp())
+	questionsSet := topic.BuildQuestionsSet(p.GetListOfSubsections()...)
+
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.reversed = p.IsReversedMode()
+	ip.mode = p.mode
+	ip.limit = 1
+	lines := getSessionOutput(questionsSet, ip)
+
+	for _, expected := range []string{"2_Answer 1     --> 2_Question 1", "2_Answer 2     --> 2_Question 2"} {
+		if !contains(lines, expected) {
+			t.Errorf("The reversed card '%s' is missing. Output was:\n%s\n", expected, strings.Join(lines, "\n"))
+		}
+	}
+	for _, line := range lines {
+		if strings.Contains(line, "1_") || strings.Contains(line, "3_") {
+			t.Errorf("The card '%s' does not belong to the selected topic.", line)
+		}
+	}
+}
+
+// getSessionOutput runs a session with the parameters and returns the lines
+// written to the output.
+func getSessionOutput(qa QuestionsAnswers, ip InterrogationParameters) []string {
+	pr, pw := io.Pipe()
+	ip.out = pw
+	go func() {
+		defer pw.Close()
+		AskQuestions(qa, ip)
+	}()
+
+	var lines []string
+	s := bufio.NewScanner(pr)
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	return lines
+}
+
+// contains tells if the line is part of the lines.
+func contains(lines []string, line string) bool {
+	for _, l := range lines {
+		if l == line {
+			return true
+		}
+	}
+	return false
+}
+
+// TestFrontAndReverseCombinations checks the prompt chosen for each
+// combination of the -front and -r options.
+func TestFrontAndReverseCombinations(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("question", "answer")
+
+	cases := []struct {
+		args     []string
+		expected string
+	}{
+		{[]string{}, "question     --> answer"},
+		{[]string{"-front", "q"}, "question     --> answer"},
+		{[]string{"-front", "a"}, "answer     --> question"},
+		{[]string{"-r"}, "answer     --> question"},
+		{[]string{"-front", "q", "-r"}, "answer     --> question"},
+		{[]string{"-front", "a", "-r"}, "question     --> answer"},
+	}
+	for _, c := range cases {
+		p, err := parse(noEnvironment, c.args...)
+		if err != nil {
+			t.Errorf("Parsing %v should not fail: %v", c.args, err)
+			continue
+		}
+		ip := getGenericUnattendedInterrogationParameters()
+		ip.answerFirst = p.answerFirst
+		ip.reversed = p.reversed
+		ip.limit = 1
+		lines := getSessionOutput(qa, ip)
+		if !contains(lines, c.expected) {
+			t.Errorf("With %v, we were expecting the line '%s' but received:\n%s\n", c.args, c.expected, strings.Join(lines, "\n"))
+		}
+	}
+}
+
+// TestParsingInvalidFront checks that only q and a are accepted as front.
+func TestParsingInvalidFront(t *testing.T) {
+	if _, err := parse(noEnvironment, "-front", "x"); err == nil {
+		t.Errorf("An invalid front column is not detected.")
+	}
+}
+
 func validateOutput(tpp TopicParsingParameters, questionsSet QuestionsAnswers, s bufio.Scanner, t *testing.T, reverseMode bool) {
 
 	announcement, _ := regexp.Compile("^" + tpp.TopicAnnounce)
 	questionsCount := questionsSet.GetCount()
-	i := 0
+	i, checked := 0, 0
 	var (
 		isAnnounce     bool
 		isEmpty        bool
@@ -373,22 +661,25 @@ func validateOutput(tpp TopicParsingParameters, questionsSet QuestionsAnswers, s
 		isSeparator = separator.MatchString(s.Text())
 		isNbOfQ = nbOfQuestions.MatchString(s.Text())
 		isLimitReached = limitReached.MatchString(s.Text())
-		if !isAnnounce && !isEmpty && !isLoop && !isSeparator && !isNbOfQ && ! isLimitReached {
+		if !isAnnounce && !isEmpty && !isLoop && !isSeparator && !isNbOfQ && !isLimitReached {
 			// default is non reverse mode
 			expected = questionsSet.questions[i] + "     --> " + questionsSet.answers[i]
 			if reverseMode {
-			  expected = questionsSet.answers[i] + "     --> " + questionsSet.questions[i]
+				expected = questionsSet.answers[i] + "     --> " + questionsSet.questions[i]
 			}
 			computed = s.Text()
 			if computed != expected {
 				t.Errorf("Check of answers failed. We were expected '%s' but received '%s'\n", expected, computed)
 			}
 			i = (i + 1) % questionsCount
+			checked++
 		}
 	}
+	if checked == 0 {
+		t.Errorf("No question was found in the output.")
+	}
 }
 
-//
 func validateRandomOutput(tpp TopicParsingParameters, questionsSet QuestionsAnswers, s bufio.Scanner, t *testing.T, reverseMode bool) {
 
 	announcement, _ := regexp.Compile("^" + tpp.TopicAnnounce)
@@ -413,11 +704,11 @@ func validateRandomOutput(tpp TopicParsingParameters, questionsSet QuestionsAnsw
 		isSeparator = separator.MatchString(s.Text())
 		isNbOfQ = nbOfQuestions.MatchString(s.Text())
 		isLimitReached = limitReached.MatchString(s.Text())
-		if !isAnnounce && !isEmpty && !isLoop && !isSeparator && !isNbOfQ && ! isLimitReached {
+		if !isAnnounce && !isEmpty && !isLoop && !isSeparator && !isNbOfQ && !isLimitReached {
 			// default is non reverse mode
 			expected = questionsSet.questions[i] + "     --> " + questionsSet.answers[i]
 			if reverseMode {
-			  expected = questionsSet.answers[i] + "     --> " + questionsSet.questions[i]
+				expected = questionsSet.answers[i] + "     --> " + questionsSet.questions[i]
 			}
 			computed = s.Text()
 			if computed == expected {
@@ -462,7 +753,7 @@ func TestAskQuestionsInLinearAndInteractiveMode(t *testing.T) {
 	go func() {
 		// Simulation of interactive mode: the "user" sends return
 		// carriage to command.
-		for i := 0; i < ip.limit * questionsCount; i++ {
+		for i := 0; i < ip.limit*questionsCount; i++ {
 			fmt.Fprintf(userOut, "\n")
 		}
 	}()
@@ -472,3 +763,1283 @@ func TestAskQuestionsInLinearAndInteractiveMode(t *testing.T) {
 	validateOutput(tpp, questionsSet, *s, t, ip.reversed)
 
 }
+
+// TestRepeatCommand checks that typing the repeat command in interactive
+// mode displays the question again before the answer is revealed.
+func TestRepeatCommand(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("question", "answer")
+
+	pr, pw := io.Pipe()
+	userIn, userOut := io.Pipe()
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.in = userIn
+	ip.out = pw
+	ip.limit = 1
+
+	go func() {
+		defer pw.Close()
+		AskQuestions(qa, ip)
+	}()
+
+	go func() {
+		fmt.Fprintf(userOut, "%s\n\n", repeatCommand)
+	}()
+
+	var lines []string
+	s := bufio.NewScanner(pr)
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	expected := []string{"question", "question     --> answer"}
+	found := false
+	for i := 0; i+1 < len(lines); i++ {
+		if lines[i] == expected[0] && lines[i+1] == expected[1] {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("The question should have been displayed twice before the answer. Output was:\n%s\n", strings.Join(lines, "\n"))
+	}
+}
+
+// fakeClock is a clock that never waits. Its timers fire immediately when
+// fireTimers is set and never otherwise.
+type fakeClock struct {
+	now        time.Time
+	fireTimers bool
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	if !c.fireTimers {
+		return nil
+	}
+	timer := make(chan time.Time, 1)
+	timer <- c.now.Add(d)
+	return timer
+}
+
+// TestParsingAutoAdvance checks that the option -auto is detected.
+func TestParsingAutoAdvance(t *testing.T) {
+	p, err := parse(noEnvironment, "-i", "-auto", "3000")
+	if err != nil {
+		t.Errorf("Parsing detects the auto advance as an error")
+	}
+	if p.autoAdvance != 3*time.Second {
+		t.Errorf("Failed to detect the auto advance as 3s. Found %v instead.\n", p.autoAdvance)
+	}
+	if _, err := parse(noEnvironment, "-auto", "soon"); err == nil {
+		t.Errorf("An auto advance time that is not an integer is not detected.")
+	}
+}
+
+// TestAutoAdvanceWithoutInput checks that, in interactive mode, the answer
+// is revealed when the timer fires even if the user does not press Return.
+func TestAutoAdvanceWithoutInput(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("question", "answer")
+
+	userIn, _ := io.Pipe()
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.in = userIn
+	ip.limit = 2
+	ip.autoAdvance = time.Second
+	ip.clock = &fakeClock{fireTimers: true}
+
+	lines := getSessionOutput(qa, ip)
+	if !contains(lines, "question     --> answer") {
+		t.Errorf("The answer should have been revealed by the timer. Output was:\n%s\n", strings.Join(lines, "\n"))
+	}
+}
+
+// TestAutoAdvanceWithInput checks that, in interactive mode with auto
+// advance, the answer is revealed as soon as the user presses Return.
+func TestAutoAdvanceWithInput(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("question", "answer")
+
+	userIn, userOut := io.Pipe()
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.in = userIn
+	ip.limit = 2
+	ip.autoAdvance = time.Second
+	ip.clock = &fakeClock{fireTimers: false}
+
+	go fmt.Fprintf(userOut, "\n\n")
+
+	lines := getSessionOutput(qa, ip)
+	if !contains(lines, "question     --> answer") {
+		t.Errorf("The answer should have been revealed by the input. Output was:\n%s\n", strings.Join(lines, "\n"))
+	}
+}
+
+// TestParsingVerbose checks that the option -verbose is detected and that
+// the verbose information goes to the error output by default.
+func TestParsingVerbose(t *testing.T) {
+	p, err := parse(noEnvironment)
+	if err != nil || p.IsVerbose() {
+		t.Errorf("The verbose mode should be off by default.")
+	}
+	p, err = parse(noEnvironment, "-verbose")
+	if err != nil || !p.IsVerbose() {
+		t.Errorf("Parsing failed to detect the verbose mode.")
+	}
+	if p.GetVerboseStream() != os.Stderr {
+		t.Errorf("The verbose information should be written to the error output.")
+	}
+}
+
+// TestWriteParseSummary checks the summary of what was loaded.
+func TestWriteParseSummary(t *testing.T) {
+	topic := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp())
+
+	var out bytes.Buffer
+	WriteParseSummary(&out, "/decks/sample.csv", getTpp(), topic)
+	for _, line := range []string{"Deck: /decks/sample.csv", "Separator: ';'", "Subsections: 3", "Questions: 6"} {
+		if !contains(strings.Split(out.String(), "\n"), line) {
+			t.Errorf("The line '%s' is missing from the summary:\n%s\n", line, out.String())
+		}
+	}
+}
+
+// TestMixMode checks that, in mix mode, the direction of each question is
+// picked with the random generator and that the answers are graded against
+// the hidden side.
+func TestMixMode(t *testing.T) {
+	p, err := parse(noEnvironment, "-mix")
+	if err != nil || !p.IsMixMode() {
+		t.Fatalf("Parsing failed to detect the mix mode.")
+	}
+
+	qa := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp()).BuildQuestionsSet()
+	const seed = 7
+
+	// The same generator gives the directions the session will pick.
+	directions := rand.New(rand.NewSource(seed))
+	var swapped []bool
+	var typed bytes.Buffer
+	for i := range qa.questions {
+		s := pickSwapped(directions)
+		swapped = append(swapped, s)
+		if s {
+			fmt.Fprintln(&typed, qa.questions[i])
+		} else {
+			fmt.Fprintln(&typed, qa.answers[i])
+		}
+	}
+	swappedCount := 0
+	for _, s := range swapped {
+		if s {
+			swappedCount++
+		}
+	}
+	if swappedCount == 0 || swappedCount == len(swapped) {
+		t.Fatalf("The seed should give both directions: %v", swapped)
+	}
+
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.in = &typed
+	ip.limit = 1
+	ip.mix = true
+	ip.graded = true
+	ip.rng = rand.New(rand.NewSource(seed))
+
+	lines := getSessionOutput(qa, ip)
+	for i := range qa.questions {
+		expected := qa.questions[i] + "     --> " + qa.answers[i]
+		if swapped[i] {
+			expected = qa.answers[i] + "     --> " + qa.questions[i]
+		}
+		if !contains(lines, expected) {
+			t.Errorf("We were expecting the line '%s'. Output was:\n%s\n", expected, strings.Join(lines, "\n"))
+		}
+	}
+	if !contains(lines, fmt.Sprintf("Score: %d/%d (100%%)", qa.GetCount(), qa.GetCount())) {
+		t.Errorf("All the answers should have been graded as correct. Output was:\n%s\n", strings.Join(lines, "\n"))
+	}
+}
+
+// TestAskQuestionsWithClosedOutput checks that the session stops when the
+// output cannot be written anymore, for instance when the reader of a pipe
+// goes away.
+func TestAskQuestionsWithClosedOutput(t *testing.T) {
+	qa := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp()).BuildQuestionsSet()
+
+	pr, pw := io.Pipe()
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.out = pw
+	ip.limit = 1000
+
+	done := make(chan SessionResult)
+	go func() {
+		done <- AskQuestions(qa, ip)
+	}()
+
+	s := bufio.NewScanner(pr)
+	for i := 0; i < 5 && s.Scan(); i++ {
+	}
+	pr.Close()
+
+	select {
+	case result := <-done:
+		if result.Err == nil {
+			t.Errorf("The session should report that the output was closed.")
+		}
+		if result.Asked >= ip.limit*qa.GetCount() {
+			t.Errorf("The session should have stopped early but asked %d questions.", result.Asked)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("The session did not stop after the output was closed.")
+	}
+}
+
+// TestInteractiveSessionWithClosedOutput checks that a session waiting for
+// the user stops when the output is closed.
+func TestInteractiveSessionWithClosedOutput(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("question", "answer")
+
+	pr, pw := io.Pipe()
+	userIn, _ := io.Pipe()
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.in = userIn
+	ip.out = pw
+
+	done := make(chan SessionResult)
+	go func() {
+		done <- AskQuestions(qa, ip)
+	}()
+	pr.Close()
+
+	select {
+	case result := <-done:
+		if result.Err == nil {
+			t.Errorf("The session should report that the output was closed.")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("The session did not stop after the output was closed.")
+	}
+}
+
+// TestBackCommand checks that typing the back command displays the previous
+// question and its answer without moving to the next question.
+func TestBackCommand(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("q1", "a1")
+	qa.AddEntry("q2", "a2")
+	qa.AddEntry("q3", "a3")
+
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.limit = 1
+	ip.graded = true
+	// back on the first question, answer it, answer the second one, then
+	// go back twice from the third one before answering it.
+	ip.in = strings.NewReader(commandPrefix + backCommand + "\na1\na2\n" + commandPrefix + backLongCommand + "\n" + commandPrefix + backCommand + "\na3\n")
+
+	lines := getSessionOutput(qa, ip)
+	expected := []string{
+		"q1",
+		"No previous question",
+		"q1     --> a1",
+		"Correct",
+		"---------------------------",
+		"q2     --> a2",
+		"Correct",
+		"---------------------------",
+		"q3",
+		"Previous: q2     --> a2",
+		"q3",
+		"Previous: q2     --> a2",
+		"q3     --> a3",
+		"Correct",
+		"---------------------------",
+		"Score: 3/3 (100%)",
+	}
+	var content []string
+	for _, line := range lines {
+		if !loop.MatchString(line) && !nbOfQuestions.MatchString(line) && !limitReached.MatchString(line) {
+			content = append(content, line)
+		}
+	}
+	if !reflect.DeepEqual(content, expected) {
+		t.Errorf("We were expecting:\n%s\nbut we got:\n%s\n", strings.Join(expected, "\n"), strings.Join(content, "\n"))
+	}
+}
+
+// TestSubsectionsBreakdown checks that the graded answers are counted per
+// subsection.
+func TestSubsectionsBreakdown(t *testing.T) {
+	topic := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp())
+	qa := topic.BuildQuestionsSet("2", "3")
+	if !reflect.DeepEqual(qa.origin, []string{"2", "2", "3", "3", "3"}) {
+		t.Fatalf("The origin of the questions is not recorded: %v", qa.origin)
+	}
+
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.limit = 1
+	ip.graded = true
+	// One answer right in the subsection 2, two in the subsection 3.
+	ip.in = strings.NewReader("2_Answer 1\nwrong\nwrong\n3_Answer 2\n3_Answer 3\n")
+
+	var result SessionResult
+	pr, pw := io.Pipe()
+	ip.out = pw
+	go func() {
+		defer pw.Close()
+		result = AskQuestions(qa, ip)
+	}()
+	var lines []string
+	s := bufio.NewScanner(pr)
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+
+	expected := []SubsectionResult{{Name: "2", Asked: 2, Graded: 2, Correct: 1}, {Name: "3", Asked: 3, Graded: 3, Correct: 2}}
+	if !reflect.DeepEqual(result.Subsections, expected) {
+		t.Errorf("The scores per subsection should be %+v but we got %+v\n", expected, result.Subsections)
+	}
+	if !contains(lines, "Per subsection: 2: 1/2, 3: 2/3") {
+		t.Errorf("The breakdown per subsection is not reported. Output was:\n%s\n", strings.Join(lines, "\n"))
+	}
+}
+
+// TestProgressStatus checks that the status line is written to its own
+// writer at each question, with the loop changing at the loop boundaries,
+// and that it does not pollute the output of the session.
+func TestProgressStatus(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("Question 1", "Answer 1")
+	qa.AddEntry("Question 2", "Answer 2")
+
+	var status bytes.Buffer
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.limit = 2
+	ip.progress = &status
+
+	lines := getSessionOutput(qa, ip)
+	expected := "Loop 1/2, question 1\nLoop 1/2, question 2\nLoop 2/2, question 3\nLoop 2/2, question 4\n"
+	if status.String() != expected {
+		t.Errorf("The status lines should be:\n%s\nbut we got:\n%s\n", expected, status.String())
+	}
+	for _, line := range lines {
+		if strings.Contains(line, "question 1") {
+			t.Errorf("The status line was written to the output of the session: '%s'\n", line)
+		}
+	}
+}
+
+// TestDiffAnswer checks the marks put on the answer typed in echo mode.
+func TestDiffAnswer(t *testing.T) {
+	cases := []struct {
+		given    string
+		expected string
+		diff     string
+	}{
+		{"chien", "chien", "chien"},
+		{" Chien", "chien", "Chien"},
+		{"chein", "chien", "ch[e][i]n"},
+		{"chienne", "chien", "chien[n][e]"},
+		{"chi", "chien", "chi[_][_]"},
+		{"été", "ete", "[é]t[é]"},
+	}
+	for _, c := range cases {
+		if diff := diffAnswer(c.given, c.expected); diff != c.diff {
+			t.Errorf("Comparing '%s' to '%s' should give '%s' but we got '%s'\n", c.given, c.expected, c.diff, diff)
+		}
+	}
+}
+
+// TestRotateAnswers checks that the answer displayed for an entry with
+// several answers changes across the loops, and that it becomes the prompt
+// in reversed mode.
+func TestRotateAnswers(t *testing.T) {
+	alternatives := []string{"to eat", "to dine", "to have a meal"}
+	qa := NewQA()
+	qa.addEntry(entry{question: "manger", answer: strings.Join(alternatives, " / "), alternatives: alternatives})
+
+	for _, reversed := range []bool{false, true} {
+		ip := getGenericUnattendedInterrogationParameters()
+		ip.limit = 9
+		ip.rotate = true
+		ip.reversed = reversed
+		ip.rng = rand.New(rand.NewSource(5))
+
+		lines := strings.Join(getSessionOutput(qa, ip), "\n")
+		seen := 0
+		for _, a := range alternatives {
+			card := "manger     --> " + a
+			if reversed {
+				card = a + "     --> manger"
+			}
+			if strings.Contains(lines, card) {
+				seen++
+			}
+		}
+		if seen < 2 {
+			t.Errorf("Several answers should have been displayed across the loops (reversed: %v). Output was:\n%s\n", reversed, lines)
+		}
+		if strings.Contains(lines, " / ") {
+			t.Errorf("The answers should be displayed one at a time (reversed: %v). Output was:\n%s\n", reversed, lines)
+		}
+	}
+}
+
+// TestRotateAnswersFromDeck checks that -rotate-answers displays one at a
+// time the answers read from a deck, whether they are alternatives or
+// distinct fields.
+func TestRotateAnswersFromDeck(t *testing.T) {
+	for _, c := range []struct {
+		args []string
+		deck string
+	}{
+		{[]string{"-rotate-answers"}, "manger;to eat|to dine|to have a meal\n"},
+		{[]string{"-rotate-answers", "-multi-answer"}, "manger;to eat;to dine;to have a meal\n"},
+	} {
+		p, err := parse(noEnvironment, c.args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		qa := ParseTopic(strings.NewReader(c.deck), p.GetTopicParsingParameters()).BuildQuestionsSet()
+
+		ip := getGenericUnattendedInterrogationParameters()
+		ip.limit = 9
+		ip.rotate = p.rotate
+		ip.rng = rand.New(rand.NewSource(5))
+		lines := strings.Join(getSessionOutput(qa, ip), "\n")
+		seen := 0
+		for _, a := range []string{"to eat", "to dine", "to have a meal"} {
+			if strings.Contains(lines, "manger     --> "+a+"\n") {
+				seen++
+			}
+		}
+		if seen < 2 {
+			t.Errorf("The answers of the deck should be displayed one at a time with %v. Output was:\n%s\n", c.args, lines)
+		}
+	}
+}
+
+// TestParseMultiAnswer checks a line with three fields after the question,
+// joined back into a single answer by default and read as three distinct
+// answers with the MultiAnswer option.
+func TestParseMultiAnswer(t *testing.T) {
+	deck := "### Lesson 1\nmanger;to eat;to dine;to have a meal #verb\n"
+
+	tpp := getTpp()
+	tpp.TagPrefix = "#"
+	topic := ParseTopic(strings.NewReader(deck), tpp)
+	legacy := topic.GetSubsection("1")
+	if legacy.answers[0] != "to eat;to dine;to have a meal" || legacy.alternatives[0] != nil {
+		t.Errorf("By default the fields should be joined in a single answer but we got '%s' and %v\n", legacy.answers[0], legacy.alternatives[0])
+	}
+
+	tpp.MultiAnswer = true
+	topic = ParseTopic(strings.NewReader(deck), tpp)
+	multi := topic.GetSubsection("1")
+	expected := []string{"to eat", "to dine", "to have a meal"}
+	if !reflect.DeepEqual(multi.alternatives[0], expected) {
+		t.Errorf("The answers should be %v but we got %v\n", expected, multi.alternatives[0])
+	}
+	if multi.answers[0] != "to eat / to dine / to have a meal" {
+		t.Errorf("The answers should be displayed joined with ' / ' but we got '%s'\n", multi.answers[0])
+	}
+	if !reflect.DeepEqual(multi.tags[0], []string{"verb"}) {
+		t.Errorf("The tags of the last answer are lost: %v\n", multi.tags[0])
+	}
+
+	tpp.AnswerConnector = ", "
+	topic = ParseTopic(strings.NewReader(deck), tpp)
+	if qa := topic.GetSubsection("1"); qa.answers[0] != "to eat, to dine, to have a meal" {
+		t.Errorf("The answers should be joined with the connector but we got '%s'\n", qa.answers[0])
+	}
+}
+
+// TestParseAlternativeAnswers checks that the answer holding the separator
+// of the alternatives is read as several acceptable answers, written back
+// the same way.
+func TestParseAlternativeAnswers(t *testing.T) {
+	deck := "### Lesson 1\nmanger;to eat | to dine #verb\nboire;to drink\n"
+
+	tpp := getTpp()
+	tpp.TagPrefix = "#"
+	topic := ParseTopic(strings.NewReader(deck), tpp)
+	if qa := topic.GetSubsection("1"); qa.answers[0] != "to eat | to dine" || qa.alternatives[0] != nil {
+		t.Errorf("Without separator the answer should be a single one but we got '%s' and %v\n", qa.answers[0], qa.alternatives[0])
+	}
+
+	tpp.AlternativeSep = "|"
+	topic = ParseTopic(strings.NewReader(deck), tpp)
+	qa := topic.GetSubsection("1")
+	if expected := []string{"to eat", "to dine"}; !reflect.DeepEqual(qa.alternatives[0], expected) {
+		t.Errorf("The answers should be %v but we got %v\n", expected, qa.alternatives[0])
+	}
+	if qa.answers[0] != "to eat / to dine" || !reflect.DeepEqual(qa.tags[0], []string{"verb"}) {
+		t.Errorf("All the answers should be displayed, without the tags, but we got '%s' and %v\n", qa.answers[0], qa.tags[0])
+	}
+	if qa.alternatives[1] != nil {
+		t.Errorf("A single answer should have no alternatives but we got %v\n", qa.alternatives[1])
+	}
+
+	var out strings.Builder
+	if err := WriteTopic(&out, topic, tpp); err != nil || !strings.Contains(out.String(), "manger;to eat|to dine #verb\n") {
+		t.Errorf("The answers should be written with their separator but we got %v. Output was:\n%s\n", err, out.String())
+	}
+}
+
+// TestGradeAlternativeAnswers checks that any of the answers of a question
+// is accepted in a graded session.
+func TestGradeAlternativeAnswers(t *testing.T) {
+	tpp := getTpp()
+	tpp.AlternativeSep = "|"
+	topic := ParseTopic(strings.NewReader("### Lesson 1\nmanger;to eat|to dine\ncourir;to run|to jog\n"), tpp)
+	qa := topic.BuildQuestionsSet()
+
+	userIn, userOut := io.Pipe()
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.in = userIn
+	ip.limit = 1
+	ip.graded = true
+	go func() {
+		fmt.Fprintln(userOut, "to dine")
+		fmt.Fprintln(userOut, "to walk")
+	}()
+
+	var result SessionResult
+	pr, pw := io.Pipe()
+	ip.out = pw
+	go func() {
+		defer pw.Close()
+		result = AskQuestions(qa, ip)
+	}()
+	ioutil.ReadAll(pr)
+
+	if result.Correct != 1 || !reflect.DeepEqual(result.Wrong, []int{1}) {
+		t.Errorf("Only the answer among the alternatives should be correct but the score is %s\n", result.Score())
+	}
+}
+
+// TestGradeMultiAnswer checks that any of the answers of a question is
+// accepted.
+func TestGradeMultiAnswer(t *testing.T) {
+	alternatives := []string{"to eat", "to dine"}
+	if !isCorrectAlternative(" To Dine", alternatives) {
+		t.Errorf("The second answer should be accepted.")
+	}
+	if isCorrectAlternative("to drink", alternatives) {
+		t.Errorf("An answer that is not in the list must not be accepted.")
+	}
+}
+
+// TestGradeNormalized checks that the answers typed are compared once
+// normalized.
+func TestGradeNormalized(t *testing.T) {
+	if !isCorrect("l'eau  chaude ", "L’eau chaude") {
+		t.Errorf("The curly quote, the case and the spaces should not be significant.")
+	}
+	if isCorrect("l'eau froide", "l'eau chaude") {
+		t.Errorf("A different answer must not be accepted.")
+	}
+
+	p, err := parse(noEnvironment, "-m", "typed")
+	if err != nil {
+		t.Fatalf("The typed mode should be accepted: %v", err)
+	}
+	if !p.interactive || !p.graded || p.mode != random {
+		t.Errorf("The typed mode should ask the questions in random order and check the answers.")
+	}
+}
+
+// TestPartialScore checks the fraction of the required parts found in an
+// answer.
+func TestPartialScore(t *testing.T) {
+	parts := []string{"suis", "es", "est"}
+	cases := []struct {
+		given    string
+		expected float64
+	}{
+		{"est, Suis,es", 1},
+		{"suis, est", 2.0 / 3},
+		{"suis, suis", 1.0 / 3},
+		{"sont", 0},
+	}
+	for _, c := range cases {
+		if score := partialScore(c.given, parts); score != c.expected {
+			t.Errorf("The score of '%s' should be %f but we got %f\n", c.given, c.expected, score)
+		}
+	}
+}
+
+// TestPartialCredit checks that the partial answers count in the percentage
+// of the score in a graded session.
+func TestPartialCredit(t *testing.T) {
+	tpp := getTpp()
+	tpp.MultiAnswer = true
+	topic := ParseTopic(strings.NewReader("### Lesson 1\nêtre;suis;es;est\navoir;ai;as;a\n"), tpp)
+	qa := topic.BuildQuestionsSet()
+
+	userIn, userOut := io.Pipe()
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.in = userIn
+	ip.limit = 1
+	ip.graded = true
+	ip.partialCredit = true
+	go func() {
+		fmt.Fprintln(userOut, "est, suis, es")
+		fmt.Fprintln(userOut, "ai, ont")
+	}()
+
+	var result SessionResult
+	pr, pw := io.Pipe()
+	ip.out = pw
+	go func() {
+		defer pw.Close()
+		result = AskQuestions(qa, ip)
+	}()
+	output, _ := ioutil.ReadAll(pr)
+
+	if result.Correct != 1 || result.Score() != "1/2 (66%)" {
+		t.Errorf("The partial answer should count for a third in the percentage but the score is %s\n", result.Score())
+	}
+	if !reflect.DeepEqual(result.Wrong, []int{1}) {
+		t.Errorf("The partial answer should be recorded as wrong but the wrong answers are %v\n", result.Wrong)
+	}
+	if !strings.Contains(string(output), "Partially correct (33%)\n") {
+		t.Errorf("The partial answer should be told. Output was:\n%s\n", output)
+	}
+}
+
+// TestShowSection checks that a header is written before the first question
+// of each subsection in linear mode, and only there.
+func TestShowSection(t *testing.T) {
+	topic := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp())
+	qa := topic.BuildQuestionsSet(topic.GetSubsectionsName()...)
+
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.limit = 1
+	ip.showSection = true
+	lines := getSessionOutput(qa, ip)
+
+	var headers []string
+	for n, line := range lines {
+		if !strings.HasPrefix(line, "### ") {
+			continue
+		}
+		headers = append(headers, line)
+		id := strings.TrimPrefix(line, "### ")
+		if n+1 == len(lines) || !strings.HasPrefix(lines[n+1], id+"_Question 1") {
+			t.Errorf("The header '%s' is not followed by the first question of its subsection.", line)
+		}
+	}
+	if !reflect.DeepEqual(headers, []string{"### 1", "### 2", "### 3"}) {
+		t.Errorf("A header should be written at each change of subsection but we got %v\n", headers)
+	}
+}
+
+// TestNoSubsectionMessage checks that taking all the subsections is only
+// told in verbose mode, and not on the standard output.
+func TestNoSubsectionMessage(t *testing.T) {
+	topic := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp())
+
+	var verbose bytes.Buffer
+	p := NewInterrogationParameters()
+	p.verboseOut = &verbose
+	if qa := p.BuildQuestionsSet(topic); qa.GetCount() != 6 {
+		t.Errorf("All the questions should be taken but we got %d\n", qa.GetCount())
+	}
+	if verbose.Len() != 0 {
+		t.Errorf("The message should not be written by default but we got '%s'\n", verbose.String())
+	}
+
+	p.verbose = true
+	p.BuildQuestionsSet(topic, "1")
+	if verbose.Len() != 0 {
+		t.Errorf("The message should not be written when a subsection is supplied.")
+	}
+	p.BuildQuestionsSet(topic)
+	if !strings.Contains(verbose.String(), "You supplied no subsection") {
+		t.Errorf("The message should be written in verbose mode but we got '%s'\n", verbose.String())
+	}
+}
+
+// TestReversedSubsections checks that the subsections followed by :r in the
+// -l option are asked in reverse, and only them.
+func TestReversedSubsections(t *testing.T) {
+	p, err := parse(noEnvironment, "-l", "1,2:r")
+	if err != nil {
+		t.Fatalf("Parsing the reversed subsection failed: %v", err)
+	}
+	if !reflect.DeepEqual(p.GetListOfSubsections(), []string{"1", "2"}) {
+		t.Errorf("The suffix should be removed from the subsections: %v\n", p.GetListOfSubsections())
+	}
+	if !reflect.DeepEqual(p.GetReversedSubsections(), []string{"2"}) {
+		t.Errorf("Only the subsection 2 should be reversed: %v\n", p.GetReversedSubsections())
+	}
+
+	topic := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp())
+	qa := topic.BuildQuestionsSet(p.GetListOfSubsections()...).ReverseSubsections(p.GetReversedSubsections()...)
+
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.limit = 1
+	lines := getSessionOutput(qa, ip)
+	for _, expected := range []string{
+		"1_Question 1     --> 1_Answer 1",
+		"2_Answer 1     --> 2_Question 1",
+		"2_Answer 2     --> 2_Question 2",
+	} {
+		if !contains(lines, expected) {
+			t.Errorf("The card '%s' is missing. Output was:\n%s\n", expected, strings.Join(lines, "\n"))
+		}
+	}
+}
+
+// getUnalignedQA returns a set with an answer missing.
+func getUnalignedQA() QuestionsAnswers {
+	qa := NewQA()
+	qa.AddEntry("question-1", "answer-1")
+	qa.AddEntry("question-2", "answer-2")
+	qa.answers = qa.answers[:1]
+	return qa
+}
+
+// TestValidate checks that an unaligned set is detected and that the
+// operations on the sets keep them aligned.
+func TestValidate(t *testing.T) {
+	if err := getUnalignedQA().Validate(); err == nil {
+		t.Errorf("A set with a missing answer is not detected.")
+	}
+	if err := NewQA().Validate(); err != nil {
+		t.Errorf("An empty set should be valid: %v", err)
+	}
+
+	topic := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp())
+	qa := topic.BuildQuestionsSet()
+	qa.Concatenate(topic.BuildInterleavedSet())
+	sets := map[string]QuestionsAnswers{
+		"built":    qa,
+		"sorted":   qa.SortByDifficulty(),
+		"filtered": qa.FilterByTag("none"),
+		"sampled":  qa.Sample(4, rand.New(rand.NewSource(1))),
+		"reversed": qa.ReverseSubsections("2"),
+	}
+	for name, set := range sets {
+		if err := set.invariant(); err != nil {
+			t.Errorf("The %s set is not aligned: %v", name, err)
+		}
+	}
+}
+
+// TestFlagCommand checks that a flagged question is listed at the end of
+// the session without changing the grading.
+func TestFlagCommand(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("q1", "a1")
+	qa.AddEntry("q2", "a2")
+
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.limit = 1
+	ip.graded = true
+	ip.in = strings.NewReader("a1\n" + commandPrefix + flagCommand + "\na2\n")
+
+	var result SessionResult
+	pr, pw := io.Pipe()
+	ip.out = pw
+	go func() {
+		defer pw.Close()
+		result = AskQuestions(qa, ip)
+	}()
+	output, _ := ioutil.ReadAll(pr)
+
+	if !reflect.DeepEqual(result.Flagged, []int{1}) {
+		t.Errorf("Only the second question should be flagged but we got %v\n", result.Flagged)
+	}
+	if result.Correct != 2 {
+		t.Errorf("Flagging a question must not change the grading: %+v\n", result)
+	}
+	if !strings.Contains(string(output), "Score: 2/2 (100%)\nFlagged for review:\n  q2     --> a2\n") {
+		t.Errorf("The flagged question is not listed at the end of the session. Output was:\n%s\n", output)
+	}
+	if topic := FlaggedTopic(qa, result); topic.GetSubsectionsCount() != 1 {
+		t.Errorf("The flagged questions should make a deck of 1 topic.")
+	}
+}
+
+// TestSessionCommands checks that a hint gives the first letter of the
+// answer, that a question skipped is not graded, that an answer spelled like
+// a command is graded and that the user can quit with the score.
+func TestSessionCommands(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("q1", "a1")
+	qa.AddEntry("q2", "b2")
+	qa.AddEntry("q3", "s")
+	qa.AddEntry("q4", "d4")
+
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.limit = 1
+	ip.mode = linear
+	ip.graded = true
+	ip.noColor = true
+	ip.in = strings.NewReader(commandPrefix + hintLongCommand + "\na1\n" + commandPrefix + skipCommand + "\n" + skipCommand + "\n" + commandPrefix + markCommand + "\n" + commandPrefix + quitCommand + "\n")
+
+	var result SessionResult
+	pr, pw := io.Pipe()
+	ip.out = pw
+	go func() {
+		defer pw.Close()
+		result = AskQuestions(qa, ip)
+	}()
+	output, _ := ioutil.ReadAll(pr)
+
+	if !strings.Contains(string(output), "Hint: a...") {
+		t.Errorf("The hint should give the first letter of the answer. Output was:\n%s\n", output)
+	}
+	if strings.Contains(string(output), "b2") || !strings.Contains(string(output), answerArrow+skippedAnswer) {
+		t.Errorf("The answer of the question skipped must not be revealed. Output was:\n%s\n", output)
+	}
+	if result.Graded != 2 || result.Correct != 2 || !result.Quit || !reflect.DeepEqual(result.Flagged, []int{3}) {
+		t.Errorf("The first and the third questions should be graded and the fourth one marked but we got %+v\n", result)
+	}
+	if !strings.Contains(string(output), "Score: 2/2 (100%)\nFlagged for review:\n  q4     --> d4\n") {
+		t.Errorf("The score should be written when the user quits. Output was:\n%s\n", output)
+	}
+}
+
+// cancelOnWrite calls cancel once a write contains the text.
+type cancelOnWrite struct {
+	w      io.Writer
+	text   string
+	cancel func()
+}
+
+func (c cancelOnWrite) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	if strings.Contains(string(b), c.text) {
+		c.cancel()
+	}
+	return n, err
+}
+
+// TestInterrupt checks that a session whose context is cancelled while it
+// waits for the user ends like a quit, with the score, in both modes.
+func TestInterrupt(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("q1", "a1")
+	qa.AddEntry("q2", "a2")
+
+	for _, interactive := range []bool{true, false} {
+		ctx, cancel := context.WithCancel(context.Background())
+		userIn, userOut := io.Pipe()
+		ip := getGenericInterrogationParameters()
+		ip.interactive = interactive
+		ip.graded = interactive
+		ip.minWait, ip.maxWait = time.Hour, time.Hour
+		ip.in = userIn
+		ip.noColor = true
+
+		var result SessionResult
+		pr, pw := io.Pipe()
+		// The session is interrupted while the last question shown waits.
+		last := "q1"
+		if interactive {
+			last = "q2"
+		}
+		ip.out = cancelOnWrite{w: pw, text: last, cancel: cancel}
+		go func() {
+			defer pw.Close()
+			result = AskQuestionsContext(ctx, qa, ip)
+		}()
+		go func() {
+			if interactive {
+				fmt.Fprintln(userOut, "a1")
+			}
+		}()
+		output, _ := ioutil.ReadAll(pr)
+		userOut.Close()
+
+		if !result.Interrupted || result.Quit {
+			t.Errorf("The session should be interrupted but we got %+v\n", result)
+		}
+		if interactive && (result.Position != 1 || !strings.Contains(string(output), "Score: 1/1 (100%)")) {
+			t.Errorf("The question answered should be kept and the score written. Output was:\n%s\n", output)
+		}
+	}
+}
+
+// TestParseSkipHeader checks that the header row of the file is only
+// skipped with the option, and only when it is the first line.
+func TestParseSkipHeader(t *testing.T) {
+	deck := "\nQuestion;Answer\n### Lesson 1\nmanger;to eat\nboire;to drink\n"
+
+	tpp := getTpp()
+	topic := ParseTopic(strings.NewReader(deck), tpp)
+	if !reflect.DeepEqual(topic.BuildQuestionsSet().questions, []string{"Question", "manger", "boire"}) {
+		t.Errorf("Without the option the header row is a question: %v\n", topic.BuildQuestionsSet().questions)
+	}
+
+	tpp.SkipHeader = true
+	topic = ParseTopic(strings.NewReader(deck), tpp)
+	if !reflect.DeepEqual(topic.BuildQuestionsSet().questions, []string{"manger", "boire"}) {
+		t.Errorf("The header row should be skipped: %v\n", topic.BuildQuestionsSet().questions)
+	}
+
+	// Without header row, the first question of the subsection is kept.
+	topic = ParseTopic(strings.NewReader("### Lesson 1\nmanger;to eat\nboire;to drink\n"), tpp)
+	if !reflect.DeepEqual(topic.BuildQuestionsSet().questions, []string{"manger", "boire"}) {
+		t.Errorf("Only a row before any subsection can be a header: %v\n", topic.BuildQuestionsSet().questions)
+	}
+}
+
+// TestParseQuestionsOnly checks that the lines without separator are
+// questions with an empty answer with the QuestionsOnly option, the
+// announces of the subsections being still detected.
+func TestParseQuestionsOnly(t *testing.T) {
+	deck := "### Lesson 1\nDescribe your house\nTalk about your family\n### Lesson 2\nmanger;to eat\n"
+
+	tpp := getTpp()
+	topic := ParseTopic(strings.NewReader(deck), tpp)
+	if qa := topic.BuildQuestionsSet(); qa.GetCount() != 1 {
+		t.Errorf("Without the option the lines without separator are dropped: %v\n", qa.questions)
+	}
+
+	tpp.QuestionsOnly = true
+	topic = ParseTopic(strings.NewReader(deck), tpp)
+	if !reflect.DeepEqual(topic.GetSubsectionsName(), []string{"1", "2"}) {
+		t.Errorf("The subsections should still be announced but we got %v\n", topic.GetSubsectionsName())
+	}
+	lesson1 := topic.GetSubsection("1")
+	if !reflect.DeepEqual(lesson1.questions, []string{"Describe your house", "Talk about your family"}) || !reflect.DeepEqual(lesson1.answers, []string{"", ""}) {
+		t.Errorf("The lines should be questions with an empty answer but we got %v and %v\n", lesson1.questions, lesson1.answers)
+	}
+	if lesson2 := topic.GetSubsection("2"); lesson2.answers[0] != "to eat" {
+		t.Errorf("The lines with an answer should be unchanged but we got '%s'\n", lesson2.answers[0])
+	}
+	if err := lesson1.Validate(); err != nil {
+		t.Errorf("The questions set is not consistent: %v\n", err)
+	}
+}
+
+// TestLoopFooter checks that the loop is announced after the answer of its
+// last question, and not before its first question, with the loopFooter
+// option.
+func TestLoopFooter(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("q1", "a1")
+	qa.AddEntry("q2", "a2")
+
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.limit = 2
+	ip.noColor = true
+	ip.loopFooter = true
+	lines := getSessionOutput(qa, ip)
+
+	expected := []string{
+		"Nb of questions: 2",
+		"q1     --> a1", "---------------------------",
+		"q2     --> a2", "---------------------------",
+		"End of loop 1/2",
+		"q1     --> a1", "---------------------------",
+		"q2     --> a2", "---------------------------",
+		"End of loop 2/2",
+		"Limit reached. Exiting. Number of loops set to: 2",
+	}
+	if !reflect.DeepEqual(lines, expected) {
+		t.Errorf("The loops should be announced at their end. Output was:\n%s\n", strings.Join(lines, "\n"))
+	}
+}
+
+// TestTimeLoops checks that the time taken by each loop is measured with
+// the clock and written at the end of the loop.
+func TestTimeLoops(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("q1", "a1")
+	qa.AddEntry("q2", "a2")
+	qa.AddEntry("q3", "a3")
+
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.limit = 2
+	ip.minWait, ip.maxWait = 2*time.Second, 2*time.Second
+	ip.clock = &fakeClock{now: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)}
+	ip.timeLoops = true
+
+	var result SessionResult
+	pr, pw := io.Pipe()
+	ip.out = pw
+	go func() {
+		defer pw.Close()
+		result = AskQuestions(qa, ip)
+	}()
+	output, _ := ioutil.ReadAll(pr)
+
+	if !reflect.DeepEqual(result.LoopDurations, []time.Duration{6 * time.Second, 6 * time.Second}) {
+		t.Errorf("Each loop should have taken 6s but we got %v\n", result.LoopDurations)
+	}
+	if !strings.Contains(string(output), "q3     --> a3\n---------------------------\nLoop 1 took 6.0s\n") ||
+		!strings.Contains(string(output), "Loop 2 took 6.0s\n") {
+		t.Errorf("The time of each loop should be written at its end. Output was:\n%s\n", output)
+	}
+}
+
+// TestStuckOutput checks that a session whose output never finishes ends
+// after the shutdown wait with a warning instead of hanging.
+func TestStuckOutput(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("q1", "a1")
+
+	var warning bytes.Buffer
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.limit = 1
+	ip.verboseOut = &warning
+	ip.shutdownWait = 10 * time.Millisecond
+	// The events are read but the end of the session never returns.
+	session := NewSession(qa, ip)
+	session.Listen(func(e Event) {
+		if e.Kind == SessionEnded {
+			select {}
+		}
+	})
+
+	done := make(chan struct{})
+	go func() {
+		session.Run(context.Background())
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("The session is still waiting for its output after the shutdown wait.")
+	}
+	if !strings.Contains(warning.String(), "did not finish") {
+		t.Errorf("A warning should tell that the output did not finish but we got '%s'\n", warning.String())
+	}
+}
+
+// TestAppendFromReader checks that the subsections of a second deck are
+// added to a topic, the ones already there getting the new questions after
+// their own.
+func TestAppendFromReader(t *testing.T) {
+	tpp := getTpp()
+	topic := ParseTopic(strings.NewReader("### Lesson 1\nmanger;to eat\n### Lesson 2\nrouge;red\n"), tpp)
+	before := topic.GetSubsection("1")
+
+	err := topic.AppendFromReader(strings.NewReader("### Lesson 2\nbleu;blue\n### Lesson 3\nun;one\ndeux;two\n"), tpp)
+	if err != nil {
+		t.Fatalf("Appending the deck failed: %v", err)
+	}
+	if !reflect.DeepEqual(topic.GetSubsectionsName(), []string{"1", "2", "3"}) {
+		t.Errorf("The new subsection should be added after the others but we got %v\n", topic.GetSubsectionsName())
+	}
+	if qa := topic.GetSubsection("2"); !reflect.DeepEqual(qa.questions, []string{"rouge", "bleu"}) {
+		t.Errorf("The questions of the subsection found twice should be concatenated but we got %v\n", qa.questions)
+	}
+	if count := topic.BuildQuestionsSet().GetCount(); count != 5 {
+		t.Errorf("The topic should hold 5 questions but holds %d\n", count)
+	}
+	if before.GetCount() != 1 {
+		t.Errorf("The set obtained before the append should be unchanged but holds %v\n", before.questions)
+	}
+
+	if err := topic.AppendFromReader(iotest.ErrReader(errors.New("disk failure")), tpp); err == nil {
+		t.Errorf("The failure of the reader should be returned.")
+	}
+	if count := topic.BuildQuestionsSet().GetCount(); count != 5 {
+		t.Errorf("A failed append should leave the topic unchanged but it holds %d questions\n", count)
+	}
+}
+
+// TestAdjustWait checks that + typed during an unattended session
+// lengthens the wait before the answer.
+func TestAdjustWait(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("q1", "a1")
+
+	var verbose bytes.Buffer
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.limit = 1
+	ip.minWait, ip.maxWait = 50*time.Millisecond, 50*time.Millisecond
+	ip.adjustWait = true
+	ip.verboseOut = &verbose
+	// The command is received during the wait of the first answer.
+	ip.in = strings.NewReader("+\n")
+
+	start := time.Now()
+	getSessionOutput(qa, ip)
+	if elapsed := time.Since(start); elapsed < 550*time.Millisecond {
+		t.Errorf("The wait should have been lengthened to 550ms but the session took %v\n", elapsed)
+	}
+	if verbose.String() != "Wait: 550ms\n" {
+		t.Errorf("The new wait should be told but we got '%s'\n", verbose.String())
+	}
+}
+
+// TestChangeWait checks that the wait is changed by a step, keeping its
+// range, and never gets shorter than a step.
+func TestChangeWait(t *testing.T) {
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.minWait, ip.maxWait = time.Second, 2*time.Second
+	if !ip.changeWait("+") || ip.minWait != 1500*time.Millisecond || ip.maxWait != 2500*time.Millisecond {
+		t.Errorf("+ should lengthen the range by a step but we got %v-%v\n", ip.minWait, ip.maxWait)
+	}
+	ip.changeWait("-")
+	ip.changeWait("-")
+	ip.changeWait("-")
+	if ip.minWait != waitStep || ip.maxWait != 1500*time.Millisecond {
+		t.Errorf("- should not shorten the wait under a step but we got %v-%v\n", ip.minWait, ip.maxWait)
+	}
+	if ip.changeWait("r") {
+		t.Errorf("Only + and - change the wait.")
+	}
+}
+
+// TestParseQuotedFields checks that the fields between quotes may hold the
+// separator, line breaks and quotes, unless the legacy split is asked.
+func TestParseQuotedFields(t *testing.T) {
+	deck := "### Lesson 1\n\"manger; dîner\";to eat\ndire;\"to say \"\"yes\"\"\"\nécrire;\"to write\nby hand\"\nlire;to read;to study\n"
+	qa := ParseTopic(strings.NewReader(deck), getTpp()).BuildQuestionsSet()
+	expectedQuestions := []string{"manger; dîner", "dire", "écrire", "lire"}
+	expectedAnswers := []string{"to eat", `to say "yes"`, "to write\nby hand", "to read;to study"}
+	if !reflect.DeepEqual(qa.questions, expectedQuestions) || !reflect.DeepEqual(qa.answers, expectedAnswers) {
+		t.Errorf("The quoted fields are wrong: %q %q\n", qa.questions, qa.answers)
+	}
+
+	tpp := getTpp()
+	tpp.LegacySplit = true
+	qa = ParseTopic(strings.NewReader(deck), tpp).BuildQuestionsSet()
+	if qa.questions[0] != `"manger` || qa.answers[0] != ` dîner";to eat` {
+		t.Errorf("The legacy split should not read the quotes but we got %q %q\n", qa.questions, qa.answers)
+	}
+
+	tpp = getTpp()
+	tpp.QaSep = " - "
+	qa = ParseTopic(strings.NewReader("### Lesson 1\n\"manger\" - to eat\n"), tpp).BuildQuestionsSet()
+	if qa.questions[0] != `"manger"` {
+		t.Errorf("A separator of several characters should be split without the quotes but we got %q\n", qa.questions)
+	}
+
+	qa = ParseTopic(strings.NewReader("### Lesson 1\nil a dit \"oui\";he said \"yes\"\n"), getTpp()).BuildQuestionsSet()
+	if qa.questions[0] != `il a dit "oui"` || qa.answers[0] != `he said "yes"` {
+		t.Errorf("The quotes inside a field should be kept but we got %q %q\n", qa.questions, qa.answers)
+	}
+
+	// A field starting with a quote that does not end it must not swallow
+	// the lines after it.
+	for _, malformed := range []string{"### Lesson 1\n\"Hello\" he said;bonjour\nmanger;to eat\n", "### Lesson 1\n\"Hello;bonjour\nmanger;to eat\n"} {
+		_, _, err := ParseTopicWithWarnings(strings.NewReader(malformed), getTpp())
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) || parseErr.Line != 2 {
+			t.Errorf("The quote of line 2 should fail the parsing of %q but we got %v\n", malformed, err)
+		}
+	}
+}
+
+// TestParseMultiLineAnswers checks that an answer goes on over the lines
+// after a \ and over the indented lines that follow it.
+func TestParseMultiLineAnswers(t *testing.T) {
+	deck := "### Lesson 1\nmanger;to eat \\\n  to dine\\\nto have a meal\nboire;to drink\n  the water\n\tthe wine\ndormir;to sleep\n  \nlire;to read\n"
+	for _, legacy := range []bool{false, true} {
+		tpp := getTpp()
+		tpp.LegacySplit = legacy
+		qa := ParseTopic(strings.NewReader(deck), tpp).BuildQuestionsSet()
+		expectedAnswers := []string{"to eat\nto dine\nto have a meal", "to drink\nthe water\nthe wine", "to sleep", "to read"}
+		if !reflect.DeepEqual(qa.questions, []string{"manger", "boire", "dormir", "lire"}) || !reflect.DeepEqual(qa.answers, expectedAnswers) {
+			t.Errorf("The answers on several lines are wrong with the legacy split %v: %q %q\n", legacy, qa.questions, qa.answers)
+		}
+	}
+
+	// A \ before a card or a blank line is kept, like an escaped one.
+	deck = "### Lesson 1\nvoir;to see \\\nlire;to read\nboire;to drink\\\n\n  the water\nécrire;C:\\\\\n  D:\n"
+	for _, legacy := range []bool{false, true} {
+		tpp := getTpp()
+		tpp.LegacySplit = legacy
+		qa := ParseTopic(strings.NewReader(deck), tpp).BuildQuestionsSet()
+		expectedAnswers := []string{`to see\`, "to read", `to drink\`, "C:\\\nD:"}
+		if !reflect.DeepEqual(qa.questions, []string{"voir", "lire", "boire", "écrire"}) || !reflect.DeepEqual(qa.answers, expectedAnswers) {
+			t.Errorf("The answers ending with a \\ are wrong with the legacy split %v: %q %q\n", legacy, qa.questions, qa.answers)
+		}
+	}
+}
+
+// TestWriteMultiLineAnswer checks that the lines of an answer are written
+// as a block under the arrow.
+func TestWriteMultiLineAnswer(t *testing.T) {
+	var out strings.Builder
+	rendering{}.writeAnswer(&out, "to drink\nthe water", 5)
+	indent := strings.Repeat(" ", len(answerArrow))
+	if expected := answerArrow + "to drink\n" + indent + "the water\n"; out.String() != expected {
+		t.Errorf("The answer should be written as\n%s\nbut we got\n%s\n", expected, out.String())
+	}
+}
+
+// TestParseTopicWithWarnings checks that the problems of a deck are
+// reported with their line, the deck being read anyway.
+func TestParseTopicWithWarnings(t *testing.T) {
+	deck := "avant;before\n### Lesson 1\nmanger;to eat\nnot a question\nboire;\n\n### Lesson 2\ncourir;to run\n### Lesson 1\n;to drink\n"
+	topic, warnings, err := ParseTopicWithWarnings(strings.NewReader(deck), getTpp())
+	if err != nil {
+		t.Fatalf("The deck should be read but we got %v\n", err)
+	}
+	expected := []string{
+		"Line 1: The question avant is before any topic.",
+		"Line 4: The line is neither a topic nor a question: the separator ; is missing.",
+		"Line 5: The question boire has no answer.",
+		"Line 9: The topic 1 is also announced at line 2: its questions are added to the ones of this line.",
+		"Line 10: The question is empty.",
+	}
+	var found []string
+	for _, warning := range warnings {
+		found = append(found, warning.String())
+	}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("The warnings should be:\n%s\nbut we got:\n%s\n", strings.Join(expected, "\n"), strings.Join(found, "\n"))
+	}
+	if topic.GetSubsection("1").GetCount() != 3 || topic.GetSubsection("").GetCount() != 1 {
+		t.Errorf("The questions should be read despite the warnings but we got %v\n", topic.GetSubsectionsName())
+	}
+}
+
+// deckGenerator writes a deck of count questions, one topic every hundred
+// questions with blank lines around, without holding it in memory.
+type deckGenerator struct {
+	count   int
+	written int
+	pending []byte
+}
+
+func (g *deckGenerator) Read(b []byte) (int, error) {
+	for len(g.pending) == 0 {
+		if g.written == g.count {
+			return 0, io.EOF
+		}
+		if g.written%100 == 0 {
+			g.pending = append(g.pending, fmt.Sprintf("\n\n### Lesson %d\n\n", g.written/100)...)
+		}
+		g.pending = append(g.pending, fmt.Sprintf("question %d;answer %d\n", g.written, g.written)...)
+		g.written++
+	}
+	n := copy(b, g.pending)
+	g.pending = g.pending[n:]
+	return n, nil
+}
+
+// TestParseLargeDeck checks that a large deck read as a stream gives its
+// questions exactly, without empty entries for the blank lines.
+func TestParseLargeDeck(t *testing.T) {
+	topic := ParseTopic(&deckGenerator{count: 100000}, getTpp())
+	if topic.GetSubsectionsCount() != 1000 {
+		t.Errorf("The deck should have 1000 topics but we got %d\n", topic.GetSubsectionsCount())
+	}
+	qa := topic.BuildQuestionsSet()
+	if qa.GetCount() != 100000 {
+		t.Fatalf("The deck should have 100000 questions but we got %d\n", qa.GetCount())
+	}
+	for i := 0; i < qa.GetCount(); i++ {
+		if qa.questions[i] != fmt.Sprintf("question %d", i) || qa.answers[i] != fmt.Sprintf("answer %d", i) {
+			t.Fatalf("The entry %d should be question %d but we got '%s' and '%s'\n", i, i, qa.questions[i], qa.answers[i])
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// MarkdownParsingParameters tells how a deck written in Markdown is read.
+// It is the counterpart of TopicParsingParameters for the csv decks.
+type MarkdownParsingParameters struct {
+	// HeadingPrefix starts the headings announcing a subsection, for
+	// instance '## '. The text after it is the id of the subsection. The
+	// other headings are ignored.
+	HeadingPrefix string
+	// CardSep separates the question from the answer on the line of a
+	// card, for instance 'manger :: to eat'. The spaces around the question
+	// and the answer are removed.
+	CardSep string
+	// Tables tells that the rows of the tables are cards too, the first
+	// cell being the question and the second one the answer. The header
+	// row of each table is not a card.
+	Tables bool
+	// Normalize tells that the questions and the answers are normalized like
+	// with TopicParsingParameters.
+	Normalize bool
+	// TagPrefix is the prefix of the tags ending the answer, like with
+	// TopicParsingParameters. If empty, no tag is extracted.
+	TagPrefix string
+}
+
+// The default Markdown syntax of the decks.
+const (
+	defaultHeadingPrefix = "## "
+	defaultCardSep       = "::"
+)
+
+// markdownParameters returns the parameters to read a Markdown deck with
+// the default syntax and the options of the csv parameters that apply.
+func markdownParameters(p TopicParsingParameters) MarkdownParsingParameters {
+	return MarkdownParsingParameters{
+		HeadingPrefix: defaultHeadingPrefix,
+		CardSep:       defaultCardSep,
+		Tables:        true,
+		Normalize:     p.Normalize,
+		TagPrefix:     p.TagPrefix,
+	}
+}
+
+// ParseTopicMarkdown reads a deck written in Markdown. For instance:
+//
+//	## Verbs
+//	- manger :: to eat
+//	- boire :: to drink
+//
+//	## Nouns
+//	| French | English |
+//	|--------|---------|
+//	| pain   | bread   |
+//
+// The list markers before the cards are optional. The cards found before
+// the first heading are in a subsection with an empty id, and the code
+// blocks are ignored.
+func ParseTopicMarkdown(r io.Reader, p MarkdownParsingParameters) (Topic, error) {
+	topic := NewTopic()
+	var id string
+	inCode := false // the line is in a fenced code block
+	inTable := false
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		input := strings.TrimSpace(s.Text())
+		if strings.HasPrefix(input, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			continue
+		}
+		var question, answer string
+		switch {
+		case strings.HasPrefix(input, p.HeadingPrefix):
+			id = strings.TrimSpace(strings.TrimPrefix(input, p.HeadingPrefix))
+			inTable = false
+			continue
+		case p.Tables && strings.HasPrefix(input, "|"):
+			cells := tableCells(input)
+			header := !inTable
+			inTable = true
+			if header || isDelimiterRow(cells) || len(cells) < 2 {
+				continue
+			}
+			question, answer = cells[0], cells[1]
+		default:
+			inTable = false
+			card := strings.TrimPrefix(strings.TrimPrefix(input, "- "), "* ")
+			split := strings.SplitN(card, p.CardSep, 2)
+			if len(split) != 2 {
+				continue
+			}
+			question, answer = strings.TrimSpace(split[0]), strings.TrimSpace(split[1])
+		}
+		if len(question) == 0 {
+			return topic, &ParseError{Line: line, msg: fmt.Sprintf("The card of the Markdown deck has no question (line %d).", line)}
+		}
+		var tags []string
+		if p.TagPrefix != "" {
+			answer, tags = extractTags(answer, p.TagPrefix)
+		}
+		if p.Normalize {
+			question, answer = normalizeText(question), normalizeText(answer)
+		}
+		qa := topic.GetSubsection(id)
+		qa.AddTaggedEntry(question, answer, tags)
+		topic.SetSubsection(id, qa)
+	}
+	return topic, s.Err()
+}
+
+// tableCells returns the cells of a row of a Markdown table, without their
+// spaces.
+func tableCells(row string) []string {
+	row = strings.TrimSuffix(strings.TrimPrefix(row, "|"), "|")
+	cells := strings.Split(row, "|")
+	for n := range cells {
+		cells[n] = strings.TrimSpace(cells[n])
+	}
+	return cells
+}
+
+// isDelimiterRow tells if the cells are the ones of the row separating the
+// header of a Markdown table from its body, like |---|:--:|.
+func isDelimiterRow(cells []string) bool {
+	for _, cell := range cells {
+		if len(strings.Trim(cell, ":-")) != 0 || !strings.Contains(cell, "-") {
+			return false
+		}
+	}
+	return true
+}
+
+// isMarkdownDeck tells from its path if a deck is written in Markdown.
+func isMarkdownDeck(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
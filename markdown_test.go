@@ -0,0 +1,44 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseTopicMarkdown checks the cards of the lists and of the tables,
+// and their subsections.
+func TestParseTopicMarkdown(t *testing.T) {
+	deck := "# French\nSome notes.\n\n## Verbs\n- manger :: to eat #food\n* boire::to drink\ndormir :: to sleep\n\n" +
+		"```\ncode :: not a card\n```\n\n## Nouns\n| French | English |\n|:------|-------:|\n| pain | bread |\n| eau | water |\n"
+	p := markdownParameters(TopicParsingParameters{TagPrefix: "#"})
+	topic, err := ParseTopicMarkdown(strings.NewReader(deck), p)
+	if err != nil {
+		t.Fatalf("A valid Markdown deck should not trigger an error: %v", err)
+	}
+	if names := topic.GetSubsectionsName(); !reflect.DeepEqual(names, []string{"Verbs", "Nouns"}) {
+		t.Errorf("The subsections should be Verbs and Nouns but we got %v\n", names)
+	}
+	verbs := topic.GetSubsection("Verbs")
+	if !reflect.DeepEqual(verbs.questions, []string{"manger", "boire", "dormir"}) || !reflect.DeepEqual(verbs.answers, []string{"to eat", "to drink", "to sleep"}) {
+		t.Errorf("The cards of the list are wrong: %v %v\n", verbs.questions, verbs.answers)
+	}
+	if !reflect.DeepEqual(verbs.tags[0], []string{"food"}) {
+		t.Errorf("The tags of the answer should be extracted but we got %v\n", verbs.tags[0])
+	}
+	nouns := topic.GetSubsection("Nouns")
+	if !reflect.DeepEqual(nouns.questions, []string{"pain", "eau"}) || !reflect.DeepEqual(nouns.answers, []string{"bread", "water"}) {
+		t.Errorf("The rows of the table are wrong: %v %v\n", nouns.questions, nouns.answers)
+	}
+}
+
+// TestParseTopicMarkdownErrors checks that a card without question is
+// reported with its line, and that the extension selects the format.
+func TestParseTopicMarkdownErrors(t *testing.T) {
+	_, err := ParseDeck("deck.md", strings.NewReader("## Verbs\nmanger :: to eat\n :: to drink\n"), getTpp())
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Line != 3 {
+		t.Errorf("The failure should be a ParseError at line 3 but we got %v\n", err)
+	}
+}
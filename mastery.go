@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// requiresPrefix introduces the tag a question requires, at the end of its
+// answer, like in 'aller;to go #irregular @requires:basics'.
+const requiresPrefix = "@requires:"
+
+// GetMasteryPath returns the path of the file counting the correct answers
+// in a row of each question. Empty means that the prerequisites are not
+// checked.
+func (p InterrogationParameters) GetMasteryPath() string {
+	return p.mastery
+}
+
+// Mastery counts for each question, keyed by its text, the correct answers
+// given in a row. A question is mastered after masteredStreak of them, like
+// the leeches.
+type Mastery map[string]int
+
+// ReadMastery reads the counts saved in JSON by Write.
+func ReadMastery(r io.Reader) (Mastery, error) {
+	mastery := make(Mastery)
+	if err := json.NewDecoder(r).Decode(&mastery); err != nil {
+		return nil, err
+	}
+	return mastery, nil
+}
+
+// Write saves the counts in JSON.
+func (m Mastery) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(m)
+}
+
+// Update records the answers of the session on the questions set: a
+// question answered correctly gets one more correct answer in a row, a
+// question missed starts again from 0.
+func (m Mastery) Update(qa QuestionsAnswers, r SessionResult) {
+	for _, i := range r.Right {
+		if !containsIndex(r.Wrong, i) {
+			m[qa.questions[i]]++
+		}
+	}
+	for _, i := range r.Wrong {
+		m[qa.questions[i]] = 0
+	}
+}
+
+// IsTagMastered tells if all the questions of the deck carrying the tag are
+// mastered. A tag that no question carries has nothing to master.
+func (m Mastery) IsTagMastered(deck QuestionsAnswers, tag string) bool {
+	for i := 0; i < deck.GetCount(); i++ {
+		if deck.HasTag(i, tag) && m[deck.questions[i]] < masteredStreak {
+			return false
+		}
+	}
+	return true
+}
+
+// FilterPrerequisites returns a new set without the questions whose
+// prerequisites are not mastered, and the number of questions left out. The
+// questions carrying the tags required are looked for in the whole deck,
+// not only in the set.
+func (m Mastery) FilterPrerequisites(qa QuestionsAnswers, deck QuestionsAnswers) (QuestionsAnswers, int) {
+	filtered := NewQA()
+	gated := 0
+	for i := 0; i < qa.GetCount(); i++ {
+		ready := true
+		for _, tag := range qa.requires[i] {
+			ready = ready && m.IsTagMastered(deck, tag)
+		}
+		if !ready {
+			gated++
+			continue
+		}
+		filtered.appendEntryFrom(qa, i)
+	}
+	return filtered, gated
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// getPrerequisitesDeck returns a deck where the irregular verb requires
+// the basic ones to be mastered.
+func getPrerequisitesDeck() QuestionsAnswers {
+	tpp := getTpp()
+	tpp.TagPrefix = "#"
+	tpp.Prerequisites = true
+	deck := "### Lesson 1\nmanger;to eat #basics\nparler;to speak #basics\naller;to go #irregular @requires:basics\n"
+	return ParseTopic(strings.NewReader(deck), tpp).BuildQuestionsSet()
+}
+
+// TestParsePrerequisites checks that the prerequisite is removed from the
+// answer, its tags being kept.
+func TestParsePrerequisites(t *testing.T) {
+	qa := getPrerequisitesDeck()
+	if qa.answers[2] != "to go" || !reflect.DeepEqual(qa.tags[2], []string{"irregular"}) {
+		t.Errorf("The answer should be 'to go' tagged irregular but we got '%s' and %v\n", qa.answers[2], qa.tags[2])
+	}
+	if !reflect.DeepEqual(qa.requires[2], []string{"basics"}) || qa.requires[0] != nil {
+		t.Errorf("Only the irregular verb should require basics but we got %v\n", qa.requires)
+	}
+}
+
+// TestFilterPrerequisites checks that a question is left out until the
+// questions it requires are answered correctly enough times in a row.
+func TestFilterPrerequisites(t *testing.T) {
+	deck := getPrerequisitesDeck()
+	mastery := make(Mastery)
+	session := SessionResult{Right: []int{0, 1}}
+
+	for n := 0; n < masteredStreak; n++ {
+		filtered, gated := mastery.FilterPrerequisites(deck, deck)
+		if gated != 1 || filtered.GetCount() != 2 {
+			t.Errorf("After %d sessions the irregular verb should be left out but we got %v\n", n, filtered.questions)
+		}
+		mastery.Update(deck, session)
+	}
+	if filtered, gated := mastery.FilterPrerequisites(deck, deck); gated != 0 || filtered.GetCount() != 3 {
+		t.Errorf("Once the basics are mastered, the irregular verb should be asked but we got %v\n", filtered.questions)
+	}
+
+	// A mistake on a basic verb locks the irregular one again.
+	mastery.Update(deck, SessionResult{Wrong: []int{1}, Right: []int{0}})
+	if _, gated := mastery.FilterPrerequisites(deck, deck); gated != 1 {
+		t.Errorf("A mistake on the basics should leave the irregular verb out again.")
+	}
+
+	var out bytes.Buffer
+	mastery.Write(&out)
+	read, err := ReadMastery(&out)
+	if err != nil || !reflect.DeepEqual(read, mastery) {
+		t.Errorf("The counts should be read back but we got %v and %v\n", read, err)
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"math/rand"
+)
+
+// MatchingExercise prepares an exercise where the user pairs the questions
+// with the answers listed in another order. key[i] is the index in
+// scrambledAnswers of the answer to the question i.
+//
+// When there are at least 2 entries, no answer stays at the position of its
+// question: the answers are moved along a random cycle (Sattolo's
+// algorithm).
+func (qa QuestionsAnswers) MatchingExercise(rng *rand.Rand) (questions []string, scrambledAnswers []string, key []int) {
+	count := qa.GetCount()
+	questions = make([]string, count)
+	copy(questions, qa.questions)
+
+	key = make([]int, count)
+	for i := range key {
+		key[i] = i
+	}
+	for i := count - 1; i > 0; i-- {
+		j := rng.Intn(i)
+		key[i], key[j] = key[j], key[i]
+	}
+
+	scrambledAnswers = make([]string, count)
+	for i, position := range key {
+		scrambledAnswers[position] = qa.answers[i]
+	}
+	return questions, scrambledAnswers, key
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// TestMatchingExercise checks that the key maps each question to its answer
+// and that no answer stays in front of its question.
+func TestMatchingExercise(t *testing.T) {
+	qa := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp()).BuildQuestionsSet()
+
+	for seed := int64(0); seed < 20; seed++ {
+		questions, answers, key := qa.MatchingExercise(rand.New(rand.NewSource(seed)))
+		if len(questions) != qa.GetCount() || len(answers) != qa.GetCount() || len(key) != qa.GetCount() {
+			t.Fatalf("The exercise should have %d entries.", qa.GetCount())
+		}
+		for i, q := range questions {
+			if answers[key[i]] != qa.answers[i] || q != qa.questions[i] {
+				t.Errorf("The key does not map the question '%s' to its answer. Found '%s'\n", q, answers[key[i]])
+			}
+			if key[i] == i {
+				t.Errorf("The answer of the question '%s' was not moved (seed %d).", q, seed)
+			}
+		}
+	}
+}
+
+// TestMatchingExerciseSingleEntry checks that a set of one entry gives an
+// exercise where the answer cannot move.
+func TestMatchingExerciseSingleEntry(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("question", "answer")
+	_, answers, key := qa.MatchingExercise(rand.New(rand.NewSource(1)))
+	if len(key) != 1 || key[0] != 0 || answers[0] != "answer" {
+		t.Errorf("A single entry should give a trivial exercise but we got %v %v\n", answers, key)
+	}
+}
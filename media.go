@@ -0,0 +1,20 @@
+package main
+
+import "strings"
+
+// extractMedia reads the path of the media file in the last of the fields
+// that follow the question. The fields are returned without it. If the last
+// field is the only one (the answer), the fields are left untouched and the
+// path is empty.
+func extractMedia(fields []string) ([]string, string) {
+	if len(fields) < 2 {
+		return fields, ""
+	}
+	return fields[:len(fields)-1], strings.TrimSpace(fields[len(fields)-1])
+}
+
+// GetMedia returns the path of the media file of the entry of index i. It
+// is empty if the entry has no media.
+func (qa QuestionsAnswers) GetMedia(i int) string {
+	return qa.media[i]
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseMedia checks that the media path is read from the last field and
+// that the lines without media are left untouched.
+func TestParseMedia(t *testing.T) {
+	deck := "### Lesson Verbs\nmanger;to eat;audio/manger.mp3\nboire;to drink\ndormir;to sleep;audio/dormir.mp3;4\n"
+	tpp := getTpp()
+	tpp.MediaField = true
+	tpp.DifficultyField = true
+	tpp.DefaultDifficulty = defaultDifficulty
+	topic := ParseTopic(strings.NewReader(deck), tpp)
+	qa := topic.GetSubsection("Verbs")
+
+	expected := []string{"audio/manger.mp3", "", "audio/dormir.mp3"}
+	for i, media := range expected {
+		if qa.GetMedia(i) != media {
+			t.Errorf("The media of '%s' should be '%s' but we got '%s'\n", qa.questions[i], media, qa.GetMedia(i))
+		}
+	}
+	if !reflect.DeepEqual(qa.answers, []string{"to eat", "to drink", "to sleep"}) {
+		t.Errorf("The media path must not be part of the answers: %v\n", qa.answers)
+	}
+	if qa.GetDifficulty(2) != 4 {
+		t.Errorf("The difficulty after the media path is lost. Found %d\n", qa.GetDifficulty(2))
+	}
+}
+
+// TestPlaybackHook checks that the hook is called with the media path of
+// the cards shown, and only for the cards that have one.
+func TestPlaybackHook(t *testing.T) {
+	qa := NewQA()
+	qa.addEntry(entry{question: "manger", answer: "to eat", media: "audio/manger.mp3"})
+	qa.addEntry(entry{question: "boire", answer: "to drink"})
+
+	var played []string
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.limit = 1
+	ip.PlaybackHook = func(path string) {
+		played = append(played, path)
+	}
+	getSessionOutput(qa, ip)
+
+	if !reflect.DeepEqual(played, []string{"audio/manger.mp3"}) {
+		t.Errorf("The hook should have played audio/manger.mp3 only but it played %v\n", played)
+	}
+}
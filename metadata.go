@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// frontMatterDelimiter opens and closes the front matter of a csv deck.
+const frontMatterDelimiter = "---"
+
+// The directions of the questions a deck can set in its front matter.
+const (
+	forwardDirection  = "forward"  // the questions are the prompts
+	reversedDirection = "reversed" // the answers are the prompts
+)
+
+// DeckMetadata is what a csv deck tells about itself in its front matter,
+// the lines between two '---' at its top:
+//
+//	---
+//	title: French verbs
+//	author: Boris
+//	language: fr
+//	separator: ,
+//	direction: reversed
+//	---
+//
+// The fields not set are empty.
+type DeckMetadata struct {
+	Title  string
+	Author string
+	// Language is the language of the answers, like fr, for instance to
+	// pronounce them.
+	Language string
+	// Separator is the separator between the question and the answer, used
+	// when -sep is not set. The word tab stands for the tab.
+	Separator string
+	// Direction is forward or reversed, the default direction of the
+	// questions of the deck.
+	Direction string
+}
+
+// Metadata returns what the deck of the topic tells about itself in its
+// front matter. When several decks are merged, it is the one of the first
+// deck with a front matter.
+func (topic Topic) Metadata() DeckMetadata {
+	return topic.metadata
+}
+
+// ApplyMetadata returns the parameters with the defaults of the deck: its
+// direction is used unless the prompts are chosen with -r or -front.
+func (p InterrogationParameters) ApplyMetadata(m DeckMetadata) InterrogationParameters {
+	if m.Direction == reversedDirection && !p.reversed && !p.answerFirst {
+		p.answerFirst = true
+	}
+	return p
+}
+
+// readFrontMatter reads the front matter at the start of the deck, if any.
+// It returns the reader of the rest of the deck and the number of lines
+// read. The lines that are not a known field are warnings.
+func readFrontMatter(r io.Reader) (DeckMetadata, io.Reader, int, []ParseWarning, error) {
+	var m DeckMetadata
+	reader := bufio.NewReader(r)
+	start, _ := reader.Peek(len(frontMatterDelimiter) + 2)
+	if !strings.HasPrefix(string(start), frontMatterDelimiter+"\n") && !strings.HasPrefix(string(start), frontMatterDelimiter+"\r\n") {
+		return m, reader, 0, nil, nil
+	}
+	var warnings []ParseWarning
+	for line := 1; ; line++ {
+		text, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return m, reader, line, warnings, err
+		}
+		text = strings.TrimRight(text, "\r\n")
+		if line > 1 && text == frontMatterDelimiter {
+			return m, reader, line, warnings, nil
+		}
+		if err == io.EOF {
+			return m, reader, line, warnings, &ParseError{Line: 1, msg: "The front matter of the deck is not closed by a line ---."}
+		}
+		if line == 1 || len(strings.TrimSpace(text)) == 0 {
+			continue
+		}
+		field := strings.SplitN(text, ":", 2)
+		key := strings.ToLower(strings.TrimSpace(field[0]))
+		var value string
+		if len(field) == 2 {
+			value = strings.Trim(strings.TrimSpace(field[1]), `"'`)
+		}
+		warn := func(format string, a ...interface{}) {
+			warnings = append(warnings, ParseWarning{Line: line, Message: fmt.Sprintf(format, a...)})
+		}
+		switch key {
+		case "title":
+			m.Title = value
+		case "author":
+			m.Author = value
+		case "language":
+			m.Language = value
+		case "separator":
+			if value == "tab" {
+				value = "\t"
+			}
+			m.Separator = value
+		case "direction":
+			if value != forwardDirection && value != reversedDirection {
+				warn("The direction %s is unknown. Choose forward or reversed.", value)
+				continue
+			}
+			m.Direction = value
+		default:
+			warn("The field %s of the front matter is unknown.", key)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseFrontMatter checks that the front matter of a deck is read and
+// that its separator is used when none is set.
+func TestParseFrontMatter(t *testing.T) {
+	deck := "---\ntitle: French verbs\nauthor: Boris\nlanguage: fr\nseparator: \"|\"\ndirection: reversed\ncolor: blue\n---\n### Lesson 1\nmanger|to eat\nboire\n"
+	tpp := NewInterrogationParameters().GetTopicParsingParameters()
+	topic, warnings, err := ParseTopicWithWarnings(strings.NewReader(deck), tpp)
+	if err != nil {
+		t.Fatalf("The deck should be read but we got %v\n", err)
+	}
+	expected := DeckMetadata{Title: "French verbs", Author: "Boris", Language: "fr", Separator: "|", Direction: "reversed"}
+	if topic.Metadata() != expected {
+		t.Errorf("The metadata should be %+v but we got %+v\n", expected, topic.Metadata())
+	}
+	if qa := topic.GetSubsection("Lesson 1"); qa.GetCount() != 1 || qa.answers[0] != "to eat" {
+		t.Errorf("The separator of the front matter should be used but we got %q\n", qa.answers)
+	}
+	if len(warnings) != 2 || warnings[0].Line != 7 || warnings[1].Line != 11 {
+		t.Errorf("The unknown field and the line without separator should be reported with their line but we got %v\n", warnings)
+	}
+
+	tpp.QaSep, tpp.DetectSeparator = ";", false
+	topic = ParseTopic(strings.NewReader(deck), tpp)
+	if qa := topic.GetSubsection("Lesson 1"); qa.GetCount() != 0 {
+		t.Errorf("The separator set should win over the one of the front matter but we got %q\n", qa.questions)
+	}
+
+	if _, _, err := ParseTopicWithWarnings(strings.NewReader("---\ntitle: French verbs\n### Lesson 1\n"), tpp); err == nil {
+		t.Errorf("A front matter that is not closed should be reported\n")
+	}
+	if topic := ParseTopic(strings.NewReader("### Lesson 1\nmanger;to eat\n"), tpp); topic.Metadata() != (DeckMetadata{}) {
+		t.Errorf("A deck without front matter should have no metadata but we got %+v\n", topic.Metadata())
+	}
+}
+
+// TestApplyMetadata checks that the direction of the deck is used unless
+// the prompts are chosen on the command line.
+func TestApplyMetadata(t *testing.T) {
+	reversed := DeckMetadata{Direction: reversedDirection}
+	if p := NewInterrogationParameters().ApplyMetadata(reversed); !p.isPromptSwapped() {
+		t.Errorf("The deck reversed should prompt with the answers\n")
+	}
+	p, _ := parse(noEnvironment, "-r")
+	if p = p.ApplyMetadata(reversed); !p.isPromptSwapped() || p.IsAnswerInFront() {
+		t.Errorf("The direction of the command line should win\n")
+	}
+	if p := NewInterrogationParameters().ApplyMetadata(DeckMetadata{Direction: forwardDirection}); p.isPromptSwapped() {
+		t.Errorf("The deck forward should prompt with the questions\n")
+	}
+}
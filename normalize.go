@@ -0,0 +1,28 @@
+package main
+
+import "strings"
+
+// typographicReplacer maps the typographic characters that word processors
+// put in the texts to the ones typed on a keyboard.
+var typographicReplacer = strings.NewReplacer(
+	"‘", "'", // left single quote
+	"’", "'", // right single quote
+	"‚", "'", // single low quote
+	"‛", "'", // single high reversed quote
+	"“", "\"", // left double quote
+	"”", "\"", // right double quote
+	"„", "\"", // double low quote
+	"‟", "\"", // double high reversed quote
+	"\u00a0", " ", // non-breaking space
+	"\u202f", " ", // narrow non-breaking space
+	"\u2007", " ", // figure space
+	"–", "-", // en dash
+	"—", "-", // em dash
+)
+
+// normalizeText replaces the curly quotes by straight ones, the
+// non-breaking spaces by regular ones and the dashes by hyphens. The runs
+// of spaces are collapsed to a single space and the text is trimmed.
+func normalizeText(text string) string {
+	return strings.Join(strings.Fields(typographicReplacer.Replace(text)), " ")
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestNormalizeText checks each normalization of the texts.
+func TestNormalizeText(t *testing.T) {
+	cases := []struct {
+		before string
+		after  string
+	}{
+		{"l’école", "l'école"},
+		{"‘single’ and “double”", "'single' and \"double\""},
+		{"„bas‟ ‚mixte‛", "\"bas\" 'mixte'"},
+		{"100\u00a0km et 5\u202f%", "100 km et 5 %"},
+		{"1990–2000 — fin", "1990-2000 - fin"},
+		{"  too   many \t spaces ", "too many spaces"},
+		{"plain text", "plain text"},
+	}
+	for _, c := range cases {
+		if after := normalizeText(c.before); after != c.after {
+			t.Errorf("Normalizing '%s' should give '%s' but we got '%s'\n", c.before, c.after, after)
+		}
+	}
+}
+
+// TestParseNormalize checks that the questions and answers are normalized
+// when the option is set, and only then.
+func TestParseNormalize(t *testing.T) {
+	deck := "### Lesson 1\nl’homme  qui ;  the “man” who\n"
+
+	tpp := getTpp()
+	topic := ParseTopic(strings.NewReader(deck), tpp)
+	qa := topic.GetSubsection("1")
+	if qa.questions[0] != "l’homme  qui " {
+		t.Errorf("The question must not be normalized by default: '%s'\n", qa.questions[0])
+	}
+
+	tpp.Normalize = true
+	topic = ParseTopic(strings.NewReader(deck), tpp)
+	qa = topic.GetSubsection("1")
+	if qa.questions[0] != "l'homme qui" || qa.answers[0] != "the \"man\" who" {
+		t.Errorf("The card should be normalized but we got '%s' and '%s'\n", qa.questions[0], qa.answers[0])
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"runtime"
+	"syscall"
+	"time"
+)
+
+const (
+	// openAttempts is the number of times the opening of a deck is tried
+	// before giving up.
+	openAttempts = 3
+	// openBackoff is the time waited after the first failed opening. It is
+	// doubled after each failure.
+	openBackoff = 200 * time.Millisecond
+)
+
+// opener gives access to the content of a file from its path.
+type opener func(path string) (io.ReadCloser, error)
+
+// openFile is the opener of the files of the operating system.
+func openFile(path string) (io.ReadCloser, error) {
+	return os.Open(path)
+}
+
+// windowsLockErrors are the errors of Windows telling that a file is used
+// by another process: ERROR_SHARING_VIOLATION and ERROR_LOCK_VIOLATION.
+// The syscall package does not name them.
+var windowsLockErrors = []syscall.Errno{32, 33}
+
+// isRetryableOpenError tells if opening a file may succeed later. Files
+// briefly locked by another process, like antivirus or cloud-synced
+// folders do on Windows, can be opened after a while. A missing file, a
+// permission denied or a directory will not change by themselves.
+func isRetryableOpenError(err error) bool {
+	if errors.Is(err, syscall.EBUSY) || errors.Is(err, syscall.EAGAIN) {
+		return true
+	}
+	if runtime.GOOS == "windows" {
+		for _, lock := range windowsLockErrors {
+			if errors.Is(err, lock) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// openWithRetry opens the file at path, trying again after a short wait
+// when the error may be transient. The last error is returned if all the
+// attempts fail.
+func openWithRetry(open opener, path string, c clock) (io.ReadCloser, error) {
+	backoff := openBackoff
+	var err error
+	for attempt := 1; attempt <= openAttempts; attempt++ {
+		var file io.ReadCloser
+		file, err = open(path)
+		if err == nil || !isRetryableOpenError(err) {
+			return file, err
+		}
+		if attempt < openAttempts {
+			c.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+	return nil, err
+}
+
+// ParseTopicFromFile reads the deck at path. The format is chosen from the
+// extension of the path, see ParseDeck. The opening is tried again if the
+// file is briefly locked.
+func ParseTopicFromFile(path string, p TopicParsingParameters) (Topic, error) {
+	return parseTopicFromOpener(openFile, path, p, systemClock{})
+}
+
+// parseTopicFromOpener reads the deck at path given by the opener.
+func parseTopicFromOpener(open opener, path string, p TopicParsingParameters, c clock) (Topic, error) {
+	file, err := openWithRetry(open, path, c)
+	if err != nil {
+		return NewTopic(), err
+	}
+	defer file.Close()
+	return ParseDeck(path, file, p.followIncludes(open, path, c, nil))
+}
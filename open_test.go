@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// flakyOpener fails a given number of times before giving the content.
+type flakyOpener struct {
+	failures int
+	err      error
+	calls    int
+	content  string
+}
+
+func (o *flakyOpener) open(path string) (io.ReadCloser, error) {
+	o.calls++
+	if o.calls <= o.failures {
+		return nil, &os.PathError{Op: "open", Path: path, Err: o.err}
+	}
+	return ioutil.NopCloser(strings.NewReader(o.content)), nil
+}
+
+// TestOpenRetriesTransientErrors checks that a deck briefly locked is
+// loaded eventually.
+func TestOpenRetriesTransientErrors(t *testing.T) {
+	o := &flakyOpener{failures: 2, err: syscall.EBUSY, content: getSampleCsvAsStream()}
+	c := &fakeClock{}
+
+	topic, err := parseTopicFromOpener(o.open, "deck.csv", getTpp(), c)
+	if err != nil {
+		t.Fatalf("The deck should have been loaded after 3 attempts: %v", err)
+	}
+	if o.calls != 3 {
+		t.Errorf("The opening should have been tried 3 times but was tried %d times.", o.calls)
+	}
+	if topic.GetSubsectionsCount() != 3 {
+		t.Errorf("The deck was not parsed. Found %d subsections.", topic.GetSubsectionsCount())
+	}
+	if waited := c.now.Sub(time.Time{}); waited != 3*openBackoff {
+		t.Errorf("The backoff should have waited %v but waited %v.", 3*openBackoff, waited)
+	}
+}
+
+// TestOpenGivesUpAfterAttempts checks that the last error is returned when
+// the deck stays locked.
+func TestOpenGivesUpAfterAttempts(t *testing.T) {
+	o := &flakyOpener{failures: openAttempts, err: syscall.EAGAIN}
+
+	_, err := parseTopicFromOpener(o.open, "deck.csv", getTpp(), &fakeClock{})
+	if err == nil {
+		t.Fatalf("The opening should fail when the deck stays locked.")
+	}
+	if o.calls != openAttempts {
+		t.Errorf("The opening should have been tried %d times but was tried %d times.", openAttempts, o.calls)
+	}
+}
+
+// TestOpenDoesNotRetryLastingErrors checks that a deck missing, denied or
+// that is not a file fails at once.
+func TestOpenDoesNotRetryLastingErrors(t *testing.T) {
+	for _, failure := range []error{os.ErrNotExist, os.ErrPermission, syscall.EISDIR, syscall.ENAMETOOLONG, errors.New("unknown")} {
+		o := &flakyOpener{failures: 1, err: failure}
+
+		_, err := parseTopicFromOpener(o.open, "deck.csv", getTpp(), &fakeClock{})
+		if !errors.Is(err, failure) {
+			t.Errorf("The failure %v should be reported as such. Got %v\n", failure, err)
+		}
+		if o.calls != 1 {
+			t.Errorf("A deck failing with %v should not be opened again but was opened %d times.\n", failure, o.calls)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"io"
+	"math/rand"
+	"os"
+	"time"
+)
+
+// Option sets one of the interrogation parameters. Options are given to
+// NewInterrogationParameters.
+type Option func(p *InterrogationParameters)
+
+// NewInterrogationParameters builds the parameters of an interrogation with
+// the default values, changed by the options. The channels used during the
+// interrogation are ready to use.
+func NewInterrogationParameters(opts ...Option) InterrogationParameters {
+	seed := newSeed()
+	p := InterrogationParameters{
+		interactive:    false,
+		minWait:        2 * time.Second,
+		maxWait:        2 * time.Second,
+		mode:           random,
+		in:             os.Stdin,
+		out:            os.Stdout,
+		verboseOut:     os.Stderr,
+		subsections:    "",
+		announce:       "### ",
+		alternativeSep: defaultAlternativeSep,
+		cramCurve:      "linear",
+		freshCount:     1,
+		distractors:    defaultDistractors,
+		limit:          1,
+		clock:          systemClock{},
+		seed:           seed,
+		rng:            rand.New(rand.NewSource(seed)),
+	}
+	for _, opt := range opts {
+		opt(&p)
+	}
+	return p
+}
+
+// newSeed returns a seed for the random sequence of a session, read from
+// the entropy of the system. The time is used if it cannot be read.
+func newSeed() int64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		return time.Now().UnixNano()
+	}
+	return int64(binary.LittleEndian.Uint64(b[:]))
+}
+
+// WithSeed sets the seed of the random sequence of the session, so that
+// the same questions are asked in the same order by 2 sessions.
+func WithSeed(seed int64) Option {
+	return func(p *InterrogationParameters) {
+		p.setSeed(seed)
+	}
+}
+
+// WithOutput sets the Writer where the questions are written to.
+func WithOutput(out io.Writer) Option {
+	return func(p *InterrogationParameters) {
+		p.out = out
+	}
+}
+
+// WithInput sets the Reader where the commands of the user are read from in
+// interactive mode.
+func WithInput(in io.Reader) Option {
+	return func(p *InterrogationParameters) {
+		p.in = in
+	}
+}
+
+// WithMode sets the order of the questions: LinearMode or RandomMode.
+func WithMode(mode interrogationMode) Option {
+	return func(p *InterrogationParameters) {
+		p.mode = mode
+	}
+}
+
+// WithLimit sets the number of times the questions set is repeated.
+func WithLimit(limit int) Option {
+	return func(p *InterrogationParameters) {
+		p.limit = limit
+	}
+}
+
+// WithInteractive sets if the user has to press Return to get the answer.
+func WithInteractive(interactive bool) Option {
+	return func(p *InterrogationParameters) {
+		p.interactive = interactive
+	}
+}
+
+// WithWait sets the time to wait before the answer is revealed when the
+// interrogation is not interactive.
+func WithWait(wait time.Duration) Option {
+	return func(p *InterrogationParameters) {
+		p.minWait = wait
+		p.maxWait = wait
+	}
+}
+
+// WithReversed sets if the answers are asked instead of the questions.
+func WithReversed(reversed bool) Option {
+	return func(p *InterrogationParameters) {
+		p.reversed = reversed
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestNewInterrogationParametersDefaults checks that the parameters built
+// without options are the same as the ones parsed without arguments.
+func TestNewInterrogationParametersDefaults(t *testing.T) {
+	p := NewInterrogationParameters()
+	if p.interactive || p.mode != random || p.limit != 1 || p.minWait != 2*time.Second {
+		t.Errorf("The default parameters are not the expected ones: %+v\n", p)
+	}
+}
+
+// TestNewInterrogationParametersWithOptions checks that the options are
+// applied and that the parameters can be used for a session.
+func TestNewInterrogationParametersWithOptions(t *testing.T) {
+	pr, pw := io.Pipe()
+	p := NewInterrogationParameters(
+		WithOutput(pw),
+		WithMode(LinearMode),
+		WithLimit(2),
+		WithInteractive(false),
+		WithWait(time.Millisecond),
+		WithReversed(true),
+	)
+	if p.out != pw || p.mode != linear || p.limit != 2 || p.interactive || p.minWait != time.Millisecond || !p.reversed {
+		t.Fatalf("The options were not applied: %+v\n", p)
+	}
+
+	qa := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp()).BuildQuestionsSet()
+	go func() {
+		defer pw.Close()
+		AskQuestions(qa, p)
+	}()
+	validateOutput(getTpp(), qa, *bufio.NewScanner(pr), t, true)
+}
+
+// TestSeed checks that 2 sessions with the same seed ask the same
+// questions in random mode.
+func TestSeed(t *testing.T) {
+	qa := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp()).BuildQuestionsSet()
+	var outputs [2]string
+	for n := range outputs {
+		p, err := parse(noEnvironment, "-seed", "42", "-t", "1", "-loops", "3")
+		if err != nil {
+			t.Fatalf("The seed should be accepted: %v", err)
+		}
+		if p.GetSeed() != 42 {
+			t.Errorf("The seed should be 42 but is %d\n", p.GetSeed())
+		}
+		p.clock = &fakeClock{}
+		outputs[n] = strings.Join(getSessionOutput(qa, p), "\n")
+	}
+	if outputs[0] != outputs[1] {
+		t.Errorf("The sessions should be the same:\n%s\n\n%s\n", outputs[0], outputs[1])
+	}
+	if p := NewInterrogationParameters(WithSeed(42)); p.GetSeed() != 42 || p.rng.Int63() != rand.New(rand.NewSource(42)).Int63() {
+		t.Errorf("The option should start the random sequence from the seed.")
+	}
+	if _, err := parse(noEnvironment, "-seed", "forty-two"); err == nil {
+		t.Errorf("A seed that is not an integer should be refused.")
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import "math/rand"
+
+// GetPerSection returns the maximum number of questions of a subsection
+// asked in a loop. 0 means that there is no maximum.
+func (p InterrogationParameters) GetPerSection() int {
+	return p.perSection
+}
+
+// loopLength returns the number of questions asked in a loop. It is the
+// size of the set unless the questions of a subsection are limited per
+// loop: each subsection then gives at most that many questions, or its
+// pinned questions if there are more. The questions that do not come from
+// a subsection count as one subsection.
+func (p InterrogationParameters) loopLength(qa QuestionsAnswers) int {
+	if p.perSection <= 0 {
+		return qa.GetCount()
+	}
+	pins := make(map[string]int)
+	for i, origin := range qa.origin {
+		if p.isPinned(qa, i) {
+			pins[origin]++
+		}
+	}
+	length := 0
+	for origin, count := range countPerOrigin(qa) {
+		length += sectionQuota(count, pins[origin], p.perSection)
+	}
+	return length
+}
+
+// sectionQuota returns the number of questions asked in a loop from a
+// subsection of count questions, pins of them being pinned: at most max,
+// except that all the pinned questions are asked.
+func sectionQuota(count int, pins int, max int) int {
+	quota := max
+	if count < quota {
+		quota = count
+	}
+	if pins > quota {
+		quota = pins
+	}
+	return quota
+}
+
+// countPerOrigin returns the number of questions of each subsection of the
+// set.
+func countPerOrigin(qa QuestionsAnswers) map[string]int {
+	counts := make(map[string]int)
+	for _, origin := range qa.origin {
+		counts[origin]++
+	}
+	return counts
+}
+
+// loopQuota decides which questions can be asked in a loop: the pinned
+// ones come first, then the questions of each subsection are limited.
+type loopQuota struct {
+	max    int            // maximum number of questions of a subsection in a loop. 0 means no maximum
+	loop   int            // the loop going on, from 0
+	origin []string       // subsection of each question of the set
+	rank   []int          // rank of each question in its subsection
+	counts map[string]int // number of questions of each subsection
+	pinsOf map[string]int // number of pinned questions of each subsection
+	asked  map[string]int // number of questions of each subsection asked in the loop
+	pins   []int          // indexes of the pinned questions, in the order of the set
+	pinned []bool         // tells for each question if it is pinned
+	picked int            // number of questions asked in the loop
+	bag    []int          // in shuffle mode, the questions not drawn yet in the loop, in a random order
+}
+
+// newLoopQuota returns the quota of the questions of the set chosen by the
+// user.
+func (p InterrogationParameters) newLoopQuota(qa QuestionsAnswers) *loopQuota {
+	q := &loopQuota{
+		max:    p.perSection,
+		origin: qa.origin,
+		rank:   make([]int, qa.GetCount()),
+		counts: make(map[string]int),
+		asked:  make(map[string]int),
+		pinsOf: make(map[string]int),
+		pinned: make([]bool, qa.GetCount()),
+	}
+	for i, origin := range qa.origin {
+		q.rank[i] = q.counts[origin]
+		q.counts[origin]++
+		if p.isPinned(qa, i) {
+			q.pins = append(q.pins, i)
+			q.pinned[i] = true
+			q.pinsOf[origin]++
+		}
+	}
+	return q
+}
+
+// startLoop resets the counts of the questions asked for the loop.
+func (q *loopQuota) startLoop(loop int) {
+	q.loop = loop
+	q.asked = make(map[string]int)
+	q.picked = 0
+	q.bag = nil
+}
+
+// nextInBag draws the next question of the loop in shuffle mode. The
+// questions are shuffled at the start of the loop so that each one is
+// drawn once. The ones the quota does not allow are left out.
+func (q *loopQuota) nextInBag(rng *rand.Rand) int {
+	for {
+		if len(q.bag) == 0 {
+			q.bag = rng.Perm(len(q.origin))
+		}
+		i := q.bag[0]
+		q.bag = q.bag[1:]
+		if q.allows(i, false) {
+			return i
+		}
+	}
+}
+
+// nextPinned returns the pinned question to ask, if some of them were not
+// asked yet in the loop.
+func (q *loopQuota) nextPinned() (int, bool) {
+	if q.picked < len(q.pins) {
+		return q.pins[q.picked], true
+	}
+	return 0, false
+}
+
+// allows tells if the question of index i can be asked in the loop after
+// the pinned ones, which are not asked twice. In linear order, each loop
+// takes the next questions of each subsection, so that all of them are
+// asked in turn. Otherwise the first questions drawn of a subsection fill
+// its quota.
+func (q *loopQuota) allows(i int, linear bool) bool {
+	if q.pinned[i] {
+		return false
+	}
+	if q.max <= 0 {
+		return true
+	}
+	count := q.counts[q.origin[i]]
+	if !linear {
+		// The questions are drawn with replacement: a small subsection
+		// could fill the loop too.
+		return q.asked[q.origin[i]] < sectionQuota(count, q.pinsOf[q.origin[i]], q.max)
+	}
+	if count <= q.max {
+		return true
+	}
+	first := q.loop * q.max % count
+	return (q.rank[i]-first+count)%count < q.max
+}
+
+// add records that the question of index i is asked.
+func (q *loopQuota) add(i int) {
+	q.asked[q.origin[i]]++
+	q.picked++
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// getUnevenTopic returns a topic whose subsections have 5, 1 and 3
+// questions. The questions start with the name of their subsection.
+func getUnevenTopic() Topic {
+	deck := "### Lesson A\nA1;a\nA2;a\nA3;a\nA4;a\nA5;a\n" +
+		"### Lesson B\nB1;b\n" +
+		"### Lesson C\nC1;c\nC2;c\nC3;c\n"
+	return ParseTopic(strings.NewReader(deck), getTpp())
+}
+
+// TestPerSection checks that no subsection gives more questions than the
+// maximum in a loop, in both modes, and that the loop is shortened.
+func TestPerSection(t *testing.T) {
+	topic := getUnevenTopic()
+	for _, mode := range []interrogationMode{linear, random, shuffle} {
+		qa := topic.BuildInterleavedSet()
+		ip := getGenericUnattendedInterrogationParameters()
+		ip.mode = mode
+		ip.limit = 3
+		ip.noColor = true
+		ip.perSection = 2
+		if length := ip.loopLength(qa); length != 5 {
+			t.Errorf("A loop should have 5 questions but has %d\n", length)
+		}
+
+		var loops []map[string]int
+		for _, line := range getSessionOutput(qa, ip) {
+			if strings.HasPrefix(line, "Loop (") {
+				loops = append(loops, make(map[string]int))
+			} else if strings.Contains(line, answerArrow) {
+				loops[len(loops)-1][line[:1]]++
+			}
+		}
+		if len(loops) != 3 {
+			t.Fatalf("There should be 3 loops but we got %d\n", len(loops))
+		}
+		for n, loop := range loops {
+			if loop["A"] != 2 || loop["B"] != 1 || loop["C"] != 2 {
+				t.Errorf("In mode %d, the loop %d should ask 2 questions of A, 1 of B and 2 of C but we got %v\n", mode, n+1, loop)
+			}
+		}
+	}
+}
+
+// TestPerSectionPins checks that the pinned questions of a subsection are
+// all asked in each loop, even when they are more than the maximum.
+func TestPerSectionPins(t *testing.T) {
+	topic := getUnevenTopic()
+	for _, mode := range []interrogationMode{linear, random, shuffle} {
+		qa := topic.BuildInterleavedSet()
+		ip := getGenericUnattendedInterrogationParameters()
+		ip.mode = mode
+		ip.limit = 2
+		ip.noColor = true
+		ip.perSection = 2
+		ip.pin = "a"
+		if length := ip.loopLength(qa); length != 8 {
+			t.Errorf("A loop should have the 5 pinned questions and 3 others but has %d\n", length)
+		}
+
+		var loops []map[string]int
+		for _, line := range getSessionOutput(qa, ip) {
+			if strings.HasPrefix(line, "Loop (") {
+				loops = append(loops, make(map[string]int))
+			} else if strings.Contains(line, answerArrow) {
+				loops[len(loops)-1][line[:2]]++
+			}
+		}
+		if len(loops) != 2 {
+			t.Fatalf("There should be 2 loops but we got %d\n", len(loops))
+		}
+		for n, loop := range loops {
+			for _, question := range []string{"A1", "A2", "A3", "A4", "A5", "B1"} {
+				if loop[question] != 1 {
+					t.Errorf("In mode %d, the loop %d should ask %s once but we got %v\n", mode, n+1, question, loop)
+				}
+			}
+		}
+	}
+}
+
+// TestPerSectionLinear checks that in linear mode the next loop goes on
+// with the questions not asked yet.
+func TestPerSectionLinear(t *testing.T) {
+	qa := getUnevenTopic().BuildQuestionsSet()
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.limit = 2
+	ip.perSection = 2
+
+	var asked []string
+	for _, line := range getSessionOutput(qa, ip) {
+		if strings.Contains(line, answerArrow) {
+			asked = append(asked, line[:2])
+		}
+	}
+	expected := "A1 A2 B1 C1 C2 C3 A3 A4 B1 C1"
+	if strings.Join(asked, " ") != expected {
+		t.Errorf("The questions asked should be %s but we got %v\n", expected, asked)
+	}
+}
+
+// TestShuffle checks that in shuffle mode each loop asks every question
+// once.
+func TestShuffle(t *testing.T) {
+	qa := getUnevenTopic().BuildQuestionsSet()
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.mode = shuffle
+	ip.limit = 4
+	ip.noColor = true
+
+	var loops []map[string]int
+	for _, line := range getSessionOutput(qa, ip) {
+		if strings.HasPrefix(line, "Loop (") {
+			loops = append(loops, make(map[string]int))
+		} else if strings.Contains(line, answerArrow) {
+			loops[len(loops)-1][line[:2]]++
+		}
+	}
+	if len(loops) != 4 {
+		t.Fatalf("There should be 4 loops but we got %d\n", len(loops))
+	}
+	for n, loop := range loops {
+		if len(loop) != qa.GetCount() {
+			t.Errorf("The loop %d should ask each of the %d questions once but we got %v\n", n+1, qa.GetCount(), loop)
+		}
+	}
+
+	p, err := parse(noEnvironment, "-m", "shuffle")
+	if err != nil || p.mode != shuffle {
+		t.Errorf("The shuffle mode should be set but we got %v\n", err)
+	}
+}
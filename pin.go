@@ -0,0 +1,15 @@
+package main
+
+import "strings"
+
+// GetPin returns the text of the questions asked first in each loop. Empty
+// means that no question is pinned.
+func (p InterrogationParameters) GetPin() string {
+	return p.pin
+}
+
+// isPinned tells if the question of index i is asked first in each loop:
+// it contains the text given with -pin, whatever the case.
+func (p InterrogationParameters) isPinned(qa QuestionsAnswers, i int) bool {
+	return len(p.pin) != 0 && strings.Contains(strings.ToLower(qa.questions[i]), strings.ToLower(p.pin))
+}
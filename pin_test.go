@@ -0,0 +1,45 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestPin checks that the pinned question is the first one of every loop,
+// in both modes, and that it is not asked again in the loop.
+func TestPin(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("q1", "a1")
+	qa.AddEntry("q2", "a2")
+	qa.AddEntry("Q3", "a3")
+	qa.AddEntry("q4", "a4")
+
+	for _, mode := range []interrogationMode{linear, random} {
+		ip := getGenericUnattendedInterrogationParameters()
+		ip.mode = mode
+		ip.limit = 3
+		ip.noColor = true
+		ip.pin = "q3"
+
+		var loops [][]string
+		for _, line := range getSessionOutput(qa, ip) {
+			if strings.HasPrefix(line, "Loop (") {
+				loops = append(loops, nil)
+			} else if strings.Contains(line, answerArrow) {
+				loops[len(loops)-1] = append(loops[len(loops)-1], line[:2])
+			}
+		}
+		if len(loops) != 3 {
+			t.Fatalf("There should be 3 loops but we got %d\n", len(loops))
+		}
+		for n, loop := range loops {
+			if len(loop) != 4 || loop[0] != "Q3" || strings.Count(strings.Join(loop, " "), "Q3") != 1 {
+				t.Errorf("In mode %d, the loop %d should start with Q3 and ask it once but we got %v\n", mode, n+1, loop)
+			}
+			if mode == linear && !reflect.DeepEqual(loop, []string{"Q3", "q1", "q2", "q4"}) {
+				t.Errorf("The other questions should keep the linear order but we got %v\n", loop)
+			}
+		}
+	}
+}
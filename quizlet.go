@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// defaultQuizletSep is the separator between the term and the definition
+// of the sets exported by Quizlet, unless another one is chosen in the
+// export.
+const defaultQuizletSep = "\t"
+
+// ParseTopicQuizlet reads a set exported by Quizlet: one card per line, the
+// term and the definition being separated by sep, a tab if empty. The cards
+// are in the subsection named after the set. A line that is not empty and
+// has no separator is an error.
+func ParseTopicQuizlet(r io.Reader, set string, sep string) (Topic, error) {
+	if len(sep) == 0 {
+		sep = defaultQuizletSep
+	}
+	topic := NewTopic()
+	qa := topic.GetSubsection(set)
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		input := strings.TrimSuffix(s.Text(), "\r")
+		if len(strings.TrimSpace(input)) == 0 {
+			continue
+		}
+		term, definition, found := strings.Cut(input, sep)
+		if !found || len(strings.TrimSpace(term)) == 0 {
+			return topic, &ParseError{Line: line, msg: fmt.Sprintf("The line %d of the Quizlet set is not a term and its definition.", line)}
+		}
+		qa.AddEntry(strings.TrimSpace(term), strings.TrimSpace(definition))
+	}
+	if qa.GetCount() != 0 {
+		topic.SetSubsection(set, qa)
+	}
+	return topic, s.Err()
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseTopicQuizlet checks that the cards of a Quizlet set are read in
+// the subsection of the set.
+func TestParseTopicQuizlet(t *testing.T) {
+	topic, err := ParseTopicQuizlet(strings.NewReader("manger\tto eat\r\n\nboire\tto drink, to sip\n"), "Verbs", "")
+	if err != nil {
+		t.Fatalf("A valid Quizlet set should not trigger an error: %v", err)
+	}
+	verbs := topic.GetSubsection("Verbs")
+	if !reflect.DeepEqual(verbs.questions, []string{"manger", "boire"}) || !reflect.DeepEqual(verbs.answers, []string{"to eat", "to drink, to sip"}) {
+		t.Errorf("The cards of the set are wrong: %v %v\n", verbs.questions, verbs.answers)
+	}
+
+	_, err = ParseTopicQuizlet(strings.NewReader("manger - to eat\nboire\tto drink\n"), "Verbs", "\t")
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) || parseErr.Line != 1 {
+		t.Errorf("The line without separator should be reported but we got %v\n", err)
+	}
+}
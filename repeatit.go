@@ -1,73 +1,456 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"os"
 	"github.com/fatih/color"
+	"io"
+	"math"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
 )
 
 func main() {
 	// Recuperation du parametre vers le fichier
 	if len(os.Args) < 2 {
 		c := color.New(color.FgRed).Add(color.Underline)
-		c.Printf("Please supply a path to a CSV file that contains the topics.\n")
+		c.Printf("Please supply a path to a CSV, YAML or Markdown file that contains the topics.\n")
 
+		var usage strings.Builder
+		writeUsage(&usage, os.Args[0])
 		c = color.New(color.FgWhite).Add(color.Bold)
-		c.Printf(`Syntax:
-	%s <csvFile> [-i]
-where:
-	* -i : stands for interactive. If set, you will have to press Return to get the
-          answer. This allows you to be in a learning way or enforcing your knowledge.
-			 If this flag is not set, you will not have to press the Return key and you
-			 simply have to wait for a given time. See -t for details about time.
-	* -t : the time to wait between 2 questions. Default is 2 seconds. The time you set is
-	       in milliseconds.
-	* -s : ask to show the different topics of  the file, no more. Execution stops after this.
-	       Sections are supposed to start with ###.
-	* -l : ask to be questionned only on the topics that are listed here. The topics must be separated with a comma.
-	* -r : reverts the questioning. This is like a Jeopardy in fact. The right column becomes the questions while the right column becomes the answer.
-`, os.Args[0])
+		c.Print(usage.String())
 		os.Exit(1)
 	}
 
-	// Creer un objet fichier et tester si on peut le lire
-	filename := os.Args[1]
-	file, err := os.Open(filename)
+	args, review := os.Args[1:], false
+	switch name := os.Args[1]; {
+	case name == learnCommand:
+		args = os.Args[2:]
+	case name == reviewCommand && len(os.Args) > 2:
+		args, review = os.Args[2:], true
+	case commands[name] != nil:
+		err := commands[name](processEnvironment, os.Args[2:], os.Stdout)
+		if errors.Is(err, flag.ErrHelp) {
+			writeUsage(os.Stdout, os.Args[0])
+			return
+		}
+		if err != nil {
+			fmt.Printf("The %s subcommand failed: %v\n", name, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Without a csv file, the first argument is already an option. This is
+	// the case of the batch mode where the decks are listed in a manifest.
+	var decks []string
+	for len(args) != 0 && !strings.HasPrefix(args[0], "-") {
+		decks, args = append(decks, args[0]), args[1:]
+	}
+	learn(decks, args, review)
+}
+
+// learn asks the questions of the decks with the options of the command
+// line. The decks are paths, directories or glob patterns, merged in one
+// topic. review tells if only the questions due in the Leitner boxes are
+// asked.
+func learn(decks []string, args []string, review bool) {
+	p, err := Parse(args...)
+	if errors.Is(err, flag.ErrHelp) {
+		writeUsage(os.Stdout, os.Args[0])
+		return
+	}
 	if err != nil {
-		fmt.Printf("Open of the source file failed: %v\n", err)
+		fmt.Printf("Parse of the command line failed: %v\n", err)
 		os.Exit(1)
 	}
-
-	p, err := Parse(os.Args[2:]...)
+	paths, err := expandDeckPaths(decks)
 	if err != nil {
-		fmt.Errorf("Parse of the command line failed: %v\n", err)
+		fmt.Printf("Load of the source file failed: %v\n", err)
 		os.Exit(1)
 	}
+	filename := strings.Join(paths, ", ")
+	if review {
+		if len(paths) > 1 && p.GetLeitnerPath() == "" {
+			fmt.Println("The review of several decks needs the file of their boxes, set with -leitner.")
+			os.Exit(1)
+		}
+		p = p.reviewDeck(filename)
+	}
+
+	tpp := p.GetTopicParsingParameters()
 
-	tpp := TopicParsingParameters{
-		TopicAnnounce: "### ",
-		QaSep:         ";",
+	var leeches Leeches
+	if p.GetLeechesPath() != "" {
+		leeches = make(Leeches)
+		if err := loadJSON(p.GetLeechesPath(), &leeches); err != nil {
+			fmt.Printf("Load of the missed questions failed: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if p.GetBatchManifest() != "" {
+		runBatch(p, tpp)
+		return
+	}
+
+	var topic Topic
+	if len(paths) > 1 {
+		topic, err = ParseTopicFromFiles(paths, tpp, p.GetMergePolicy())
+	} else {
+		topic, err = ParseTopicFromFile(filename, tpp)
+	}
+	if err != nil {
+		fmt.Printf("Load of the source file failed: %v\n", err)
+		os.Exit(1)
+	}
+	p = p.ApplyMetadata(topic.Metadata())
+	if p.IsVerbose() {
+		for n, path := range paths {
+			if abs, err := filepath.Abs(path); err == nil {
+				paths[n] = abs
+			}
+		}
+		WriteParseSummary(p.GetVerboseStream(), strings.Join(paths, ", "), tpp, topic)
 	}
-	topic := ParseTopic(file, tpp)
-	file.Close()
 
 	out := p.GetOutputStream()
+	if p.GetExportFormat() != "" {
+		if err := ConvertTopic(out, topic, p.GetExportFormat(), tpp); err != nil {
+			fmt.Printf("Export of the deck failed: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 	if p.IsSummaryMode() {
-		list := topic.GetSubsectionsName()
-		if len(list) == 0 {
-			fmt.Fprintf(out, "No topic found in this file")
+		topic.WriteTopicsList(out)
+		return
+	}
+
+	var state SessionState
+	var saved bool
+	if p.GetResumePath() != "" {
+		if state, saved = loadSessionState(p.GetResumePath()); saved {
+			p = p.ResumeSeed(state)
+		}
+	}
+
+	var qa QuestionsAnswers
+	if p.IsCramMode() {
+		qa = p.BuildCramSet(topic)
+	} else if p.IsInterleaved() {
+		qa = topic.BuildInterleavedSet(p.GetListOfSubsections()...)
+	} else {
+		qa = p.BuildQuestionsSet(topic, p.GetListOfSubsections()...)
+	}
+	if reversed := p.GetReversedSubsections(); len(reversed) != 0 {
+		qa = qa.ReverseSubsections(reversed...)
+	}
+	if p.GetDedupStrategy() != "" {
+		qa = qa.Dedup(p.GetDedupStrategy())
+	}
+	if tags := p.GetTags(); len(tags) != 0 {
+		qa = qa.FilterByTag(tags...)
+		if qa.GetCount() == 0 {
+			fmt.Fprintf(out, "No question carries the tags %s\n", strings.Join(tags, ", "))
+			return
+		}
+	}
+
+	if p.UsesDifficulty() {
+		qa = p.ApplyDifficulty(qa)
+		if qa.GetCount() == 0 {
+			fmt.Fprintln(out, "No question has the difficulty you asked for")
+			return
+		}
+	}
+	if p.GetStatsPath() != "" {
+		p.Stats, err = LoadStats(p.GetStatsPath())
+		if err != nil {
+			fmt.Printf("Load of the statistics failed: %v\n", err)
+			os.Exit(1)
+		}
+		if p.IsWeakestFirst() {
+			qa = p.Stats.SortByWeakness(qa)
+		}
+	}
+	var mastery Mastery
+	if p.GetMasteryPath() != "" {
+		mastery = make(Mastery)
+		if err := loadJSON(p.GetMasteryPath(), &mastery); err != nil {
+			fmt.Printf("Load of the mastered questions failed: %v\n", err)
+			os.Exit(1)
+		}
+		var gated int
+		qa, gated = mastery.FilterPrerequisites(qa, topic.BuildQuestionsSet())
+		if gated != 0 {
+			fmt.Fprintf(out, "%d questions wait for their prerequisites to be mastered.\n", gated)
+		}
+		if qa.GetCount() == 0 {
+			return
+		}
+	}
+	var schedule Schedule
+	if p.GetSchedulePath() != "" {
+		schedule = make(Schedule)
+		if err := loadJSON(p.GetSchedulePath(), &schedule); err != nil {
+			fmt.Printf("Load of the schedule failed: %v\n", err)
+			os.Exit(1)
+		}
+		due := schedule.FilterDue(qa, time.Now())
+		if due.GetCount() == 0 {
+			fmt.Fprintf(out, "No question is due. The next one is due on %s.\n", schedule.NextDue(qa).Format("2006-01-02 15:04"))
 			return
 		}
-		fmt.Fprintln(out, "List of topics:")
-		fmt.Fprintln(out, "===============")
-		for i := 0; i < len(list); i++ {
-			fmt.Fprintf(out, "  * %s\n", list[i])
+		qa = due
+	}
+	var boxes LeitnerBoxes
+	if p.GetLeitnerPath() != "" {
+		boxes = make(LeitnerBoxes)
+		if err := loadJSON(p.GetLeitnerPath(), &boxes); err != nil {
+			fmt.Printf("Load of the Leitner boxes failed: %v\n", err)
+			os.Exit(1)
+		}
+		if p.IsDueOnly() {
+			qa = boxes.FilterDue(qa, time.Now())
+			if qa.GetCount() == 0 {
+				fmt.Fprintln(out, "No question is due today.")
+				return
+			}
+		}
+	}
+	var seen SeenCards
+	if p.GetFreshPath() != "" {
+		seen = SeenCards{Cards: make(map[string]int)}
+		if err := loadJSON(p.GetFreshPath(), &seen); err != nil {
+			fmt.Printf("Load of the questions seen failed: %v\n", err)
+			os.Exit(1)
+		}
+		var fresh bool
+		if qa, fresh = seen.FilterFresh(qa, p.GetFreshSessions()); !fresh {
+			fmt.Fprintln(os.Stderr, "All the questions were seen recently, they are all asked.")
+		}
+	}
+	if p.GetReview() > 0 {
+		qa = p.AddReviews(qa)
+	}
+	if p.IsExamMode() {
+		qa = p.DrawExam(qa)
+	}
+
+	if p.IsCoverageAsked() {
+		fmt.Fprintf(out, "About %.0f questions are needed to see each of the %d questions at least once in random mode, that is %d loops.\n",
+			math.Ceil(EstimateCoverageDraws(qa.GetCount())), qa.GetCount(), EstimateCoverageLoops(qa.GetCount()))
+		return
+	}
+	if p.IsCompactMode() {
+		if err := p.WriteCompact(out, qa); err != nil {
+			fmt.Fprintf(os.Stderr, "The transcript stopped early: %v\n", err)
 		}
 		return
 	}
 
-	qa := topic.BuildQuestionsSet(p.GetListOfSubsections()[:]...)
+	if saved {
+		var resumed bool
+		if p, resumed = p.Resume(qa, state); !resumed {
+			fmt.Fprintln(os.Stderr, "The deck changed since the session was saved, starting from the beginning.")
+		}
+	}
 
-	AskQuestions(qa, p)
+	// Ctrl-C stops the session like the quit command, so that it is saved
+	// and summed up. Once the session is over, it stops the program again.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	result := AskQuestionsContext(ctx, qa, p)
+	stop()
+	if result.Interrupted {
+		fmt.Fprintln(out, "\nSession interrupted.")
+	}
+	if result.Err != nil {
+		fmt.Fprintf(os.Stderr, "The session stopped early: %v\n", result.Err)
+	} else if err := result.Report(qa).Write(out); err != nil {
+		fmt.Fprintf(os.Stderr, "The summary of the session could not be written: %v\n", err)
+	}
 
+	if p.GetSaveWrongPath() != "" && len(result.Wrong) != 0 {
+		if err := saveTopic(p.GetSaveWrongPath(), WrongAnswersTopic(qa, result), tpp); err != nil {
+			fmt.Printf("Save of the wrong answers failed: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if p.GetSaveFlaggedPath() != "" && len(result.Flagged) != 0 {
+		if err := saveTopic(p.GetSaveFlaggedPath(), FlaggedTopic(qa, result), tpp); err != nil {
+			fmt.Printf("Save of the flagged questions failed: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if p.GetResumePath() != "" {
+		if err := saveSession(p, qa, result); err != nil {
+			fmt.Printf("Save of the session failed: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if p.GetFreshPath() != "" {
+		seen.Update(qa, result)
+		if err := writeFile(p.GetFreshPath(), seen.Write); err != nil {
+			fmt.Printf("Save of the questions seen failed: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if p.GetLeechesPath() != "" {
+		leeches.Update(qa, result)
+		if err := saveLeeches(p.GetLeechesPath(), leeches); err != nil {
+			fmt.Printf("Save of the missed questions failed: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if p.GetSchedulePath() != "" {
+		schedule.Update(qa, result, time.Now())
+		if err := writeFile(p.GetSchedulePath(), schedule.Write); err != nil {
+			fmt.Printf("Save of the schedule failed: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if p.GetLeitnerPath() != "" {
+		boxes.Update(qa, result, time.Now())
+		if err := writeFile(p.GetLeitnerPath(), boxes.Write); err != nil {
+			fmt.Printf("Save of the Leitner boxes failed: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if p.GetMasteryPath() != "" {
+		mastery.Update(qa, result)
+		if err := writeFile(p.GetMasteryPath(), mastery.Write); err != nil {
+			fmt.Printf("Save of the mastered questions failed: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if p.GetStatsPath() != "" {
+		if err := p.Stats.Save(p.GetStatsPath()); err != nil {
+			fmt.Printf("Save of the statistics failed: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if p.GetComparePath() != "" {
+		stats := make(LastSessions)
+		if err := loadJSON(p.GetComparePath(), &stats); err != nil {
+			fmt.Printf("Load of the last sessions failed: %v\n", err)
+			os.Exit(1)
+		}
+		deck := topic.Fingerprint()
+		fmt.Fprintln(out, result.CompareTo(stats.Last(deck)))
+		stats.Update(deck, result)
+		if err := writeFile(p.GetComparePath(), stats.Write); err != nil {
+			fmt.Printf("Save of the session failed: %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+
+// runBatch runs the decks listed in the manifest of the batch mode and
+// prints the report.
+func runBatch(p InterrogationParameters, tpp TopicParsingParameters) {
+	manifestPath := p.GetBatchManifest()
+	manifest, err := os.Open(manifestPath)
+	if err != nil {
+		fmt.Printf("Open of the batch manifest failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer manifest.Close()
+
+	// Decks are relative to the manifest.
+	open := func(path string) (io.ReadCloser, error) {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(manifestPath), path)
+		}
+		return openWithRetry(openFile, path, systemClock{})
+	}
+	result, err := RunBatch(manifest, open, tpp, p)
+	if err != nil {
+		fmt.Printf("Batch failed: %v\n", err)
+		os.Exit(1)
+	}
+	result.WriteReport(p.GetOutputStream())
+}
+
+// loadSessionState reads the state of the session saved at path by the
+// -resume option. It returns false if there is none, or if it is malformed:
+// the session then starts from the beginning.
+func loadSessionState(path string) (SessionState, bool) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return SessionState{}, false
+	}
+	if err != nil {
+		fmt.Printf("Load of the session failed: %v\n", err)
+		os.Exit(1)
+	}
+	defer file.Close()
+	state, err := readState(file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "The saved session is malformed, starting from the beginning: %v\n", err)
+		return SessionState{}, false
+	}
+	return state, true
+}
+
+// saveSession saves the state of the session if the user quit or
+// interrupted it, so that it can be resumed. A session that went to its end
+// has nothing to resume.
+func saveSession(p InterrogationParameters, qa QuestionsAnswers, result SessionResult) error {
+	if !result.Quit && !result.Interrupted {
+		err := os.Remove(p.GetResumePath())
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return writeFile(p.GetResumePath(), func(w io.Writer) error {
+		return writeState(w, p.SaveState(qa, result))
+	})
+}
+
+// saveLeeches writes the leeches to the file at path.
+func saveLeeches(path string, leeches Leeches) error {
+	return writeFile(path, leeches.Write)
+}
+
+// loadJSON reads the JSON of the file at path in v. The files kept across
+// the sessions do not exist before the first one: v is then left as is.
+func loadJSON(path string, v interface{}) error {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return json.NewDecoder(file).Decode(v)
+}
+
+// saveTopic writes the topic to the file at path.
+func saveTopic(path string, topic Topic, tpp TopicParsingParameters) error {
+	return writeFile(path, func(w io.Writer) error {
+		return WriteTopic(w, topic, tpp)
+	})
+}
+
+// writeFile creates the file at path and fills it with write.
+func writeFile(path string, write func(w io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
 }
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// slowestCount is the number of questions listed as the slowest ones in a
+// session report.
+const slowestCount = 3
+
+// SessionReport sums up a session for the user once it is over. It is
+// built from the result so that other frontends can render it their way.
+type SessionReport struct {
+	Asked       int                // number of questions asked
+	Duration    time.Duration      // time the session lasted
+	Subsections []SubsectionResult // questions asked and graded per subsection
+	Graded      int                // number of answers graded. 0 means that the accuracy is meaningless
+	Accuracy    float64            // percentage of the graded answers found, with the partial credit
+	Slowest     []SlowQuestion     // questions that took the longest to answer, the slowest first
+}
+
+// SlowQuestion is a question and the longest time taken to answer it.
+type SlowQuestion struct {
+	Question string
+	Time     time.Duration
+}
+
+// Report returns the report of the session on the questions set.
+func (r SessionResult) Report(qa QuestionsAnswers) SessionReport {
+	return SessionReport{
+		Asked:       r.Asked,
+		Duration:    r.Duration,
+		Subsections: r.Subsections,
+		Graded:      r.Graded,
+		Accuracy:    r.percentage(),
+		Slowest:     slowestQuestions(qa, r.Answers, slowestCount),
+	}
+}
+
+// slowestQuestions returns at most n of the questions that took the
+// longest to answer. A question answered several times counts with its
+// longest time.
+func slowestQuestions(qa QuestionsAnswers, answers []TimedAnswer, n int) []SlowQuestion {
+	var longest []TimedAnswer
+	for _, answer := range answers {
+		found := false
+		for k := range longest {
+			if longest[k].Index == answer.Index {
+				found = true
+				if answer.Time > longest[k].Time {
+					longest[k].Time = answer.Time
+				}
+			}
+		}
+		if !found {
+			longest = append(longest, answer)
+		}
+	}
+	sort.SliceStable(longest, func(a, b int) bool {
+		return longest[a].Time > longest[b].Time
+	})
+	if len(longest) > n {
+		longest = longest[:n]
+	}
+	slowest := make([]SlowQuestion, 0, len(longest))
+	for _, answer := range longest {
+		slowest = append(slowest, SlowQuestion{Question: qa.questions[answer.Index], Time: answer.Time})
+	}
+	return slowest
+}
+
+// Write writes the report as text, one subsection per line.
+func (s SessionReport) Write(w io.Writer) error {
+	out := &stickyWriter{w: w}
+	fmt.Fprintln(out, "Session summary")
+	fmt.Fprintf(out, "Questions asked: %d in %v\n", s.Asked, s.Duration.Round(time.Second))
+	for _, sub := range s.Subsections {
+		if sub.Graded == 0 {
+			fmt.Fprintf(out, "  %s: %d asked\n", sub.Name, sub.Asked)
+		} else {
+			fmt.Fprintf(out, "  %s: %d asked, %d/%d correct\n", sub.Name, sub.Asked, sub.Correct, sub.Graded)
+		}
+	}
+	if s.Graded != 0 {
+		fmt.Fprintf(out, "Accuracy: %.0f%%\n", s.Accuracy)
+	}
+	if len(s.Slowest) != 0 {
+		fmt.Fprintln(out, "Slowest questions:")
+		for _, slow := range s.Slowest {
+			fmt.Fprintf(out, "  %.1fs %s\n", slow.Time.Seconds(), slow.Question)
+		}
+	}
+	return out.err
+}
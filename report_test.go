@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSessionReport checks the summary of a graded session with the
+// slowest questions.
+func TestSessionReport(t *testing.T) {
+	qa := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp()).BuildQuestionsSet()
+	result := SessionResult{
+		Asked:    5,
+		Graded:   4,
+		Correct:  3,
+		Credit:   3,
+		Duration: 95 * time.Second,
+		Subsections: []SubsectionResult{
+			{Name: "1", Asked: 1},
+			{Name: "2", Asked: 4, Graded: 4, Correct: 3},
+		},
+		Answers: []TimedAnswer{
+			{Index: 1, Time: 2 * time.Second},
+			{Index: 2, Time: 7 * time.Second},
+			{Index: 1, Time: 9 * time.Second},
+			{Index: 0, Time: time.Second},
+			{Index: 3, Time: 4 * time.Second},
+		},
+	}
+	report := result.Report(qa)
+
+	expected := []SlowQuestion{
+		{Question: qa.questions[1], Time: 9 * time.Second},
+		{Question: qa.questions[2], Time: 7 * time.Second},
+		{Question: qa.questions[3], Time: 4 * time.Second},
+	}
+	if !reflect.DeepEqual(report.Slowest, expected) {
+		t.Errorf("The slowest questions should be %v but we got %v\n", expected, report.Slowest)
+	}
+
+	var out bytes.Buffer
+	if err := report.Write(&out); err != nil {
+		t.Fatalf("Writing the report failed: %v", err)
+	}
+	summary := "Session summary\n" +
+		"Questions asked: 5 in 1m35s\n" +
+		"  1: 1 asked\n" +
+		"  2: 4 asked, 3/4 correct\n" +
+		"Accuracy: 75%\n" +
+		"Slowest questions:\n" +
+		"  9.0s 2_Question 1\n" +
+		"  7.0s 2_Question 2\n" +
+		"  4.0s 3_Question 1\n"
+	if out.String() != summary {
+		t.Errorf("The report should be:\n%s\nbut we got:\n%s\n", summary, out.String())
+	}
+}
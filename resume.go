@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// SessionState is what is saved when the user quits a session so that it
+// can be resumed later at the same position.
+type SessionState struct {
+	Deck     string `json:"deck"`     // fingerprint of the questions set, to detect that the deck changed
+	Seed     int64  `json:"seed"`     // seed of the random sequence of the session
+	Loop     int    `json:"loop"`     // the loop in progress, starting from 1
+	Question int    `json:"question"` // the number of questions already asked in this loop
+}
+
+// GetResumePath returns the path of the file where the state of the
+// session is saved when the user quits. It is empty if not requested.
+func (p InterrogationParameters) GetResumePath() string {
+	return p.resume
+}
+
+// SaveState returns the state of the session that the user quit.
+func (p InterrogationParameters) SaveState(qa QuestionsAnswers, r SessionResult) SessionState {
+	n := qa.GetCount()
+	return SessionState{
+		Deck:     qa.fingerprint(),
+		Seed:     p.seed,
+		Loop:     r.Position/n + 1,
+		Question: r.Position % n,
+	}
+}
+
+// ResumeSeed returns the parameters with the seed of the state. It is set
+// before the questions set is built, so that a set drawn randomly, like an
+// exam or the reviews, is the one of the session saved.
+func (p InterrogationParameters) ResumeSeed(state SessionState) InterrogationParameters {
+	p.setSeed(state.Seed)
+	return p
+}
+
+// Resume returns the parameters of a session starting where the state was
+// saved. It returns false, and the parameters untouched, if the questions
+// set is not the one of the state. The random sequence already set by
+// ResumeSeed goes on: it drew the set before the session.
+func (p InterrogationParameters) Resume(qa QuestionsAnswers, state SessionState) (InterrogationParameters, bool) {
+	if state.Deck != qa.fingerprint() {
+		return p, false
+	}
+	if p.seed != state.Seed {
+		p.setSeed(state.Seed)
+	}
+	p.start = (state.Loop-1)*qa.GetCount() + state.Question
+	return p, true
+}
+
+// readState reads the state of a session saved by writeState.
+func readState(r io.Reader) (SessionState, error) {
+	var state SessionState
+	err := json.NewDecoder(r).Decode(&state)
+	return state, err
+}
+
+// writeState saves the state of a session in JSON.
+func writeState(w io.Writer, state SessionState) error {
+	return json.NewEncoder(w).Encode(state)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// getCardsAsked runs an interactive session where the user types the input
+// and returns the cards shown with their answer.
+func getCardsAsked(qa QuestionsAnswers, ip InterrogationParameters, input string) ([]string, SessionResult) {
+	var out bytes.Buffer
+	ip.in = strings.NewReader(input)
+	ip.out = &out
+	result := AskQuestions(qa, ip)
+
+	var cards []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if strings.Contains(line, "     --> ") {
+			cards = append(cards, line)
+		}
+	}
+	return cards, result
+}
+
+// getResumeParameters returns the parameters of a random interactive
+// session of 2 loops with a fixed seed.
+func getResumeParameters(seed int64) InterrogationParameters {
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.mode = random
+	ip.limit = 2
+	ip.seed = seed
+	ip.rng = rand.New(rand.NewSource(seed))
+	return ip
+}
+
+// TestResumeSession checks that a session quit then resumed asks the same
+// cards, in the same order, as a session that was not interrupted.
+func TestResumeSession(t *testing.T) {
+	qa := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp()).BuildQuestionsSet()
+
+	full, _ := getCardsAsked(qa, getResumeParameters(11), strings.Repeat("\n", 12))
+	if len(full) != 12 {
+		t.Fatalf("The session should have asked 12 cards but asked %d\n", len(full))
+	}
+
+	ip := getResumeParameters(11)
+	first, result := getCardsAsked(qa, ip, strings.Repeat("\n", 8)+"q\n")
+	if !result.Quit || result.Position != 8 || result.Asked != 8 {
+		t.Fatalf("The session should have been quit after 8 cards but we got %+v\n", result)
+	}
+
+	var saved bytes.Buffer
+	if err := writeState(&saved, ip.SaveState(qa, result)); err != nil {
+		t.Fatalf("Saving the state failed: %v", err)
+	}
+	state, err := readState(&saved)
+	if err != nil {
+		t.Fatalf("Reading the state back failed: %v", err)
+	}
+	if state.Seed != 11 || state.Loop != 2 || state.Question != 2 {
+		t.Errorf("The state should be at the question 2 of the loop 2 with the seed 11 but we got %+v\n", state)
+	}
+
+	resumed, ok := getResumeParameters(99).Resume(qa, state)
+	if !ok {
+		t.Fatalf("The session of the same deck could not be resumed.")
+	}
+	second, result := getCardsAsked(qa, resumed, strings.Repeat("\n", 4))
+	if result.Asked != 4 {
+		t.Errorf("The resumed session should have asked the 4 remaining cards but asked %d\n", result.Asked)
+	}
+	if joined := strings.Join(append(first, second...), "\n"); joined != strings.Join(full, "\n") {
+		t.Errorf("The resumed session does not continue the sequence.\nExpected:\n%s\nGot:\n%s\n", strings.Join(full, "\n"), joined)
+	}
+}
+
+// TestResumeExam checks that an exam quit then resumed without -seed
+// draws the same questions, so that it goes on where it was quit.
+func TestResumeExam(t *testing.T) {
+	deck := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp()).BuildQuestionsSet()
+	ip := getResumeParameters(11)
+	ip.exam = 4
+	ip.mode = linear
+	ip.limit = 1
+	qa := ip.DrawExam(deck)
+	full, _ := getCardsAsked(qa, ip, strings.Repeat("\n", 4))
+
+	ip = getResumeParameters(11)
+	ip.exam, ip.mode, ip.limit = 4, linear, 1
+	qa = ip.DrawExam(deck)
+	first, result := getCardsAsked(qa, ip, "\n\nq\n")
+	state := ip.SaveState(qa, result)
+
+	resumed := getResumeParameters(99).ResumeSeed(state)
+	resumed.exam, resumed.mode, resumed.limit = 4, linear, 1
+	qa = resumed.DrawExam(deck)
+	resumed, ok := resumed.Resume(qa, state)
+	if !ok {
+		t.Fatalf("The exam drawn again should be the one saved.")
+	}
+	second, _ := getCardsAsked(qa, resumed, strings.Repeat("\n", 2))
+	if joined := strings.Join(append(first, second...), "\n"); joined != strings.Join(full, "\n") {
+		t.Errorf("The resumed exam does not go on where it was quit.\nExpected:\n%s\nGot:\n%s\n", strings.Join(full, "\n"), joined)
+	}
+}
+
+// TestResumeChangedDeck checks that the state of another deck is not used.
+func TestResumeChangedDeck(t *testing.T) {
+	qa := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp()).BuildQuestionsSet()
+	ip := getResumeParameters(11)
+	state := ip.SaveState(qa, SessionResult{Quit: true, Position: 3})
+
+	changed := ParseTopic(strings.NewReader(strings.Replace(getSampleCsvAsStream(), "3_Answer 3", "3_Answer three", 1)), getTpp()).BuildQuestionsSet()
+	p, ok := getResumeParameters(5).Resume(changed, state)
+	if ok {
+		t.Errorf("The state of a deck that changed should not be resumed.")
+	}
+	if p.start != 0 || p.seed != 5 {
+		t.Errorf("A session that is not resumed should start from the beginning with its own seed: %d, %d\n", p.start, p.seed)
+	}
+}
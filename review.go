@@ -0,0 +1,44 @@
+package main
+
+import (
+	"math/rand"
+)
+
+// GetReview returns the number of questions of the previous subsections
+// asked again after each subsection. 0 means no review.
+func (p InterrogationParameters) GetReview() int {
+	return p.review
+}
+
+// AddReviews returns the set with the reviews chosen by the user.
+func (p InterrogationParameters) AddReviews(qa QuestionsAnswers) QuestionsAnswers {
+	return qa.AddReviews(p.review, p.rng)
+}
+
+// AddReviews returns a new set where the questions of each subsection are
+// followed by n questions drawn randomly, without replacement, among the
+// questions of the subsections before it. The first subsection has no
+// review, and a review has fewer questions when there are fewer questions
+// before. The subsections are the runs of questions sharing the same
+// origin, so the set is expected in linear order.
+func (qa QuestionsAnswers) AddReviews(n int, rng *rand.Rand) QuestionsAnswers {
+	reviewed := NewQA()
+	var previous QuestionsAnswers
+	for start := 0; start < qa.GetCount(); {
+		end := start
+		for end < qa.GetCount() && qa.origin[end] == qa.origin[start] {
+			end++
+		}
+		for i := start; i < end; i++ {
+			reviewed.appendEntryFrom(qa, i)
+		}
+		if previous.GetCount() != 0 {
+			reviewed.Concatenate(previous.Sample(n, rng))
+		}
+		for i := start; i < end; i++ {
+			previous.appendEntryFrom(qa, i)
+		}
+		start = end
+	}
+	return reviewed
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestAddReviews checks that each subsection but the first one is followed
+// by the number of questions asked drawn from the subsections before it.
+func TestAddReviews(t *testing.T) {
+	deck := "### Lesson A\nA1;a\nA2;a\nA3;a\n### Lesson B\nB1;b\nB2;b\n### Lesson C\nC1;c\nC2;c\n"
+	qa := ParseTopic(strings.NewReader(deck), getTpp()).BuildQuestionsSet("A", "B", "C")
+
+	reviewed := qa.AddReviews(2, rand.New(rand.NewSource(1)))
+	if reviewed.GetCount() != qa.GetCount()+4 {
+		t.Fatalf("2 reviews of 2 questions should be added but we got %v\n", reviewed.questions)
+	}
+	if !reflect.DeepEqual(reviewed.questions[:5], []string{"A1", "A2", "A3", "B1", "B2"}) {
+		t.Errorf("The first subsection should not be reviewed but we got %v\n", reviewed.questions)
+	}
+	checkReview := func(review []string, allowed string) {
+		if review[0] == review[1] {
+			t.Errorf("The questions of a review should be distinct but we got %v\n", review)
+		}
+		for _, q := range review {
+			if !strings.Contains(allowed, q[:1]) {
+				t.Errorf("The review %v should only have questions of the subsections %s\n", review, allowed)
+			}
+		}
+	}
+	checkReview(reviewed.questions[5:7], "A")
+	if !reflect.DeepEqual(reviewed.questions[7:9], []string{"C1", "C2"}) {
+		t.Errorf("The third subsection should follow the first review but we got %v\n", reviewed.questions)
+	}
+	checkReview(reviewed.questions[9:11], "AB")
+	if err := reviewed.Validate(); err != nil {
+		t.Errorf("The set with the reviews is not consistent: %v\n", err)
+	}
+
+	same := qa.AddReviews(2, rand.New(rand.NewSource(1)))
+	if !reflect.DeepEqual(same.questions, reviewed.questions) {
+		t.Errorf("The same seed should give the same reviews but we got %v and %v\n", reviewed.questions, same.questions)
+	}
+}
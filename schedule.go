@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"time"
+)
+
+// The SM-2 algorithm grades each answer with a quality from 0 to 5. The
+// answers are only right or wrong here.
+const (
+	rightQuality = 4 // a correct answer, found with some hesitation
+	wrongQuality = 1 // a wrong answer, the correct one being remembered once seen
+)
+
+// The bounds of the easiness factor of SM-2.
+const (
+	initialEasiness = 2.5
+	minEasiness     = 1.3
+)
+
+// day is the unit of the intervals of SM-2.
+const day = 24 * time.Hour
+
+// GetSchedulePath returns the path of the file where the schedule of the
+// questions is kept. Empty means that all the questions are asked.
+func (p InterrogationParameters) GetSchedulePath() string {
+	return p.schedule
+}
+
+// Scheduled is the state of a question in the SM-2 algorithm.
+type Scheduled struct {
+	Easiness    float64   `json:"easiness"`    // how easy the question is, at least minEasiness
+	Interval    int       `json:"interval"`    // days between the last answer and the next one
+	Repetitions int       `json:"repetitions"` // correct answers in a row
+	Due         time.Time `json:"due"`         // when the question has to be asked again
+}
+
+// Schedule holds the state in SM-2 of the questions, keyed by their text.
+// The questions that are not in the schedule are new ones, due at once.
+type Schedule map[string]Scheduled
+
+// ReadSchedule reads the schedule saved in JSON by Write.
+func ReadSchedule(r io.Reader) (Schedule, error) {
+	schedule := make(Schedule)
+	if err := json.NewDecoder(r).Decode(&schedule); err != nil {
+		return nil, err
+	}
+	return schedule, nil
+}
+
+// Write saves the schedule in JSON.
+func (s Schedule) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// FilterDue returns the entries of the set that are due at now, the new
+// ones included.
+func (s Schedule) FilterDue(qa QuestionsAnswers, now time.Time) QuestionsAnswers {
+	return qa.filterDue(now, func(question string) (time.Time, bool) {
+		scheduled, found := s[question]
+		return scheduled.Due, found
+	})
+}
+
+// filterDue returns the entries of the set that are due at now. due tells
+// when a question is due, and false for a new question, which is due at
+// once.
+func (qa QuestionsAnswers) filterDue(now time.Time, due func(question string) (time.Time, bool)) QuestionsAnswers {
+	filtered := NewQA()
+	for i := 0; i < qa.GetCount(); i++ {
+		if at, found := due(qa.questions[i]); !found || !at.After(now) {
+			filtered.appendEntryFrom(qa, i)
+		}
+	}
+	return filtered
+}
+
+// NextDue returns when the first of the questions of the set is due. It
+// is the zero time if the set is empty.
+func (s Schedule) NextDue(qa QuestionsAnswers) time.Time {
+	var next time.Time
+	for i := 0; i < qa.GetCount(); i++ {
+		due := s[qa.questions[i]].Due
+		if next.IsZero() || due.Before(next) {
+			next = due
+		}
+	}
+	return next
+}
+
+// Update schedules again the questions answered during the session at
+// now. A question missed at least once counts as missed.
+func (s Schedule) Update(qa QuestionsAnswers, r SessionResult, now time.Time) {
+	for _, i := range r.Right {
+		if !containsIndex(r.Wrong, i) {
+			s[qa.questions[i]] = s.get(qa.questions[i]).review(rightQuality, now)
+		}
+	}
+	for _, i := range r.Wrong {
+		s[qa.questions[i]] = s.get(qa.questions[i]).review(wrongQuality, now)
+	}
+}
+
+// get returns the state of the question, a new question starting with the
+// initial easiness.
+func (s Schedule) get(question string) Scheduled {
+	scheduled, found := s[question]
+	if !found {
+		scheduled.Easiness = initialEasiness
+	}
+	return scheduled
+}
+
+// review applies SM-2 to an answer of the given quality at now. Below 3,
+// the question starts again from the first interval. The easiness is
+// adjusted in both cases.
+func (s Scheduled) review(quality int, now time.Time) Scheduled {
+	if quality < 3 {
+		s.Repetitions = 0
+		s.Interval = 1
+	} else {
+		switch s.Repetitions {
+		case 0:
+			s.Interval = 1
+		case 1:
+			s.Interval = 6
+		default:
+			s.Interval = int(math.Round(float64(s.Interval) * s.Easiness))
+		}
+		s.Repetitions++
+	}
+	s.Easiness += 0.1 - float64(5-quality)*(0.08+float64(5-quality)*0.02)
+	if s.Easiness < minEasiness {
+		s.Easiness = minEasiness
+	}
+	s.Due = now.Add(time.Duration(s.Interval) * day)
+	return s
+}
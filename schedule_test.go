@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestReview checks the intervals of SM-2 for correct answers in a row,
+// and the restart after a mistake.
+func TestReview(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := Scheduled{Easiness: initialEasiness}
+
+	for _, interval := range []int{1, 6, 15, 38} {
+		s = s.review(rightQuality, now)
+		if s.Interval != interval || s.Easiness != initialEasiness {
+			t.Errorf("The interval should be %d days with an easiness of 2.5 but we got %+v\n", interval, s)
+		}
+	}
+	if !s.Due.Equal(now.Add(38 * day)) {
+		t.Errorf("The question should be due in 38 days but is due on %v\n", s.Due)
+	}
+
+	s = s.review(wrongQuality, now)
+	if s.Interval != 1 || s.Repetitions != 0 || s.Easiness > 2 {
+		t.Errorf("A mistake should start again from one day and lower the easiness but we got %+v\n", s)
+	}
+	for n := 0; n < 5; n++ {
+		s = s.review(wrongQuality, now)
+	}
+	if s.Easiness != minEasiness {
+		t.Errorf("The easiness should not go under %f but is %f\n", minEasiness, s.Easiness)
+	}
+}
+
+// TestFilterDue checks that only the new questions and the questions due
+// are asked, and that a session schedules them again.
+func TestFilterDue(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("manger", "to eat")
+	qa.AddEntry("boire", "to drink")
+	qa.AddEntry("dormir", "to sleep")
+
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	schedule := make(Schedule)
+	if due := schedule.FilterDue(qa, now); due.GetCount() != 3 {
+		t.Errorf("The new questions should all be due but we got %v\n", due.questions)
+	}
+
+	schedule.Update(qa, SessionResult{Right: []int{0, 1}, Wrong: []int{1}}, now)
+	tomorrow := now.Add(day)
+	if due := schedule.FilterDue(qa, now); !reflect.DeepEqual(due.questions, []string{"dormir"}) {
+		t.Errorf("Only the question not answered should be due today but we got %v\n", due.questions)
+	}
+	if due := schedule.FilterDue(qa, tomorrow); due.GetCount() != 3 {
+		t.Errorf("All the questions should be due tomorrow but we got %v\n", due.questions)
+	}
+	if schedule["boire"].Repetitions != 0 {
+		t.Errorf("A question missed once in the session should count as missed.")
+	}
+
+	var out bytes.Buffer
+	schedule.Write(&out)
+	read, err := ReadSchedule(&out)
+	if err != nil || !read["manger"].Due.Equal(tomorrow) {
+		t.Errorf("The schedule should be read back but we got %v and %v\n", read, err)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// passingQuality is the lowest quality of an answer known. The questions
+// graded below it by the user are asked again at the end of the session.
+const passingQuality = 3
+
+// gradePrompt asks the user to grade the answer just revealed.
+const gradePrompt = "Did you know it? (y/n, or 1 to 5)"
+
+// IsSelfGraded tells if the user grades the answers once revealed.
+func (p InterrogationParameters) IsSelfGraded() bool {
+	return p.selfGrade
+}
+
+// parseGrade reads the grade typed by the user: y and n, or a quality
+// from 1 to 5. ok is false if the text is not a grade.
+func parseGrade(text string) (quality int, ok bool) {
+	text = strings.ToLower(strings.TrimSpace(text))
+	switch text {
+	case yesCommand:
+		return rightQuality, true
+	case noCommand:
+		return wrongQuality, true
+	}
+	quality, err := strconv.Atoi(text)
+	if err != nil || quality < 1 || quality > 5 {
+		return 0, false
+	}
+	return quality, true
+}
+
+// selfGrade asks the user to grade the answer of the current card until a
+// grade is typed. The other commands work like before the answer is
+// revealed. It returns the command typed when it is not a grade, like the
+// quit command, and ok is false when no grade was given.
+func (s *Session) selfGrade(ctx context.Context, current shownCard, previous *shownCard, result *SessionResult) (quality int, cmd string, ok bool) {
+	for {
+		s.emit(Event{Kind: InfoShown, Text: gradePrompt})
+		given, command, typed := s.waitForAnswer(ctx, current, previous, result)
+		if !typed || command == quitCommand || command == quitLongCommand {
+			return 0, command, false
+		}
+		if quality, ok := parseGrade(given); ok {
+			return quality, given, true
+		}
+	}
+}
+
+// askAgain asks once more the cards graded below passingQuality during the
+// session, until the user quits. Their grades are not counted and the
+// cards are not asked a third time. The cards can still be flagged.
+func (s *Session) askAgain(ctx context.Context, cards []shownCard, result *SessionResult) {
+	if len(cards) == 0 {
+		return
+	}
+	s.emit(Event{Kind: InfoShown, Text: "Questions to review again: " + strconv.Itoa(len(cards))})
+	var previous *shownCard
+	for n := range cards {
+		if s.failure.hasFailed() || ctx.Err() != nil {
+			return
+		}
+		card := cards[n]
+		s.emit(Event{Kind: QuestionShown, Text: card.prompt, Again: true})
+		_, cmd, _ := s.waitForAnswer(ctx, card, previous, result)
+		if cmd == quitCommand || cmd == quitLongCommand {
+			return
+		}
+		s.emit(Event{Kind: AnswerRevealed, Text: card.answer, Again: true})
+		if _, cmd, ok := s.selfGrade(ctx, card, previous, result); !ok && cmd != "" {
+			return
+		}
+		previous = &cards[n]
+	}
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseGrade checks the grades the user can type.
+func TestParseGrade(t *testing.T) {
+	cases := []struct {
+		text    string
+		quality int
+		ok      bool
+	}{
+		{"y", rightQuality, true},
+		{" N ", wrongQuality, true},
+		{"5", 5, true},
+		{"1", 1, true},
+		{"0", 0, false},
+		{"6", 0, false},
+		{"", 0, false},
+		{"maybe", 0, false},
+	}
+	for _, c := range cases {
+		if quality, ok := parseGrade(c.text); quality != c.quality || ok != c.ok {
+			t.Errorf("The grade '%s' should give %d and %v but we got %d and %v\n", c.text, c.quality, c.ok, quality, ok)
+		}
+	}
+}
+
+// TestSelfGrade checks that the answers graded low count as wrong and are
+// asked again at the end of the session, without being counted again.
+func TestSelfGrade(t *testing.T) {
+	qa := ParseTopic(strings.NewReader("### Lesson 1\nmanger;to eat\nboire;to drink\ndormir;to sleep\n"), getTpp()).BuildQuestionsSet()
+
+	userIn, userOut := io.Pipe()
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.in = userIn
+	ip.limit = 1
+	ip.selfGrade = true
+
+	// A wrong grade is typed again. The second question is not known, the
+	// third one scores 2 and is not known either.
+	go func() {
+		for _, input := range []string{"", "y", "", "maybe", "n", "", "2", "", "y", "", "4"} {
+			fmt.Fprintln(userOut, input)
+		}
+	}()
+
+	var result SessionResult
+	pr, pw := io.Pipe()
+	ip.out = pw
+	go func() {
+		defer pw.Close()
+		result = AskQuestions(qa, ip)
+	}()
+	output, _ := ioutil.ReadAll(pr)
+
+	if result.Graded != 3 || result.Correct != 1 || !reflect.DeepEqual(result.Wrong, []int{1, 2}) {
+		t.Errorf("The questions 1 and 2 should be wrong but we got %+v\n", result)
+	}
+	if !strings.Contains(string(output), "Questions to review again: 2") {
+		t.Errorf("The questions not known should be asked again. Output was:\n%s\n", output)
+	}
+	if strings.Count(string(output), "boire") != 2 || strings.Count(string(output), "dormir") != 2 || strings.Count(string(output), "manger") != 1 {
+		t.Errorf("Only the questions not known should be asked twice. Output was:\n%s\n", output)
+	}
+	if !strings.Contains(string(output), "Score: 1/3") {
+		t.Errorf("The score should be written at the end. Output was:\n%s\n", output)
+	}
+}
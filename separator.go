@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// defaultSeparator is the separator between the question and the answer
+// when none is set and none is found in the deck.
+const defaultSeparator = ";"
+
+// separatorCandidates are the separators looked for in a deck when none is
+// set, the first ones winning the ties.
+var separatorCandidates = []string{";", ",", "\t"}
+
+// tabSeparator is the candidate chosen when it is on every line: a tab is
+// rarely part of a question or an answer, unlike the other ones.
+const tabSeparator = "\t"
+
+// sniffSize is the number of bytes read at the start of a deck to find its
+// separator.
+const sniffSize = 4096
+
+// detectSeparator returns the parameters with the separator of the csv deck
+// read from r, if DetectSeparator is set, and a reader giving the whole
+// deck. Among the first lines that do not announce a topic, the separator
+// is the tab if it is on all of them. Otherwise it is the candidate
+// splitting most of them into the same number of fields, or QaSep if none
+// is found.
+func (p TopicParsingParameters) detectSeparator(r io.Reader) (TopicParsingParameters, io.Reader) {
+	if !p.DetectSeparator {
+		return p, r
+	}
+	reader := bufio.NewReaderSize(r, sniffSize)
+	start, _ := reader.Peek(sniffSize)
+	var lines []string
+	for _, line := range strings.Split(string(start), "\n") {
+		if len(strings.TrimSpace(line)) != 0 && (p.TopicAnnounce == "" || !strings.HasPrefix(line, p.TopicAnnounce)) {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) != 0 && consistentLines(lines, tabSeparator) == len(lines) {
+		p.QaSep = tabSeparator
+		return p, reader
+	}
+	best, bestCount := "", 0
+	for _, candidate := range separatorCandidates {
+		if count := consistentLines(lines, candidate); count > bestCount {
+			best, bestCount = candidate, count
+		}
+	}
+	if bestCount > 0 {
+		p.QaSep = best
+	}
+	return p, reader
+}
+
+// consistentLines returns the number of lines holding the separator that
+// it splits into the number of fields most of them have.
+func consistentLines(lines []string, separator string) int {
+	fields := make(map[int]int)
+	most := 0
+	for _, line := range lines {
+		if n := strings.Count(line, separator); n > 0 {
+			fields[n+1]++
+			if fields[n+1] > most {
+				most = fields[n+1]
+			}
+		}
+	}
+	return most
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDetectSeparator checks that the separator of a deck is found when it
+// is not set.
+func TestDetectSeparator(t *testing.T) {
+	cases := []struct {
+		deck      string
+		separator string
+		answer    string
+	}{
+		{"### Verbs\nmanger;to eat\nboire;to drink, to sip\n", ";", "to drink, to sip"},
+		{"### Verbs\nmanger,to eat\nboire,to drink\n", ",", "to drink"},
+		{"### Verbs\nmanger\tto eat\nboire\tto drink; to sip\n", "\t", "to drink; to sip"},
+		{"### Verbs\nmanger\tto eat, to dine\nboire\tto drink, to sip\n", "\t", "to drink, to sip"},
+		{"### Verbs\nmanger,to eat; to dine; to have a meal\nboire,to drink; to sip\n", ",", "to drink; to sip"},
+		{"### Verbs\nmanger\nboire\n", ";", ""},
+	}
+	for _, c := range cases {
+		tpp := NewInterrogationParameters().GetTopicParsingParameters()
+		tpp.QuestionsOnly = true
+		topic := ParseTopic(strings.NewReader(c.deck), tpp)
+		qa := topic.BuildQuestionsSet("Verbs")
+		if qa.GetCount() != 2 || qa.questions[1] != "boire" || qa.answers[1] != c.answer {
+			t.Errorf("The separator '%s' should be found in %q but we got %q and %q\n", c.separator, c.deck, qa.questions, qa.answers)
+		}
+	}
+}
+
+// TestSetSeparator checks that the separator set with -sep is not
+// replaced by the one found in the deck, and that -prefix sets the announce
+// of the topics.
+func TestSetSeparator(t *testing.T) {
+	p, err := parse(noEnvironment, "-sep", ";", "-prefix", "== ")
+	if err != nil {
+		t.Fatalf("The options should be read but we got %v\n", err)
+	}
+	tpp := p.GetTopicParsingParameters()
+	if tpp.DetectSeparator || tpp.TopicAnnounce != "== " {
+		t.Errorf("The separator and the prefix should be the ones set but we got %+v\n", tpp)
+	}
+	topic := ParseTopic(strings.NewReader("== Verbs\nmanger;to eat, to dine\nboire,to drink\n"), tpp)
+	qa := topic.BuildQuestionsSet("Verbs")
+	if qa.GetCount() != 1 || qa.answers[0] != "to eat, to dine" {
+		t.Errorf("Only the line with the separator set should be a question but we got %q\n", qa.questions)
+	}
+}
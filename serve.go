@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// DeckHandler serves the page of the flashcards of the deck at path, like
+// -export html writes it. The deck is read again for each request so that
+// the changes made to the file are shown once the page is reloaded.
+func DeckHandler(path string, p TopicParsingParameters) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		topic, err := ParseTopicFromFile(path, p)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// The page is written once complete so that a failure is reported
+		// with its status.
+		var page bytes.Buffer
+		if err := topic.WriteHTML(&page); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		page.WriteTo(w)
+	})
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestDeckHandler checks that the flashcards of the deck are served.
+func TestDeckHandler(t *testing.T) {
+	handler := DeckHandler(writeDeck(t, "deck.csv", "### Lesson 1\nmanger;to eat\n"), getTpp())
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `<div class="question">manger</div>`) {
+		t.Errorf("The flashcards should be served but we got %d:\n%s\n", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest("GET", "/other", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Only the root should be served but we got %d\n", rec.Code)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// Session runs a session of questions and tells its listeners what
+// happens, so that a frontend renders it its own way, like a terminal, a
+// web page or a bot. The commands of the user are given with Send instead
+// of being read from the input of the parameters.
+type Session struct {
+	qa        QuestionsAnswers
+	p         InterrogationParameters
+	listeners []func(Event)
+	commands  chan string    // the commands of the user, like the answers typed in interactive mode
+	events    chan Event     // the events waiting to be given to the listeners
+	failure   *outputFailure // set when a listener cannot render the events anymore
+	done      chan struct{}  // closed when the session is over
+}
+
+// NewSession returns the session asking the questions of the set with the
+// parameters.
+func NewSession(qa QuestionsAnswers, p InterrogationParameters) *Session {
+	p.in = nil
+	return newSession(qa, p, make(chan string))
+}
+
+// newSession returns the session reading the commands of the user from the
+// channel, and from the input of the parameters if any. The event channel
+// holds one event so that the end of the session is never blocked by a
+// listener stuck on the event before.
+func newSession(qa QuestionsAnswers, p InterrogationParameters, commands chan string) *Session {
+	return &Session{
+		qa:       qa,
+		p:        p,
+		commands: commands,
+		events:   make(chan Event, 1),
+		failure:  newOutputFailure(),
+		done:     make(chan struct{}),
+	}
+}
+
+// Listen registers a listener of the events of the session. The listeners
+// are called in the order they were registered, one event at a time, from
+// a goroutine of the session: it waits for them to return. Listen must be
+// called before Run.
+func (s *Session) Listen(listener func(Event)) {
+	s.listeners = append(s.listeners, listener)
+}
+
+// Send sends a command of the user, like the answer typed in interactive
+// mode. It waits for the session to read it. The commands sent once the
+// session is over are dropped.
+func (s *Session) Send(command string) {
+	select {
+	case s.commands <- command:
+	case <-s.done:
+	}
+}
+
+// Run asks the questions until the end of the session or until the context
+// is done. The last event is SessionEnded, with the result returned.
+func (s *Session) Run(ctx context.Context) SessionResult {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go s.deliver(&wg)
+	result := s.ask(ctx)
+	close(s.done)
+	s.emit(Event{Kind: SessionEnded, Result: result})
+	close(s.events)
+	waitGoroutines(&wg, s.p)
+	// The last events may have failed to be rendered.
+	result.Err = s.failure.err
+	return result
+}
+
+// emit gives the event to the listeners.
+func (s *Session) emit(e Event) {
+	s.events <- e
+}
+
+// deliver calls the listeners with each event until the session is over.
+func (s *Session) deliver(wg *sync.WaitGroup) {
+	defer wg.Done()
+	for e := range s.events {
+		for _, listener := range s.listeners {
+			listener(e)
+		}
+	}
+}
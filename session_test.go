@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// TestSession checks that each listener receives the events of the session
+// in order, and that the answers are given with Send.
+func TestSession(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("manger", "to eat")
+
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.limit = 1
+	ip.graded = true
+
+	session := NewSession(qa, ip)
+	var first, second []EventKind
+	session.Listen(func(e Event) {
+		first = append(first, e.Kind)
+		if e.Kind == QuestionShown {
+			go session.Send("to eat")
+		}
+	})
+	session.Listen(func(e Event) {
+		second = append(second, e.Kind)
+	})
+	result := session.Run(context.Background())
+
+	expected := []EventKind{LoopStarted, QuestionShown, AnswerRevealed, AnswerGraded, InfoShown, SessionEnded}
+	if !reflect.DeepEqual(first, expected) || !reflect.DeepEqual(second, expected) {
+		t.Errorf("Both listeners should receive %v but we got %v and %v\n", expected, first, second)
+	}
+	if result.Correct != 1 {
+		t.Errorf("The answer sent should be correct but we got %+v\n", result)
+	}
+	// The session is over: the command is dropped instead of blocking.
+	session.Send("to drink")
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// GetStatsPath returns the path of the file where the statistics of each
+// question are kept across the sessions. Empty means no statistics.
+func (p InterrogationParameters) GetStatsPath() string {
+	return p.stats
+}
+
+// IsWeakestFirst tells if the questions the most often missed in the
+// previous sessions are asked first.
+func (p InterrogationParameters) IsWeakestFirst() bool {
+	return p.weakFirst
+}
+
+// QuestionStats are the answers given to a question across the sessions.
+// Correct and Incorrect only count the graded answers.
+type QuestionStats struct {
+	Attempts  int       `json:"attempts"`
+	Correct   int       `json:"correct"`
+	Incorrect int       `json:"incorrect"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// ErrorRate returns the part of the graded answers that were wrong. It is
+// 0 for a question never graded.
+func (q QuestionStats) ErrorRate() float64 {
+	graded := q.Correct + q.Incorrect
+	if graded == 0 {
+		return 0
+	}
+	return float64(q.Incorrect) / float64(graded)
+}
+
+// Stats holds the statistics of the questions, keyed by the hash of their
+// text so that the file does not disclose the deck.
+type Stats map[string]QuestionStats
+
+// questionHash returns a SHA-256 hash, in hexadecimal, of the question.
+func questionHash(question string) string {
+	sum := sha256.Sum256([]byte(question))
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadStats reads the statistics saved in JSON by Save. The file does not
+// exist before the first session.
+func LoadStats(path string) (Stats, error) {
+	stats := make(Stats)
+	if err := loadJSON(path, &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// Save writes the statistics in JSON to the file at path, creating its
+// directory if needed.
+func (s Stats) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return writeFile(path, s.Write)
+}
+
+// Write writes the statistics in JSON.
+func (s Stats) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s)
+}
+
+// Get returns the statistics of the question. They are empty for a
+// question never asked.
+func (s Stats) Get(question string) QuestionStats {
+	return s[questionHash(question)]
+}
+
+// seen records that the answer of the question was shown at now.
+func (s Stats) seen(question string, now time.Time) {
+	hash := questionHash(question)
+	stats := s[hash]
+	stats.Attempts++
+	stats.LastSeen = now
+	s[hash] = stats
+}
+
+// answered records a graded answer to the question.
+func (s Stats) answered(question string, correct bool) {
+	hash := questionHash(question)
+	stats := s[hash]
+	if correct {
+		stats.Correct++
+	} else {
+		stats.Incorrect++
+	}
+	s[hash] = stats
+}
+
+// WriteReport writes the statistics of the questions of the set, the most
+// often missed first. The questions never asked are only counted.
+func (s Stats) WriteReport(w io.Writer, qa QuestionsAnswers) error {
+	out := &stickyWriter{w: w}
+	sorted := s.SortByWeakness(qa)
+	never := 0
+	for i := 0; i < sorted.GetCount(); i++ {
+		stats := s.Get(sorted.questions[i])
+		if stats.Attempts == 0 {
+			never++
+			continue
+		}
+		fmt.Fprintf(out, "%3.0f%% missed, %d/%d correct, %d attempts, last seen %s: %s\n",
+			100*stats.ErrorRate(), stats.Correct, stats.Correct+stats.Incorrect, stats.Attempts,
+			stats.LastSeen.Format("2006-01-02"), sorted.questions[i])
+	}
+	fmt.Fprintf(out, "Questions never asked: %d/%d\n", never, qa.GetCount())
+	return out.err
+}
+
+// SortByWeakness returns a new set with the entries the most often missed
+// first. The entries with the same error rate keep their order.
+func (s Stats) SortByWeakness(qa QuestionsAnswers) QuestionsAnswers {
+	indexes := make([]int, qa.GetCount())
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(a, b int) bool {
+		return s.Get(qa.questions[indexes[a]]).ErrorRate() > s.Get(qa.questions[indexes[b]]).ErrorRate()
+	})
+	sorted := NewQA()
+	for _, i := range indexes {
+		sorted.appendEntryFrom(qa, i)
+	}
+	return sorted
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestStatsRecorded checks that a session records the attempts, the
+// answers and the time each question was last seen.
+func TestStatsRecorded(t *testing.T) {
+	qa := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp()).BuildQuestionsSet()
+
+	userIn, userOut := io.Pipe()
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.in = userIn
+	ip.limit = 2
+	ip.graded = true
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	ip.clock = &fakeClock{now: now}
+	ip.Stats = make(Stats)
+
+	// Question 1 is missed on the first loop only.
+	go func() {
+		for loop := 0; loop < ip.limit; loop++ {
+			for i := range qa.answers {
+				answer := qa.answers[i]
+				if i == 1 && loop == 0 {
+					answer = "no idea"
+				}
+				fmt.Fprintln(userOut, answer)
+			}
+		}
+	}()
+
+	pr, pw := io.Pipe()
+	ip.out = pw
+	go func() {
+		defer pw.Close()
+		AskQuestions(qa, ip)
+	}()
+	io.Copy(ioutil.Discard, pr)
+
+	expected := QuestionStats{Attempts: 2, Correct: 1, Incorrect: 1, LastSeen: now}
+	if stats := ip.Stats.Get(qa.questions[1]); stats != expected {
+		t.Errorf("The statistics of the question 1 should be %+v but we got %+v\n", expected, stats)
+	}
+	if stats := ip.Stats.Get(qa.questions[0]); stats.Attempts != 2 || stats.Correct != 2 || stats.ErrorRate() != 0 {
+		t.Errorf("The question 0 should have been answered correctly twice but we got %+v\n", stats)
+	}
+	if _, found := ip.Stats[qa.questions[0]]; found {
+		t.Errorf("The statistics should be keyed by the hash of the question, not its text.")
+	}
+}
+
+// TestStatsLoadSave checks that the statistics are read back, a missing
+// file giving no statistics.
+func TestStatsLoadSave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".simple-learning", "stats.db")
+	stats, err := LoadStats(path)
+	if err != nil || len(stats) != 0 {
+		t.Fatalf("A missing file should give no statistics but we got %v and %v", stats, err)
+	}
+	stats.seen("manger", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	stats.answered("manger", false)
+	if err := stats.Save(path); err != nil {
+		t.Fatalf("Saving the statistics failed: %v", err)
+	}
+	read, err := LoadStats(path)
+	if err != nil {
+		t.Fatalf("Loading the statistics failed: %v", err)
+	}
+	if !reflect.DeepEqual(read, stats) {
+		t.Errorf("The statistics read back %v differ from the ones saved %v\n", read, stats)
+	}
+}
+
+// TestSortByWeakness checks that the questions the most often missed come
+// first.
+func TestSortByWeakness(t *testing.T) {
+	qa := ParseTopic(strings.NewReader("### Lesson 1\nmanger;to eat\nboire;to drink\ndormir;to sleep\n"), getTpp()).BuildQuestionsSet()
+	stats := make(Stats)
+	stats.answered("manger", true)
+	stats.answered("dormir", false)
+	stats.answered("dormir", true)
+
+	sorted := stats.SortByWeakness(qa)
+	if expected := []string{"dormir", "manger", "boire"}; !reflect.DeepEqual(sorted.questions, expected) {
+		t.Errorf("The questions should be sorted as %v but we got %v\n", expected, sorted.questions)
+	}
+}
+
+// TestParsingWeakestFirst checks that -weakest-first asks the questions in
+// the order it builds, whatever the mode.
+func TestParsingWeakestFirst(t *testing.T) {
+	p, err := parse(noEnvironment, "-stats", "stats.db", "-weakest-first", "-m", "random")
+	if err != nil {
+		t.Fatalf("Parsing detects -weakest-first as an error: %v\n", err)
+	}
+	if !p.IsWeakestFirst() || p.mode != linear {
+		t.Errorf("The weakest first mode should be set and keep the order it builds: %+v\n", p)
+	}
+}
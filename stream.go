@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+)
+
+// EventKind tells what happened in a session run by a Session.
+type EventKind int
+
+const (
+	LoopStarted    EventKind = iota // a new loop on the questions starts
+	QuestionShown                   // a question is shown, or shown again
+	AnswerRevealed                  // the answer of the question is revealed
+	AnswerGraded                    // the answer typed by the user was checked
+	InfoShown                       // a line of information, like the score or the previous question
+	SessionEnded                    // the session is over. This is the last event
+)
+
+// Event describes what happened in a session so that a user interface can
+// render it.
+type Event struct {
+	Kind     EventKind
+	Text     string        // the question, the answer, the verdict or the information
+	Section  string        // for QuestionShown, the subsection of the question, if known
+	Echo     string        // for AnswerRevealed in echo mode, the answer typed with its mistakes marked
+	Repeated bool          // for QuestionShown, the question waiting for its answer is shown again
+	Again    bool          // for QuestionShown and AnswerRevealed, the card is asked again at the end of the session, out of the loops
+	Aside    bool          // for InfoShown, the information is shown while the question waits for its answer, like a hint
+	Loop     int           // for LoopStarted, the number of the loop starting from 1
+	Correct  bool          // for AnswerGraded, tells if the user found the answer
+	Result   SessionResult // for SessionEnded, the result of the session
+}
+
+// AskQuestionsStream runs the session of AskQuestions in the background and
+// sends what happens as events instead of writing it. The channel is closed
+// after the SessionEnded event and must be read until then, the session
+// waiting for the events to be read. The function returned sends the
+// commands of the user, like the answers typed in interactive mode. The
+// input of the parameters is not read.
+func AskQuestionsStream(qa QuestionsAnswers, p InterrogationParameters) (<-chan Event, func(command string)) {
+	return AskQuestionsStreamContext(context.Background(), qa, p)
+}
+
+// AskQuestionsStreamContext is AskQuestionsStream stopping when the context
+// is done, like AskQuestionsContext. Once it is done, the events are
+// dropped instead of waiting to be read. SessionEnded then takes the place
+// of the event left in the buffer of the channel so that the session ends
+// even if nobody reads it anymore.
+func AskQuestionsStreamContext(ctx context.Context, qa QuestionsAnswers, p InterrogationParameters) (<-chan Event, func(command string)) {
+	events := make(chan Event, 1)
+	session := NewSession(qa, p)
+	session.Listen(func(e Event) {
+		if e.Kind == SessionEnded {
+			select {
+			case events <- e:
+			case <-ctx.Done():
+				// The session is the only sender: once the buffer is
+				// emptied, the event is sent without waiting.
+				select {
+				case <-events:
+				default:
+				}
+				events <- e
+			}
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		select {
+		case events <- e:
+		case <-ctx.Done():
+		}
+	})
+	go func() {
+		session.Run(ctx)
+		close(events)
+	}()
+	return events, session.Send
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAskQuestionsStream checks the events of a short graded session where
+// the answers are sent through the function returned.
+func TestAskQuestionsStream(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("manger", "to eat")
+	qa.AddEntry("boire", "to drink")
+
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.limit = 1
+	ip.graded = true
+
+	events, send := AskQuestionsStream(qa, ip)
+	var kinds []EventKind
+	var last Event
+	for e := range events {
+		kinds = append(kinds, e.Kind)
+		if e.Kind == QuestionShown {
+			switch e.Text {
+			case "manger":
+				go send("to eat")
+			case "boire":
+				go send("to sleep")
+			}
+		}
+		if e.Kind == AnswerGraded && e.Correct != (e.Text == "Correct") {
+			t.Errorf("The graded event is inconsistent: %+v\n", e)
+		}
+		last = e
+	}
+
+	expected := []EventKind{
+		LoopStarted,
+		QuestionShown, AnswerRevealed, AnswerGraded,
+		QuestionShown, AnswerRevealed, AnswerGraded,
+		InfoShown,
+		SessionEnded,
+	}
+	if !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("The events should be %v but we got %v\n", expected, kinds)
+	}
+	if last.Result.Asked != 2 || last.Result.Correct != 1 {
+		t.Errorf("The session should end with 1 correct answer out of 2 but we got %+v\n", last.Result)
+	}
+}
+
+// TestAskQuestionsStreamDeadline checks that a session stops at the
+// deadline of its context, even when the events are not read anymore.
+func TestAskQuestionsStreamDeadline(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("manger", "to eat")
+
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.clock = systemClock{}
+	ip.minWait, ip.maxWait = time.Hour, time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	events, _ := AskQuestionsStreamContext(ctx, qa, ip)
+	// Only the first event is read before the deadline.
+	<-events
+	<-ctx.Done()
+
+	var last Event
+	for e := range events {
+		last = e
+	}
+	if last.Kind != SessionEnded || !last.Result.Interrupted {
+		t.Errorf("The session should end interrupted but the last event is %+v\n", last)
+	}
+}
+
+// TestAskQuestionsStreamNoReader checks that a session stopped by its
+// context ends even when its events are not read, and that SessionEnded is
+// still the last event.
+func TestAskQuestionsStreamNoReader(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("manger", "to eat")
+
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.clock = systemClock{}
+	ip.minWait, ip.maxWait = time.Hour, time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events, _ := AskQuestionsStreamContext(ctx, qa, ip)
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+
+	var last Event
+	for e := range events {
+		last = e
+	}
+	if last.Kind != SessionEnded || !last.Result.Interrupted {
+		t.Errorf("The session should end interrupted but the last event is %+v\n", last)
+	}
+}
+
+// TestReadCommandsStops checks that the input is not read anymore once the
+// context is done.
+func TestReadCommandsStops(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	commands := make(chan string)
+	go readCommands(ctx, strings.NewReader("a\nb\n"), commands)
+
+	if cmd := <-commands; cmd != "a" {
+		t.Errorf("The first line should be read but we got '%s'\n", cmd)
+	}
+	cancel()
+	// The second line may already wait to be sent, but the channel is
+	// closed after it.
+	for range commands {
+	}
+}
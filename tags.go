@@ -0,0 +1,61 @@
+package main
+
+import (
+	"strings"
+)
+
+// extractTags removes the tags found at the end of the answer. A tag is a
+// word starting with the prefix. The answer is returned without its tags
+// and trimmed of the spaces that separated it from them.
+func extractTags(answer string, prefix string) (string, []string) {
+	words := strings.Fields(answer)
+	end := len(words)
+	for end > 0 && strings.HasPrefix(words[end-1], prefix) && len(words[end-1]) > len(prefix) {
+		end--
+	}
+	if end == len(words) {
+		return answer, nil
+	}
+	tags := make([]string, 0, len(words)-end)
+	for _, word := range words[end:] {
+		tags = append(tags, strings.TrimPrefix(word, prefix))
+	}
+	// Cut the answer just before the first tag to keep its inner spacing.
+	trimmed := strings.TrimRight(answer, " \t")
+	for i := len(words) - 1; i >= end; i-- {
+		trimmed = strings.TrimRight(strings.TrimSuffix(trimmed, words[i]), " \t")
+	}
+	return trimmed, tags
+}
+
+// HasTag tells if the entry of index i carries the tag.
+func (qa QuestionsAnswers) HasTag(i int, tag string) bool {
+	for _, t := range qa.tags[i] {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterByTag returns a new set made of the entries carrying one of the
+// tags.
+func (qa QuestionsAnswers) FilterByTag(tags ...string) QuestionsAnswers {
+	filtered := NewQA()
+	for i := 0; i < qa.GetCount(); i++ {
+		for _, tag := range tags {
+			if qa.HasTag(i, tag) {
+				filtered.appendEntryFrom(qa, i)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// BuildQuestionsSetByTag builds the set of the questions carrying one of
+// the tags, across all the subsections of the topic. They keep the order of
+// the topic.
+func (topic Topic) BuildQuestionsSetByTag(tags ...string) QuestionsAnswers {
+	return topic.BuildQuestionsSet().FilterByTag(tags...)
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestExtractTags checks that the tags are removed from the answer.
+func TestExtractTags(t *testing.T) {
+	cases := []struct {
+		input  string
+		answer string
+		tags   []string
+	}{
+		{"answer", "answer", nil},
+		{"answer #verb", "answer", []string{"verb"}},
+		{"an  answer #verb #common", "an  answer", []string{"verb", "common"}},
+		{"C# is a language", "C# is a language", nil},
+		{"answer #", "answer #", nil},
+	}
+	for _, c := range cases {
+		answer, tags := extractTags(c.input, "#")
+		if answer != c.answer {
+			t.Errorf("Extracting tags from '%s' should leave '%s' as answer but we got '%s'\n", c.input, c.answer, answer)
+		}
+		if !reflect.DeepEqual(tags, c.tags) {
+			t.Errorf("Extracting tags from '%s' should give %v but we got %v\n", c.input, c.tags, tags)
+		}
+	}
+}
+
+func getSampleTaggedCsvAsStream() string {
+	return `
+### Lesson 1
+manger;to eat #verb #common
+pomme;apple #noun
+courir;to run #verb
+
+### Lesson 2
+maison;house #noun #common
+`
+}
+
+// TestParseTaggedStream checks that the tags are extracted when parsing and
+// that the displayed answers do not contain them anymore.
+func TestParseTaggedStream(t *testing.T) {
+	tpp := getTpp()
+	tpp.TagPrefix = "#"
+	topic := ParseTopic(strings.NewReader(getSampleTaggedCsvAsStream()), tpp)
+
+	qa := topic.GetSubsection("1")
+	if qa.answers[0] != "to eat" {
+		t.Errorf("The tags should have been removed from the answer but we got '%s'\n", qa.answers[0])
+	}
+	if !reflect.DeepEqual(qa.tags[0], []string{"verb", "common"}) {
+		t.Errorf("The tags of the first entry should be [verb common] but we got %v\n", qa.tags[0])
+	}
+}
+
+// TestParseStreamWithoutTagPrefix checks that tags are kept in the answer
+// when the tag prefix is not set.
+func TestParseStreamWithoutTagPrefix(t *testing.T) {
+	topic := ParseTopic(strings.NewReader(getSampleTaggedCsvAsStream()), getTpp())
+
+	qa := topic.GetSubsection("1")
+	if qa.answers[0] != "to eat #verb #common" {
+		t.Errorf("The answer should be left untouched but we got '%s'\n", qa.answers[0])
+	}
+}
+
+// TestFilterByTag checks that only the entries carrying the tag are kept.
+func TestFilterByTag(t *testing.T) {
+	tpp := getTpp()
+	tpp.TagPrefix = "#"
+	topic := ParseTopic(strings.NewReader(getSampleTaggedCsvAsStream()), tpp)
+	qa := topic.BuildQuestionsSet("1", "2")
+
+	verbs := qa.FilterByTag("verb")
+	if !reflect.DeepEqual(verbs.questions, []string{"manger", "courir"}) {
+		t.Errorf("Filtering on the tag verb should give [manger courir] but we got %v\n", verbs.questions)
+	}
+	common := qa.FilterByTag("common")
+	if !reflect.DeepEqual(common.questions, []string{"manger", "maison"}) {
+		t.Errorf("Filtering on the tag common should give [manger maison] but we got %v\n", common.questions)
+	}
+	if qa.FilterByTag("adjective").GetCount() != 0 {
+		t.Errorf("Filtering on an unknown tag should give an empty set.")
+	}
+	either := qa.FilterByTag("noun", "common")
+	if !reflect.DeepEqual(either.questions, []string{"manger", "pomme", "maison"}) {
+		t.Errorf("Filtering on the tags noun and common should give [manger pomme maison] once each but we got %v\n", either.questions)
+	}
+}
+
+// TestBuildQuestionsSetByTag checks that the questions carrying the tags
+// are taken from all the subsections.
+func TestBuildQuestionsSetByTag(t *testing.T) {
+	tpp := getTpp()
+	tpp.TagPrefix = "#"
+	topic := ParseTopic(strings.NewReader(getSampleTaggedCsvAsStream()), tpp)
+
+	qa := topic.BuildQuestionsSetByTag("noun")
+	if !reflect.DeepEqual(qa.questions, []string{"pomme", "maison"}) {
+		t.Errorf("The nouns of both subsections should be asked but we got %v\n", qa.questions)
+	}
+	if qa.GetOrigin(1) != "2" {
+		t.Errorf("The subsection of the question should be kept but we got '%s'\n", qa.GetOrigin(1))
+	}
+}
+
+// TestParsingTag checks that the option -tag is detected.
+func TestParsingTag(t *testing.T) {
+	p, err := parse(noEnvironment, "-tag", "verb")
+	if err != nil {
+		t.Errorf("Parsing detects the tag option as an error")
+	}
+	if p.GetTag() != "verb" {
+		t.Errorf("Parsing failed to set the tag. Found '%s'\n", p.GetTag())
+	}
+	p, err = parse(noEnvironment, "-tag", "grammar, verbs,")
+	if err != nil || !reflect.DeepEqual(p.GetTags(), []string{"grammar", "verbs"}) {
+		t.Errorf("Parsing failed to set the tags. Found %v and %v\n", p.GetTags(), err)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// byteOrderMark starts the files written by some editors in UTF-8. It would
+// be read as part of the first line of the deck.
+const byteOrderMark = "\ufeff"
+
+// ValidateDeck checks the deck read from r, the format being chosen from
+// the extension of path like ParseDeck does. The problems found are
+// ParseErrors telling their line, sorted by line: the byte order mark, the
+// lines that are not UTF-8 and the ones ending with spaces, then, in a csv
+// deck, the warnings of the parser in strict mode: the lines that are
+// neither a topic nor a question, the questions or the answers that are
+// empty, the questions found twice and the ones with conflicting answers. A
+// YAML or a Markdown deck is checked to be read by ParseDeck. The error is
+// the one of the reader, if any.
+func ValidateDeck(path string, r io.Reader, p TopicParsingParameters) ([]error, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	problems := checkEncoding(data)
+	if isYAMLDeck(path) || isMarkdownDeck(path) {
+		if _, err := ParseDeck(path, bytes.NewReader(data), p); err != nil {
+			return append(problems, err), nil
+		}
+		return problems, nil
+	}
+
+	p.Strict = true
+	p = p.followIncludes(openFile, path, systemClock{}, nil)
+	_, warnings, err := parseTopic(bytes.NewReader(data), p)
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		return append(problems, err), nil
+	}
+	if err != nil {
+		return problems, err
+	}
+	for _, warning := range warnings {
+		problems = append(problems, &ParseError{Line: warning.Line, msg: warning.String()})
+	}
+	sort.SliceStable(problems, func(a, b int) bool {
+		return problems[a].(*ParseError).Line < problems[b].(*ParseError).Line
+	})
+	return problems, nil
+}
+
+// checkEncoding returns the problems of the text of a deck whatever its
+// format: the byte order mark, the lines that are not UTF-8 and the ones
+// ending with spaces.
+func checkEncoding(data []byte) []error {
+	var problems []error
+	problem := func(line int, format string, a ...interface{}) {
+		problems = append(problems, &ParseError{Line: line, msg: fmt.Sprintf("Line %d: ", line) + fmt.Sprintf(format, a...)})
+	}
+	if bytes.HasPrefix(data, []byte(byteOrderMark)) {
+		problem(1, "The deck starts with a byte order mark.")
+	}
+	for n, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if !utf8.ValidString(line) {
+			problem(n+1, "The line is not valid UTF-8.")
+		} else if strings.TrimRight(line, " \t") != line {
+			problem(n+1, "The line ends with spaces.")
+		}
+	}
+	return problems
+}
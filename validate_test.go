@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// TestValidateDeck checks the problems found in a csv deck and their line.
+func TestValidateDeck(t *testing.T) {
+	deck := "Question;Answer\n### Lesson 1\nmanger;to eat\n\nboire to drink\n;to sleep\ncourir;\nmanger;to dine\n"
+	tpp := getTpp()
+	tpp.SkipHeader = true
+	problems, err := ValidateDeck("deck.csv", strings.NewReader(deck), tpp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []int
+	for _, problem := range problems {
+		var parseErr *ParseError
+		if !errors.As(problem, &parseErr) {
+			t.Fatalf("The problem %v should be a ParseError\n", problem)
+		}
+		lines = append(lines, parseErr.Line)
+	}
+	if expected := []int{5, 6, 7, 8}; len(lines) != len(expected) || lines[0] != 5 || lines[1] != 6 || lines[2] != 7 || lines[3] != 8 {
+		t.Errorf("The problems should be at the lines %v but we got %v\n", expected, problems)
+	}
+
+	if problems, _ := ValidateDeck("deck.yaml", strings.NewReader("Lesson 1:\n  - a: to eat\n"), tpp); len(problems) != 1 {
+		t.Errorf("The YAML entry without question should be reported but we got %v\n", problems)
+	}
+}
+
+// TestValidateCloze checks that the lines with cloze deletions are
+// questions, and that their cards are checked for duplicates.
+func TestValidateCloze(t *testing.T) {
+	deck := "### Lesson 1\nThe capital of France is {{Paris}}\nThe capital of France is {{Paris}}\n"
+	problems, err := ValidateDeck("deck.csv", strings.NewReader(deck), getTpp())
+	if err != nil || len(problems) != 1 || !strings.Contains(problems[0].Error(), "also at line 2") {
+		t.Errorf("Only the second cloze line should be reported but we got %v and %v\n", problems, err)
+	}
+}
+
+// TestValidateStrict checks the problems found in strict mode: the
+// conflicting answers, the encoding and the spaces ending a line.
+func TestValidateStrict(t *testing.T) {
+	deck := "### Lesson 1\nmanger;to eat \nboire;to drink\n### Lesson 2\nmanger;to dine\nboire;to drink\nchat;\xffcat\n"
+	problems, err := ValidateDeck("deck.csv", strings.NewReader(deck), getTpp())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		"Line 2: The line ends with spaces.",
+		"Line 5: The question manger is also at line 2 with another answer: to eat",
+		"Line 6: The question boire is also at line 3.",
+		"Line 7: The line is not valid UTF-8.",
+	}
+	var found []string
+	for _, problem := range problems {
+		found = append(found, problem.Error())
+	}
+	if strings.Join(found, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("The problems should be:\n%s\nbut we got:\n%s\n", strings.Join(expected, "\n"), strings.Join(found, "\n"))
+	}
+
+	problems, _ = ValidateDeck("deck.yaml", strings.NewReader("\ufeffLesson 1:\n  - q: manger\n    a: to eat\n"), getTpp())
+	if len(problems) == 0 || problems[0].Error() != "Line 1: The deck starts with a byte order mark." {
+		t.Errorf("The byte order mark should be reported but we got %v\n", problems)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// wrapText breaks the text on spaces into lines that fit in the widths: the
+// first line fits in first columns, the other ones in next columns. The
+// widths are counted in runes so that accented letters count for one
+// column. A word longer than the width is left alone on its line. A width
+// of 0 or less means no wrapping.
+func wrapText(text string, first int, next int) []string {
+	if first <= 0 || next <= 0 {
+		return []string{text}
+	}
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{text}
+	}
+	var lines []string
+	line, width := words[0], first
+	for _, word := range words[1:] {
+		if utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > width {
+			lines = append(lines, line)
+			line, width = word, next
+			continue
+		}
+		line += " " + word
+	}
+	return append(lines, line)
+}
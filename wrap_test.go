@@ -0,0 +1,62 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestWrapText checks the breaking of texts into lines.
+func TestWrapText(t *testing.T) {
+	cases := []struct {
+		text     string
+		first    int
+		next     int
+		expected []string
+	}{
+		{"no wrapping at all", 0, 0, []string{"no wrapping at all"}},
+		{"short", 10, 10, []string{"short"}},
+		{"the cat is on the mat", 10, 10, []string{"the cat is", "on the mat"}},
+		{"the cat is on the mat", 4, 9, []string{"the", "cat is on", "the mat"}},
+		{"a veryveryverylongword here", 8, 8, []string{"a", "veryveryverylongword", "here"}},
+		{"élève été à côté", 10, 10, []string{"élève été", "à côté"}},
+	}
+	for _, c := range cases {
+		lines := wrapText(c.text, c.first, c.next)
+		if !reflect.DeepEqual(lines, c.expected) {
+			t.Errorf("Wrapping '%s' at %d/%d should give %q but we got %q\n", c.text, c.first, c.next, c.expected, lines)
+		}
+	}
+}
+
+// TestWrappedOutput checks that long questions and answers are wrapped at
+// the width and that the continuation lines of the answer are indented
+// under the arrow.
+func TestWrappedOutput(t *testing.T) {
+	qa := NewQA()
+	qa.AddEntry("Traduire la phrase suivante en anglais", "The quick brown fox jumps over the lazy dog near the river bank")
+
+	ip := getGenericUnattendedInterrogationParameters()
+	ip.limit = 1
+	ip.width = 30
+
+	lines := getSessionOutput(qa, ip)
+	expected := []string{
+		"Traduire la phrase suivante en",
+		"anglais     --> The quick",
+		"         brown fox jumps over",
+		"         the lazy dog near the",
+		"         river bank",
+	}
+	for _, line := range expected {
+		if !contains(lines, line) {
+			t.Errorf("The line '%s' is missing. Output was:\n%s\n", line, strings.Join(lines, "\n"))
+		}
+	}
+	for _, line := range lines {
+		if utf8.RuneCountInString(line) > ip.width && !separator.MatchString(line) && !limitReached.MatchString(line) {
+			t.Errorf("The line '%s' is wider than %d columns.", line, ip.width)
+		}
+	}
+}
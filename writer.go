@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// wrongAnswersSubsection is the name of the subsection of the deck made of
+// the questions wrongly answered that come from no subsection.
+const wrongAnswersSubsection = "Wrong Answers"
+
+// WriteTopic writes the topic in the csv format described by the parsing
+// parameters so that it is parsed again identically: its front matter, if
+// any, then its subsections in their order. The fields of a card are the
+// ones the parameters read, the ones holding the separator being quoted
+// when the deck is read as RFC 4180 CSV.
+func WriteTopic(w io.Writer, topic Topic, p TopicParsingParameters) error {
+	bw := bufio.NewWriter(w)
+	metadata := topic.metadata
+	if metadata.Separator != "" {
+		// The deck is read again with the separator it is written with.
+		metadata.Separator = p.QaSep
+	}
+	writeFrontMatter(bw, metadata)
+	for n, id := range topic.GetSubsectionsName() {
+		if n > 0 {
+			bw.WriteString("\n")
+		}
+		// Questions found before any subsection have an empty id and no
+		// header.
+		if len(id) != 0 {
+			bw.WriteString(p.TopicAnnounce + id + "\n")
+		}
+		qa := topic.list[id]
+		for i := range qa.questions {
+			if qa.IsCloze(i) {
+				// The cards of a line with cloze deletions follow each other:
+				// the line is written once.
+				if i > 0 && qa.cloze[i-1] == qa.cloze[i] {
+					continue
+				}
+				bw.WriteString(qa.cloze[i] + p.cardAnnotations(qa, i) + "\n")
+				continue
+			}
+			fields := p.cardFields(qa, i)
+			for n, field := range fields {
+				if n > 0 {
+					bw.WriteString(p.QaSep)
+				}
+				bw.WriteString(p.csvField(field, n == len(fields)-1))
+			}
+			bw.WriteString("\n")
+		}
+	}
+	return bw.Flush()
+}
+
+// cardFields returns the fields of the line of the entry of index i: the
+// question, the answers, the hint and the note, the media and the
+// difficulty, as far as the parameters read them. The optional fields
+// are written when they are set, or when a field after them is.
+func (p TopicParsingParameters) cardFields(qa QuestionsAnswers, i int) []string {
+	answers := []string{qa.answers[i]}
+	if len(qa.alternatives[i]) != 0 {
+		answers = append([]string{}, qa.alternatives[i]...)
+		if !p.MultiAnswer && p.AlternativeSep != "" {
+			answers = []string{strings.Join(answers, p.AlternativeSep)}
+		}
+	}
+	// The tags and the prerequisites end the last answer.
+	answers[len(answers)-1] += p.cardAnnotations(qa, i)
+	fields := append([]string{qa.questions[i]}, answers...)
+	if p.HintNoteFields {
+		switch {
+		case qa.notes[i] != "":
+			fields = append(fields, qa.hints[i], qa.notes[i])
+		case qa.hints[i] != "":
+			fields = append(fields, qa.hints[i])
+		}
+	}
+	if p.MediaField && (qa.media[i] != "" || len(fields) > len(answers)+1) {
+		fields = append(fields, qa.media[i])
+	}
+	set := qa.difficulty[i] != 0 && qa.difficulty[i] != p.DefaultDifficulty
+	if p.DifficultyField && (set || len(fields) > len(answers)+1) {
+		fields = append(fields, strconv.Itoa(qa.GetDifficulty(i)))
+	}
+	return fields
+}
+
+// cardAnnotations returns the tags and the prerequisites of the entry of
+// index i, as they end its answer, when the parameters read them.
+func (p TopicParsingParameters) cardAnnotations(qa QuestionsAnswers, i int) string {
+	var annotations string
+	if p.TagPrefix != "" && len(qa.tags[i]) != 0 {
+		annotations += " " + p.TagPrefix + strings.Join(qa.tags[i], " "+p.TagPrefix)
+	}
+	if p.Prerequisites && len(qa.requires[i]) != 0 {
+		annotations += " " + requiresPrefix + strings.Join(qa.requires[i], " "+requiresPrefix)
+	}
+	return annotations
+}
+
+// csvField returns the field as written in a csv deck. When the deck is
+// read as RFC 4180 CSV, a field holding the separator or starting with a
+// quote is quoted, like a field holding a line break that is not the last
+// one of the line. The separator is left as is in the answer ending the
+// line when the fields after the answer are read as part of it. The line
+// breaks of the last field are otherwise continued with a \, unless one of
+// its next lines could not be continued: the field is then quoted. The \
+// ending the lines of the last field are escaped.
+func (p TopicParsingParameters) csvField(field string, last bool) string {
+	quoted := !p.LegacySplit && len([]rune(p.QaSep)) == 1
+	joined := last && !p.MultiAnswer && !p.HintNoteFields && !p.MediaField && !p.DifficultyField
+	lines := strings.Split(field, "\n")
+	broken := false // a line after the first one would not go on the field
+	for _, line := range lines[1:] {
+		broken = broken || strings.Contains(line, p.QaSep) || len(strings.TrimSpace(line)) == 0
+	}
+	if quoted && ((!joined && strings.Contains(field, p.QaSep)) || strings.HasPrefix(field, `"`) || (len(lines) > 1 && (!last || broken))) {
+		if last {
+			field = escapeContinuation(field)
+		}
+		return `"` + strings.ReplaceAll(field, `"`, `""`) + `"`
+	}
+	if last {
+		for n := range lines {
+			lines[n] = escapeContinuation(lines[n])
+		}
+	}
+	return strings.Join(lines, "\\\n")
+}
+
+// writeFrontMatter writes the metadata of a deck as its front matter, if
+// there is any.
+func writeFrontMatter(w io.Writer, m DeckMetadata) {
+	if m == (DeckMetadata{}) {
+		return
+	}
+	separator := m.Separator
+	if separator == "\t" {
+		separator = "tab"
+	}
+	fmt.Fprintln(w, frontMatterDelimiter)
+	for _, field := range []struct{ key, value string }{
+		{"title", m.Title},
+		{"author", m.Author},
+		{"language", m.Language},
+		{"separator", separator},
+		{"direction", m.Direction},
+	} {
+		if field.value == "" {
+			continue
+		}
+		if field.key == "separator" {
+			field.value = `"` + field.value + `"`
+		}
+		fmt.Fprintf(w, "%s: %s\n", field.key, field.value)
+	}
+	fmt.Fprintln(w, frontMatterDelimiter)
+}
+
+// deckFormats are the formats a deck can be converted to.
+var deckFormats = map[string]bool{"csv": true, "yaml": true, "html": true, "anki": true}
+
+// formatFromPath returns the format of a deck from the extension of its
+// path: yaml for .yaml and .yml files, html for .html ones, anki for .tsv
+// ones and csv otherwise.
+func formatFromPath(path string) string {
+	if isYAMLDeck(path) {
+		return "yaml"
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html":
+		return "html"
+	case ".tsv":
+		return "anki"
+	}
+	return "csv"
+}
+
+// ConvertTopic writes the topic in the format, csv, yaml, html or anki.
+// The csv format is the one described by the parsing parameters.
+func ConvertTopic(w io.Writer, topic Topic, format string, p TopicParsingParameters) error {
+	switch format {
+	case "csv":
+		return WriteTopic(w, topic, p)
+	case "yaml":
+		return WriteTopicYAML(w, topic)
+	case "html":
+		return topic.WriteHTML(w)
+	case "anki":
+		return topic.WriteAnki(w)
+	}
+	return fmt.Errorf("The format %s is unknown.", format)
+}
+
+// flaggedSubsection is the name of the subsection of the deck made of the
+// questions flagged for review that come from no subsection.
+const flaggedSubsection = "Flagged"
+
+// WrongAnswersTopic builds a topic made of the questions of the set that
+// were wrongly answered during the session, in the subsections they come
+// from.
+func WrongAnswersTopic(qa QuestionsAnswers, r SessionResult) Topic {
+	return indexesTopic(qa, r.Wrong, wrongAnswersSubsection)
+}
+
+// FlaggedTopic builds a topic made of the questions of the set that were
+// flagged for review during the session, in the subsections they come
+// from.
+func FlaggedTopic(qa QuestionsAnswers, r SessionResult) Topic {
+	return indexesTopic(qa, r.Flagged, flaggedSubsection)
+}
+
+// indexesTopic builds a topic made of the entries of the set at the
+// indexes. Each entry is in the subsection it comes from, or in the
+// subsection id when the set was not built from a topic.
+func indexesTopic(qa QuestionsAnswers, indexes []int, id string) Topic {
+	topic := NewTopic()
+	for _, i := range indexes {
+		origin := qa.origin[i]
+		if origin == "" {
+			origin = id
+		}
+		selected := topic.GetSubsection(origin)
+		selected.appendEntryFrom(qa, i)
+		topic.SetSubsection(origin, selected)
+	}
+	return topic
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// TestWriteTopic checks that a written topic is parsed back identically.
+func TestWriteTopic(t *testing.T) {
+	topic := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp())
+
+	var out bytes.Buffer
+	if err := WriteTopic(&out, topic, getTpp()); err != nil {
+		t.Fatalf("Writing the topic failed: %v", err)
+	}
+	parsed := ParseTopic(&out, getTpp())
+	if !topic.Equal(parsed) {
+		t.Errorf("The written topic is not parsed back identically.")
+	}
+}
+
+// TestWriteTopicMultiAnswer checks that the answers of a question are
+// written as distinct fields.
+func TestWriteTopicMultiAnswer(t *testing.T) {
+	tpp := getTpp()
+	tpp.MultiAnswer = true
+	topic := ParseTopic(strings.NewReader("### Lesson 1\nmanger;to eat;to dine\n"), tpp)
+
+	var out bytes.Buffer
+	if err := WriteTopic(&out, topic, tpp); err != nil {
+		t.Fatalf("Writing the topic failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "manger;to eat;to dine\n") {
+		t.Errorf("The answers should be written as distinct fields. Output was:\n%s\n", out.String())
+	}
+}
+
+// TestWriteTopicMultiLineAnswer checks that an answer written on several
+// lines is parsed back identically.
+func TestWriteTopicMultiLineAnswer(t *testing.T) {
+	topic := ParseTopic(strings.NewReader("### Lesson 1\nboire;to drink\n  the water\n"), getTpp())
+
+	var out bytes.Buffer
+	if err := WriteTopic(&out, topic, getTpp()); err != nil {
+		t.Fatalf("Writing the topic failed: %v", err)
+	}
+	if parsed := ParseTopic(&out, getTpp()); !topic.Equal(parsed) {
+		t.Errorf("The answer on several lines is not parsed back identically: %+v\n", parsed)
+	}
+
+	// The answers ending with a \, or whose lines could not go on, are
+	// written so that they read the same.
+	deck := "### Lesson 1\nchemin;\"C:\\\\\"\nboire;\"to drink\nthe water;the wine\"\ndire;\"to say\\\nmore\"\nlire;\"to read\n\nthe book\"\n"
+	topic = ParseTopic(strings.NewReader(deck), getTpp())
+	if answer := topic.BuildQuestionsSet().answers[0]; answer != `C:\` {
+		t.Fatalf("The escaped \\ should be read once but we got %q\n", answer)
+	}
+	out.Reset()
+	if err := WriteTopic(&out, topic, getTpp()); err != nil {
+		t.Fatalf("Writing the topic failed: %v", err)
+	}
+	written := out.String()
+	if parsed := ParseTopic(&out, getTpp()); !topic.Equal(parsed) {
+		t.Errorf("The answers are not parsed back identically from:\n%s\n", written)
+	}
+}
+
+// TestSaveWrongAnswers checks that the questions wrongly answered during a
+// graded session give a deck made of exactly those questions.
+func TestSaveWrongAnswers(t *testing.T) {
+	qa := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp()).BuildQuestionsSet()
+
+	userIn, userOut := io.Pipe()
+	ip := getGenericInteractiveInterrogationParameters()
+	ip.in = userIn
+	ip.limit = 2
+	ip.graded = true
+
+	// Questions 1 and 4 are missed on the first loop, question 4 on the
+	// second one too.
+	go func() {
+		for loop := 0; loop < ip.limit; loop++ {
+			for i := range qa.answers {
+				answer := qa.answers[i]
+				if i == 4 || (i == 1 && loop == 0) {
+					answer = "no idea"
+				}
+				fmt.Fprintln(userOut, answer)
+			}
+		}
+	}()
+
+	var result SessionResult
+	pr, pw := io.Pipe()
+	ip.out = pw
+	go func() {
+		defer pw.Close()
+		result = AskQuestions(qa, ip)
+	}()
+	io.Copy(ioutil.Discard, pr)
+
+	var out bytes.Buffer
+	if err := WriteTopic(&out, WrongAnswersTopic(qa, result), getTpp()); err != nil {
+		t.Fatalf("Writing the wrong answers failed: %v", err)
+	}
+	saved := ParseTopic(&out, getTpp())
+
+	expected := NewTopic()
+	for _, i := range []int{1, 4} {
+		wrong := expected.GetSubsection(qa.origin[i])
+		wrong.AddEntry(qa.questions[i], qa.answers[i])
+		expected.SetSubsection(qa.origin[i], wrong)
+	}
+	if !saved.Equal(expected) {
+		t.Errorf("The saved deck should contain exactly the questions 1 and 4 but contains %v\n", saved.BuildQuestionsSet().questions)
+	}
+}
+
+// TestWrongAnswersTopicOrigin checks that the questions wrongly answered
+// stay in the subsections they come from.
+func TestWrongAnswersTopicOrigin(t *testing.T) {
+	topic := ParseTopic(strings.NewReader("### Lesson 1\nmanger;to eat\nboire;to drink\n### Lesson 2\nlire;to read\n"), getTpp())
+	qa := topic.BuildQuestionsSet()
+	var out bytes.Buffer
+	if err := WriteTopic(&out, WrongAnswersTopic(qa, SessionResult{Wrong: []int{2, 0}}), getTpp()); err != nil {
+		t.Fatalf("Writing the wrong answers failed: %v", err)
+	}
+	if expected := "### Lesson 2\nlire;to read\n\n### Lesson 1\nmanger;to eat\n"; out.String() != expected {
+		t.Errorf("The wrong answers should be written as:\n%s\nbut we got:\n%s\n", expected, out.String())
+	}
+}
+
+// TestWriteTopicCanonical checks that every field read by the parameters
+// is written so that the deck is parsed back identically, the fields
+// holding the separator being quoted.
+func TestWriteTopicCanonical(t *testing.T) {
+	deck := "---\ntitle: French verbs\nseparator: \";\"\ndirection: reversed\n---\n" +
+		"avant;before\n\n" +
+		"### Lesson 1\n" +
+		"manger;\"to eat; to dine #verb @requires:basics\";starts with t;irregular;manger.mp3;4\n" +
+		"boire;to drink|to sip;;;;2\n" +
+		"le \"chat\";the cat\n" +
+		"\"\"\"noir\"\" ou blanc\";black or white\n" +
+		"{{Paris}} is the capital of {{France}} #geography\n"
+	tpp := getTpp()
+	tpp.TagPrefix = "#"
+	tpp.Prerequisites = true
+	tpp.HintNoteFields = true
+	tpp.MediaField = true
+	tpp.DifficultyField = true
+	tpp.DefaultDifficulty = defaultDifficulty
+	tpp.AlternativeSep = "|"
+	topic := ParseTopic(strings.NewReader(deck), tpp)
+
+	var out bytes.Buffer
+	if err := WriteTopic(&out, topic, tpp); err != nil {
+		t.Fatalf("Writing the topic failed: %v", err)
+	}
+	expected := strings.Replace(deck, "boire;to drink|to sip;;;;2", "boire;to drink|to sip;2", 1)
+	if out.String() != expected {
+		t.Errorf("The deck should be written as:\n%s\nbut we got:\n%s\n", expected, out.String())
+	}
+	parsed := ParseTopic(strings.NewReader(out.String()), tpp)
+	if !topic.Equal(parsed) || parsed.Metadata() != topic.Metadata() {
+		t.Errorf("The written topic is not parsed back identically: %+v\n", parsed)
+	}
+	qa := parsed.GetSubsection("1")
+	if qa.GetHint(0) != "starts with t" || qa.GetMedia(0) != "manger.mp3" || qa.GetDifficulty(0) != 4 || qa.GetDifficulty(1) != 2 {
+		t.Errorf("The optional fields are not parsed back: %q, %q and %v\n", qa.hints, qa.media, qa.difficulty)
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlEntry is a question and its answer in a YAML deck.
+type yamlEntry struct {
+	Q    string `yaml:"q"`
+	A    string `yaml:"a"`
+	Hint string `yaml:"hint,omitempty"`
+	Note string `yaml:"note,omitempty"`
+}
+
+// ParseTopicYAML reads a deck written in YAML. The document is a mapping of
+// the subsections names to the list of their questions and answers:
+//
+//	Lesson 1:
+//	  - q: manger
+//	    a: to eat
+//	    hint: starts with t
+//
+// The hint and the note of a question are optional. The subsections keep the order of the document.
+func ParseTopicYAML(r io.Reader) (Topic, error) {
+	topic := NewTopic()
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		if err == io.EOF {
+			return topic, nil
+		}
+		return topic, fmt.Errorf("The YAML deck is malformed: %v", err)
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return topic, &ParseError{Line: root.Line, msg: fmt.Sprintf("The YAML deck must be a mapping of the topics to their questions (line %d).", root.Line)}
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		id := root.Content[i].Value
+		var entries []yamlEntry
+		if err := root.Content[i+1].Decode(&entries); err != nil {
+			return topic, &ParseError{Line: root.Content[i+1].Line, msg: fmt.Sprintf("The topic %s of the YAML deck is malformed (line %d): %v", id, root.Content[i+1].Line, err)}
+		}
+		qa := topic.GetSubsection(id)
+		for _, card := range entries {
+			if len(card.Q) == 0 {
+				return topic, &ParseError{Line: root.Content[i+1].Line, msg: fmt.Sprintf("The topic %s of the YAML deck has an entry without question (line %d).", id, root.Content[i+1].Line)}
+			}
+			qa.addEntry(entry{question: card.Q, answer: card.A, hint: card.Hint, note: card.Note})
+		}
+		topic.SetSubsection(id, qa)
+	}
+	return topic, nil
+}
+
+// WriteTopicYAML writes the topic in YAML so that ParseTopicYAML reads it
+// again. The subsections keep their order. The questions found before any
+// subsection are written under an empty name.
+func WriteTopicYAML(w io.Writer, topic Topic) error {
+	root := yaml.Node{Kind: yaml.MappingNode}
+	for _, id := range topic.GetSubsectionsName() {
+		qa := topic.list[id]
+		entries := make([]yamlEntry, qa.GetCount())
+		for i := range entries {
+			entries[i] = yamlEntry{Q: qa.questions[i], A: qa.answers[i], Hint: qa.hints[i], Note: qa.notes[i]}
+		}
+		var value yaml.Node
+		if err := value.Encode(entries); err != nil {
+			return err
+		}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: id}, &value)
+	}
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// isYAMLDeck tells from its path if a deck is written in YAML.
+func isYAMLDeck(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// ParseDeck reads a deck choosing the format from the extension of its
+// path: YAML for .yaml and .yml files, Markdown for .md and .markdown files,
+// csv otherwise.
+func ParseDeck(path string, r io.Reader, p TopicParsingParameters) (Topic, error) {
+	if isYAMLDeck(path) {
+		return ParseTopicYAML(r)
+	}
+	if isMarkdownDeck(path) {
+		return ParseTopicMarkdown(r, markdownParameters(p))
+	}
+	topic, _, err := parseTopic(r, p)
+	return topic, err
+}
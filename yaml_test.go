@@ -0,0 +1,101 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseTopicYAML checks that a YAML deck gives the same topic as the
+// equivalent csv deck.
+func TestParseTopicYAML(t *testing.T) {
+	topic, err := ParseTopicYAML(strings.NewReader(`
+"1":
+  - q: 1_Question 1
+    a: 1_Answer 1
+"2":
+  - q: 2_Question 1
+    a: 2_Answer 1
+  - q: 2_Question 2
+    a: 2_Answer 2
+"3":
+  - {q: "3_Question 1", a: "3_Answer 1"}
+  - {q: "3_Question 2", a: "3_Answer 2"}
+  - {q: "3_Question 3", a: "3_Answer 3"}
+`))
+	if err != nil {
+		t.Fatalf("A valid YAML deck should not trigger an error: %v", err)
+	}
+	expected := ParseTopic(strings.NewReader(getSampleCsvAsStream()), getTpp())
+	if !topic.Equal(expected) {
+		t.Errorf("The YAML deck does not give the same topic as the csv deck.")
+	}
+}
+
+// TestParseTopicYAMLKeepsOrder checks that the subsections keep the order of
+// the document.
+func TestParseTopicYAMLKeepsOrder(t *testing.T) {
+	topic, err := ParseTopicYAML(strings.NewReader(`
+Verbs:
+  - {q: manger, a: to eat}
+Animals:
+  - {q: chat, a: cat}
+Colors:
+  - {q: rouge, a: red}
+`))
+	if err != nil {
+		t.Fatalf("A valid YAML deck should not trigger an error: %v", err)
+	}
+	expected := []string{"Verbs", "Animals", "Colors"}
+	if !reflect.DeepEqual(topic.GetSubsectionsName(), expected) {
+		t.Errorf("The subsections should be %v but we got %v\n", expected, topic.GetSubsectionsName())
+	}
+}
+
+// TestParseTopicYAMLMalformed checks that malformed documents are reported.
+func TestParseTopicYAMLMalformed(t *testing.T) {
+	documents := []string{
+		"Verbs: [q: manger",
+		"- q: manger\n  a: to eat\n",
+		"Verbs:\n  q: manger\n",
+		"Verbs:\n  - a: to eat\n",
+	}
+	for _, doc := range documents {
+		if _, err := ParseTopicYAML(strings.NewReader(doc)); err == nil {
+			t.Errorf("The malformed document '%s' is not reported.", doc)
+		}
+	}
+}
+
+// TestIsYAMLDeck checks the detection of the YAML decks from their path.
+func TestIsYAMLDeck(t *testing.T) {
+	for path, expected := range map[string]bool{
+		"deck.yaml": true,
+		"deck.YML":  true,
+		"deck.csv":  false,
+		"yaml":      false,
+	} {
+		if isYAMLDeck(path) != expected {
+			t.Errorf("The detection of %s as a YAML deck should be %v", path, expected)
+		}
+	}
+}
+
+// TestParseTopicYAMLHintNote checks that the hint and the note of a
+// question are read and written back.
+func TestParseTopicYAMLHintNote(t *testing.T) {
+	deck := "Lesson 1:\n  - q: manger\n    a: to eat\n    hint: starts with t\n    note: irregular\n  - q: boire\n    a: to drink\n"
+	topic, err := ParseTopicYAML(strings.NewReader(deck))
+	if err != nil {
+		t.Fatalf("The deck should be read but we got %v\n", err)
+	}
+	qa := topic.GetSubsection("Lesson 1")
+	if qa.GetHint(0) != "starts with t" || qa.GetNote(0) != "irregular" || qa.GetHint(1) != "" {
+		t.Errorf("The hint and the note are not the ones of the deck: %q and %q\n", qa.hints, qa.notes)
+	}
+
+	var out strings.Builder
+	if err := WriteTopicYAML(&out, topic); err != nil || out.String() != deck {
+		t.Errorf("The deck should be written back identically but we got %v and:\n%s\n", err, out.String())
+	}
+}